@@ -0,0 +1,359 @@
+package opencode
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMaxAttachmentSize is the largest input the attachment constructors
+// accept unless WithAttachmentMaxSize says otherwise. Attachments are sent
+// inline as base64, which grows them by a third.
+const DefaultMaxAttachmentSize = 10 << 20
+
+var (
+	// ErrAttachmentTooLarge matches *AttachmentTooLargeError.
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+	// ErrUnsupportedAttachment matches *UnsupportedAttachmentError.
+	ErrUnsupportedAttachment = errors.New("attachment not supported by model")
+)
+
+// AttachmentTooLargeError is returned when an attachment's input exceeds the
+// size limit.
+type AttachmentTooLargeError struct {
+	Filename string
+	// Size is the input's size, or limit+1 when it was read from a stream
+	// and reading stopped at the limit.
+	Size  int64
+	Limit int64
+}
+
+func (e *AttachmentTooLargeError) Error() string {
+	return fmt.Sprintf("attachment %s is %d bytes, over the %d byte limit", e.Filename, e.Size, e.Limit)
+}
+
+func (e *AttachmentTooLargeError) Is(target error) bool {
+	return target == ErrAttachmentTooLarge
+}
+
+// UnsupportedAttachmentError is returned when a prompt attaches a file the
+// target model cannot accept.
+type UnsupportedAttachmentError struct {
+	ProviderID string
+	ModelID    string
+	Filename   string
+	Mime       string
+	// Modality is the input modality the file needs, or empty for binary
+	// files that match none.
+	Modality ModelModalityInput
+}
+
+func (e *UnsupportedAttachmentError) Error() string {
+	if e.Modality == "" {
+		return fmt.Sprintf("model %s/%s does not accept file attachments (%s, %s)", e.ProviderID, e.ModelID, e.Filename, e.Mime)
+	}
+	return fmt.Sprintf("model %s/%s does not accept %s input (%s, %s)", e.ProviderID, e.ModelID, e.Modality, e.Filename, e.Mime)
+}
+
+func (e *UnsupportedAttachmentError) Is(target error) bool {
+	return target == ErrUnsupportedAttachment
+}
+
+// AttachmentOption configures the attachment constructors.
+type AttachmentOption func(*attachmentConfig) error
+
+type attachmentConfig struct {
+	maxSize  int64
+	filename string
+	mime     string
+}
+
+// WithAttachmentMaxSize sets the largest accepted input in bytes.
+func WithAttachmentMaxSize(n int64) AttachmentOption {
+	return func(c *attachmentConfig) error {
+		if n <= 0 {
+			return errors.New("attachment max size must be positive")
+		}
+		c.maxSize = n
+		return nil
+	}
+}
+
+// WithAttachmentFilename overrides the filename sent with the attachment.
+func WithAttachmentFilename(name string) AttachmentOption {
+	return func(c *attachmentConfig) error {
+		c.filename = name
+		return nil
+	}
+}
+
+// WithAttachmentMIME sets the MIME type instead of detecting it.
+func WithAttachmentMIME(mediaType string) AttachmentOption {
+	return func(c *attachmentConfig) error {
+		parsed, _, err := mime.ParseMediaType(mediaType)
+		if err != nil {
+			return fmt.Errorf("invalid attachment MIME type %q: %w", mediaType, err)
+		}
+		c.mime = parsed
+		return nil
+	}
+}
+
+// NewFileAttachment reads a local file into a file part carried inline as
+// a data: URL. Unlike PromptBuilder.File, which references a file the server
+// reads itself, the content travels with the request, so this works for
+// files outside the server's workspace.
+func NewFileAttachment(filePath string, opts ...AttachmentOption) (FilePartInputParam, error) {
+	cfg, err := newAttachmentConfig(filepath.Base(filePath), opts)
+	if err != nil {
+		return FilePartInputParam{}, err
+	}
+	f, err := os.Open(filePath) //nolint:gosec // reading the caller-selected file is the point of this helper
+	if err != nil {
+		return FilePartInputParam{}, fmt.Errorf("attachment: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if info, err := f.Stat(); err == nil && info.Size() > cfg.maxSize {
+		return FilePartInputParam{}, &AttachmentTooLargeError{Filename: cfg.filename, Size: info.Size(), Limit: cfg.maxSize}
+	}
+	return newAttachment(f, cfg)
+}
+
+// NewReaderAttachment reads r to EOF into a file part carried inline as a
+// data: URL. filename is sent to the server and used as the MIME fallback
+// when the content is not recognized.
+func NewReaderAttachment(r io.Reader, filename string, opts ...AttachmentOption) (FilePartInputParam, error) {
+	if r == nil {
+		return FilePartInputParam{}, missingRequiredParameterError("reader")
+	}
+	cfg, err := newAttachmentConfig(filename, opts)
+	if err != nil {
+		return FilePartInputParam{}, err
+	}
+	return newAttachment(r, cfg)
+}
+
+// NewFSAttachment reads name from fsys, such as an embed.FS, into a file
+// part carried inline as a data: URL.
+func NewFSAttachment(fsys fs.FS, name string, opts ...AttachmentOption) (FilePartInputParam, error) {
+	if fsys == nil {
+		return FilePartInputParam{}, missingRequiredParameterError("fsys")
+	}
+	cfg, err := newAttachmentConfig(path.Base(name), opts)
+	if err != nil {
+		return FilePartInputParam{}, err
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return FilePartInputParam{}, fmt.Errorf("attachment: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if info, err := f.Stat(); err == nil && info.Size() > cfg.maxSize {
+		return FilePartInputParam{}, &AttachmentTooLargeError{Filename: cfg.filename, Size: info.Size(), Limit: cfg.maxSize}
+	}
+	return newAttachment(f, cfg)
+}
+
+func newAttachmentConfig(filename string, opts []AttachmentOption) (*attachmentConfig, error) {
+	cfg := &attachmentConfig{maxSize: DefaultMaxAttachmentSize, filename: filename}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	if strings.TrimSpace(cfg.filename) == "" {
+		return nil, missingRequiredParameterError("filename")
+	}
+	return cfg, nil
+}
+
+func newAttachment(r io.Reader, cfg *attachmentConfig) (FilePartInputParam, error) {
+	// Read one byte past the limit to tell "exactly at" from "over".
+	data, err := io.ReadAll(io.LimitReader(r, cfg.maxSize+1))
+	if err != nil {
+		return FilePartInputParam{}, fmt.Errorf("attachment %s: %w", cfg.filename, err)
+	}
+	if int64(len(data)) > cfg.maxSize {
+		return FilePartInputParam{}, &AttachmentTooLargeError{Filename: cfg.filename, Size: int64(len(data)), Limit: cfg.maxSize}
+	}
+
+	mediaType := cfg.mime
+	if mediaType == "" {
+		mediaType = sniffMIMEType(data, cfg.filename)
+	}
+	return FilePartInputParam{
+		Mime:     mediaType,
+		Type:     FilePartInputTypeFile,
+		URL:      dataURL(mediaType, data),
+		Filename: Ptr(cfg.filename),
+	}, nil
+}
+
+// sniffMIMEType detects a MIME type from content, falling back to the
+// filename's extension when the content only matches a generic type.
+func sniffMIMEType(data []byte, filename string) string {
+	sniffed, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil {
+		sniffed = "application/octet-stream"
+	}
+	if sniffed == "application/octet-stream" || sniffed == "text/plain" {
+		if byExt := mimeTypeByExtension(filename); byExt != "" {
+			return byExt
+		}
+	}
+	return sniffed
+}
+
+func dataURL(mediaType string, data []byte) string {
+	var b bytes.Buffer
+	b.Grow(len("data:;base64,") + len(mediaType) + base64.StdEncoding.EncodedLen(len(data)))
+	b.WriteString("data:")
+	b.WriteString(mediaType)
+	b.WriteString(";base64,")
+	b.WriteString(base64.StdEncoding.EncodeToString(data))
+	return b.String()
+}
+
+// attachmentModality maps a MIME type to the model input modality needed to
+// read it. Binary types with no matching modality, such as
+// application/octet-stream, map to the empty modality.
+func attachmentModality(mimeType string) ModelModalityInput {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "image/"):
+		return ModelModalityInputImage
+	case strings.HasPrefix(mediaType, "audio/"):
+		return ModelModalityInputAudio
+	case strings.HasPrefix(mediaType, "video/"):
+		return ModelModalityInputVideo
+	case mediaType == "application/pdf":
+		return ModelModalityInputPdf
+	case isTextMediaType(mediaType):
+		return ModelModalityInputText
+	}
+	return ""
+}
+
+// isTextMediaType reports whether the server can inline files of mediaType
+// as text.
+func isTextMediaType(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/yaml", "application/x-yaml":
+		return true
+	}
+	return false
+}
+
+// ValidateAttachments reports whether the model can accept every file part
+// in parts, using the provider catalog from ConfigService.Providers. Text
+// files, meaning text/* and JSON, XML and YAML, are always accepted because
+// the server inlines them; other media require the model to support
+// attachments and list the matching input modality. Binary files that match
+// no modality need only attachment support. The first unsupported part is
+// returned as an *UnsupportedAttachmentError.
+func ValidateAttachments(providers *ConfigProviderListResponse, model SessionPromptParamsModel, parts []SessionPromptParamsPartUnion) error {
+	if providers == nil {
+		return missingRequiredParameterError("providers")
+	}
+	info, ok := findProviderModel(providers, model.ProviderID, model.ModelID)
+	if !ok {
+		return fmt.Errorf("model %s/%s not found in provider catalog: %w", model.ProviderID, model.ModelID, ErrNotFound)
+	}
+
+	for _, part := range parts {
+		var file FilePartInputParam
+		switch p := part.(type) {
+		case FilePartInputParam:
+			file = p
+		case *FilePartInputParam:
+			if p == nil {
+				continue
+			}
+			file = *p
+		default:
+			continue
+		}
+
+		modality := attachmentModality(file.Mime)
+		if modality == ModelModalityInputText {
+			continue
+		}
+		if info.Attachment && (modality == "" || modelAcceptsInput(info, modality)) {
+			continue
+		}
+		filename := ""
+		if file.Filename != nil {
+			filename = *file.Filename
+		}
+		return &UnsupportedAttachmentError{
+			ProviderID: model.ProviderID,
+			ModelID:    model.ModelID,
+			Filename:   filename,
+			Mime:       file.Mime,
+			Modality:   modality,
+		}
+	}
+	return nil
+}
+
+// CheckAttachments fetches the provider catalog and runs ValidateAttachments
+// for params.Model, so unsupported files are caught before the prompt is
+// sent. params.Model must be set.
+func (s *ConfigService) CheckAttachments(ctx context.Context, params *SessionPromptParams) error {
+	if params == nil {
+		return ErrParamsRequired
+	}
+	if params.Model == nil {
+		return requiredFieldError("Model")
+	}
+	providers, err := s.Providers(ctx, &ConfigProviderListParams{Directory: params.Directory})
+	if err != nil {
+		return err
+	}
+	return ValidateAttachments(providers, *params.Model, params.Parts)
+}
+
+func findProviderModel(providers *ConfigProviderListResponse, providerID, modelID string) (ConfigProviderModel, bool) {
+	for _, provider := range providers.Providers {
+		if provider.ID != providerID {
+			continue
+		}
+		model, ok := provider.Models[modelID]
+		return model, ok
+	}
+	return ConfigProviderModel{}, false
+}
+
+// modelAcceptsInput reports whether model lists modality as an input. Models
+// whose catalog entry has no modalities are trusted on their attachment flag
+// alone.
+func modelAcceptsInput(model ConfigProviderModel, modality ModelModalityInput) bool {
+	if len(model.Modalities.Input) == 0 {
+		return true
+	}
+	for _, input := range model.Modalities.Input {
+		if string(input) == string(modality) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package opencode
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestNewReaderAttachment_SniffsContent(t *testing.T) {
+	part, err := NewReaderAttachment(bytes.NewReader(pngHeader), "shot.bin")
+	if err != nil {
+		t.Fatalf("NewReaderAttachment failed: %v", err)
+	}
+	if part.Mime != "image/png" || part.Type != FilePartInputTypeFile || *part.Filename != "shot.bin" {
+		t.Fatalf("unexpected part %+v", part)
+	}
+	want := "data:image/png;base64," + base64.StdEncoding.EncodeToString(pngHeader)
+	if part.URL != want {
+		t.Fatalf("expected URL %q, got %q", want, part.URL)
+	}
+}
+
+func TestNewReaderAttachment_ExtensionFallback(t *testing.T) {
+	part, err := NewReaderAttachment(strings.NewReader(`{"a":1}`), "data.json")
+	if err != nil {
+		t.Fatalf("NewReaderAttachment failed: %v", err)
+	}
+	if part.Mime != "application/json" {
+		t.Fatalf("expected extension fallback to application/json, got %s", part.Mime)
+	}
+
+	part, err = NewReaderAttachment(strings.NewReader("plain"), "notes", WithAttachmentMIME("text/markdown; charset=utf-8"))
+	if err != nil {
+		t.Fatalf("NewReaderAttachment failed: %v", err)
+	}
+	if part.Mime != "text/markdown" {
+		t.Fatalf("expected MIME override, got %s", part.Mime)
+	}
+}
+
+func TestNewReaderAttachment_TooLarge(t *testing.T) {
+	_, err := NewReaderAttachment(strings.NewReader("0123456789"), "big.txt", WithAttachmentMaxSize(4))
+	var tooLarge *AttachmentTooLargeError
+	if !errors.As(err, &tooLarge) || !errors.Is(err, ErrAttachmentTooLarge) {
+		t.Fatalf("expected AttachmentTooLargeError, got %v", err)
+	}
+	if tooLarge.Limit != 4 || tooLarge.Size != 5 || tooLarge.Filename != "big.txt" {
+		t.Fatalf("unexpected error fields %+v", tooLarge)
+	}
+
+	if _, err := NewReaderAttachment(strings.NewReader("0123"), "ok.txt", WithAttachmentMaxSize(4)); err != nil {
+		t.Fatalf("expected input at the limit to be accepted, got %v", err)
+	}
+}
+
+func TestNewFSAttachment(t *testing.T) {
+	fsys := fstest.MapFS{
+		"img/logo.png": {Data: pngHeader},
+		"big.txt":      {Data: bytes.Repeat([]byte("x"), 100)},
+	}
+	part, err := NewFSAttachment(fsys, "img/logo.png")
+	if err != nil {
+		t.Fatalf("NewFSAttachment failed: %v", err)
+	}
+	if part.Mime != "image/png" || *part.Filename != "logo.png" {
+		t.Fatalf("unexpected part %+v", part)
+	}
+
+	_, err = NewFSAttachment(fsys, "big.txt", WithAttachmentMaxSize(10))
+	var tooLarge *AttachmentTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Size != 100 {
+		t.Fatalf("expected size from stat in error, got %v", err)
+	}
+}
+
+func TestNewFileAttachment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	if err := os.WriteFile(path, []byte("%PDF-1.7\n"), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	part, err := NewFileAttachment(path)
+	if err != nil {
+		t.Fatalf("NewFileAttachment failed: %v", err)
+	}
+	if part.Mime != "application/pdf" || *part.Filename != "report.pdf" || !strings.HasPrefix(part.URL, "data:application/pdf;base64,") {
+		t.Fatalf("unexpected part %+v", part)
+	}
+
+	if _, err := NewFileAttachment(filepath.Join(t.TempDir(), "missing.png")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected not exist error, got %v", err)
+	}
+}
+
+func attachmentCatalog() *ConfigProviderListResponse {
+	return &ConfigProviderListResponse{Providers: []ConfigProvider{{
+		ID: "acme",
+		Models: map[string]ConfigProviderModel{
+			"vision": {ID: "vision", Attachment: true, Modalities: ConfigProviderModelsModalities{
+				Input: []ConfigProviderModelsModalitiesInput{ConfigProviderModelsModalitiesInputText, ConfigProviderModelsModalitiesInputImage},
+			}},
+			"text-only": {ID: "text-only"},
+		},
+	}}}
+}
+
+func TestValidateAttachments(t *testing.T) {
+	png := FilePartInputParam{Mime: "image/png", Type: FilePartInputTypeFile, Filename: Ptr("a.png")}
+	pdf := FilePartInputParam{Mime: "application/pdf", Type: FilePartInputTypeFile, Filename: Ptr("a.pdf")}
+	txt := FilePartInputParam{Mime: "text/plain", Type: FilePartInputTypeFile}
+	yaml := FilePartInputParam{Mime: "application/yaml; charset=utf-8", Type: FilePartInputTypeFile}
+	bin := FilePartInputParam{Mime: "application/octet-stream", Type: FilePartInputTypeFile, Filename: Ptr("a.bin")}
+	text := TextPartInputParam{Text: "hi", Type: TextPartInputTypeText}
+
+	vision := SessionPromptParamsModel{ProviderID: "acme", ModelID: "vision"}
+	if err := ValidateAttachments(attachmentCatalog(), vision, []SessionPromptParamsPartUnion{text, png, &txt}); err != nil {
+		t.Fatalf("expected image to be accepted, got %v", err)
+	}
+
+	err := ValidateAttachments(attachmentCatalog(), vision, []SessionPromptParamsPartUnion{pdf})
+	var unsupported *UnsupportedAttachmentError
+	if !errors.As(err, &unsupported) || unsupported.Modality != ModelModalityInputPdf || unsupported.Filename != "a.pdf" {
+		t.Fatalf("expected unsupported pdf, got %v", err)
+	}
+
+	textOnly := SessionPromptParamsModel{ProviderID: "acme", ModelID: "text-only"}
+	if err := ValidateAttachments(attachmentCatalog(), textOnly, []SessionPromptParamsPartUnion{png}); !errors.Is(err, ErrUnsupportedAttachment) {
+		t.Fatalf("expected model without attachment support to reject image, got %v", err)
+	}
+	if err := ValidateAttachments(attachmentCatalog(), textOnly, []SessionPromptParamsPartUnion{txt, yaml}); err != nil {
+		t.Fatalf("expected text files to be accepted, got %v", err)
+	}
+
+	// Binary files with no matching modality need attachment support.
+	err = ValidateAttachments(attachmentCatalog(), textOnly, []SessionPromptParamsPartUnion{bin})
+	if !errors.As(err, &unsupported) || unsupported.Modality != "" || unsupported.Filename != "a.bin" {
+		t.Fatalf("expected model without attachment support to reject binary file, got %v", err)
+	}
+	if err := ValidateAttachments(attachmentCatalog(), vision, []SessionPromptParamsPartUnion{bin}); err != nil {
+		t.Fatalf("expected binary file to be accepted with attachment support, got %v", err)
+	}
+
+	missing := SessionPromptParamsModel{ProviderID: "acme", ModelID: "nope"}
+	if err := ValidateAttachments(attachmentCatalog(), missing, nil); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for unknown model, got %v", err)
+	}
+}
+
+func TestConfigService_CheckAttachments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/config/providers" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"default":{},"providers":[{"id":"acme","models":{"text-only":{"id":"text-only","attachment":false}}}]}`))
+	}))
+	defer server.Close()
+	client, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	png, err := NewReaderAttachment(bytes.NewReader(pngHeader), "a.png")
+	if err != nil {
+		t.Fatalf("NewReaderAttachment failed: %v", err)
+	}
+	params := &SessionPromptParams{
+		Parts: []SessionPromptParamsPartUnion{png},
+		Model: &SessionPromptParamsModel{ProviderID: "acme", ModelID: "text-only"},
+	}
+	if err := client.Config.CheckAttachments(context.Background(), params); !errors.Is(err, ErrUnsupportedAttachment) {
+		t.Fatalf("expected unsupported attachment, got %v", err)
+	}
+
+	params.Model = nil
+	if err := client.Config.CheckAttachments(context.Background(), params); !errors.Is(err, ErrRequiredField) {
+		t.Fatalf("expected required Model error, got %v", err)
+	}
+}
+
+func TestPromptBuilder_Attach(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big.png")
+	if err := os.WriteFile(path, pngHeader, 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	_, err := NewPromptBuilder().Text("look").Attach(path, WithAttachmentMaxSize(4)).Build()
+	if !errors.Is(err, ErrAttachmentTooLarge) {
+		t.Fatalf("expected builder to surface the size error, got %v", err)
+	}
+
+	params, err := NewPromptBuilder().Text("look").Attach(path).Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if file, ok := params.Parts[1].(FilePartInputParam); !ok || file.Mime != "image/png" {
+		t.Fatalf("expected attached image part, got %#v", params.Parts[1])
+	}
+}
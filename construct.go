@@ -0,0 +1,327 @@
+package opencode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dominicnunez/opencode-sdk-go/shared"
+)
+
+// The constructors in this file build union values from their variants, for
+// test fixtures and for services that forward or synthesize server data. Each
+// sets the variant's discriminator, so it need not be filled in, and encodes
+// the variant the way the server would. The result behaves exactly like a
+// decoded value: its As* accessors return the variant and MarshalJSON returns
+// its JSON.
+//
+// Part constructors are named after the variant type, as in
+// NewTextPartValue; the others after the union and the As* accessor, as in
+// NewToolPartStateCompleted. NewEvent accepts any Event* variant type.
+//
+// A constructor panics if the variant cannot be encoded, which only happens
+// when an interface-typed field such as ToolStateRunning.Input holds a value
+// encoding/json does not support.
+
+// newUnion encodes variant and decodes it into a union value.
+func newUnion[U any, P interface {
+	*U
+	json.Unmarshaler
+}](variant any) U {
+	data, err := json.Marshal(variant)
+	if err != nil {
+		panic(fmt.Sprintf("opencode: encode %T: %v", variant, err))
+	}
+	var u U
+	if err := P(&u).UnmarshalJSON(data); err != nil {
+		panic(fmt.Sprintf("opencode: decode %T: %v", u, err))
+	}
+	return u
+}
+
+// NewTextPartValue returns a Part holding p.
+func NewTextPartValue(p TextPart) Part {
+	p.Type = TextPartTypeText
+	return newUnion[Part](p)
+}
+
+// NewReasoningPartValue returns a Part holding p.
+func NewReasoningPartValue(p ReasoningPart) Part {
+	p.Type = ReasoningPartTypeReasoning
+	return newUnion[Part](p)
+}
+
+// NewFilePartValue returns a Part holding p.
+func NewFilePartValue(p FilePart) Part {
+	p.Type = FilePartTypeFile
+	return newUnion[Part](p)
+}
+
+// NewToolPartValue returns a Part holding p.
+func NewToolPartValue(p ToolPart) Part {
+	p.Type = ToolPartTypeTool
+	return newUnion[Part](p)
+}
+
+// NewStepStartPartValue returns a Part holding p.
+func NewStepStartPartValue(p StepStartPart) Part {
+	p.Type = StepStartPartTypeStepStart
+	return newUnion[Part](p)
+}
+
+// NewStepFinishPartValue returns a Part holding p.
+func NewStepFinishPartValue(p StepFinishPart) Part {
+	p.Type = StepFinishPartTypeStepFinish
+	return newUnion[Part](p)
+}
+
+// NewSnapshotPartValue returns a Part holding p.
+func NewSnapshotPartValue(p SnapshotPart) Part {
+	p.Type = SnapshotPartTypeSnapshot
+	return newUnion[Part](p)
+}
+
+// NewPartPatchPartValue returns a Part holding p.
+func NewPartPatchPartValue(p PartPatchPart) Part {
+	p.Type = PartPatchPartTypePatch
+	return newUnion[Part](p)
+}
+
+// NewAgentPartValue returns a Part holding p.
+func NewAgentPartValue(p AgentPart) Part {
+	p.Type = AgentPartTypeAgent
+	return newUnion[Part](p)
+}
+
+// NewPartRetryPartValue returns a Part holding p.
+func NewPartRetryPartValue(p PartRetryPart) Part {
+	p.Type = PartRetryPartTypeRetry
+	return newUnion[Part](p)
+}
+
+// NewMessageUser returns a Message holding v.
+func NewMessageUser(v UserMessage) Message {
+	v.Role = UserMessageRoleUser
+	return newUnion[Message](v)
+}
+
+// NewMessageAssistant returns a Message holding v.
+func NewMessageAssistant(v AssistantMessage) Message {
+	v.Role = AssistantMessageRoleAssistant
+	return newUnion[Message](v)
+}
+
+// NewToolPartStatePending returns a ToolPartState holding v.
+func NewToolPartStatePending(v ToolStatePending) ToolPartState {
+	v.Status = ToolStatePendingStatusPending
+	return newUnion[ToolPartState](v)
+}
+
+// NewToolPartStateRunning returns a ToolPartState holding v.
+func NewToolPartStateRunning(v ToolStateRunning) ToolPartState {
+	v.Status = ToolStateRunningStatusRunning
+	return newUnion[ToolPartState](v)
+}
+
+// NewToolPartStateCompleted returns a ToolPartState holding v.
+func NewToolPartStateCompleted(v ToolStateCompleted) ToolPartState {
+	v.Status = ToolStateCompletedStatusCompleted
+	return newUnion[ToolPartState](v)
+}
+
+// NewToolPartStateError returns a ToolPartState holding v.
+func NewToolPartStateError(v ToolStateError) ToolPartState {
+	v.Status = ToolStateErrorStatusError
+	return newUnion[ToolPartState](v)
+}
+
+// NewFilePartSourceFile returns a FilePartSource holding v.
+func NewFilePartSourceFile(v FileSource) FilePartSource {
+	v.Type = FileSourceTypeFile
+	return newUnion[FilePartSource](v)
+}
+
+// NewFilePartSourceSymbol returns a FilePartSource holding v.
+func NewFilePartSourceSymbol(v SymbolSource) FilePartSource {
+	v.Type = SymbolSourceTypeSymbol
+	return newUnion[FilePartSource](v)
+}
+
+// NewAssistantMessageErrorProviderAuth returns an AssistantMessageError
+// holding v.
+func NewAssistantMessageErrorProviderAuth(v shared.ProviderAuthError) AssistantMessageError {
+	v.Name = shared.ProviderAuthErrorNameProviderAuthError
+	return newUnion[AssistantMessageError](v)
+}
+
+// NewAssistantMessageErrorUnknown returns an AssistantMessageError holding v.
+func NewAssistantMessageErrorUnknown(v shared.UnknownError) AssistantMessageError {
+	v.Name = shared.UnknownErrorNameUnknownError
+	return newUnion[AssistantMessageError](v)
+}
+
+// NewAssistantMessageErrorOutputLength returns an AssistantMessageError
+// holding v.
+func NewAssistantMessageErrorOutputLength(v AssistantMessageErrorMessageOutputLengthError) AssistantMessageError {
+	v.Name = AssistantMessageErrorMessageOutputLengthErrorNameMessageOutputLengthError
+	return newUnion[AssistantMessageError](v)
+}
+
+// NewAssistantMessageErrorAborted returns an AssistantMessageError holding v.
+func NewAssistantMessageErrorAborted(v shared.MessageAbortedError) AssistantMessageError {
+	v.Name = shared.MessageAbortedErrorNameMessageAbortedError
+	return newUnion[AssistantMessageError](v)
+}
+
+// NewAssistantMessageErrorAPI returns an AssistantMessageError holding v.
+func NewAssistantMessageErrorAPI(v AssistantMessageErrorAPIError) AssistantMessageError {
+	v.Name = AssistantMessageErrorAPIErrorNameAPIError
+	return newUnion[AssistantMessageError](v)
+}
+
+// NewSessionErrorProviderAuth returns a SessionError holding v.
+func NewSessionErrorProviderAuth(v shared.ProviderAuthError) SessionError {
+	v.Name = shared.ProviderAuthErrorNameProviderAuthError
+	return newUnion[SessionError](v)
+}
+
+// NewSessionErrorUnknown returns a SessionError holding v.
+func NewSessionErrorUnknown(v shared.UnknownError) SessionError {
+	v.Name = shared.UnknownErrorNameUnknownError
+	return newUnion[SessionError](v)
+}
+
+// NewSessionErrorOutputLength returns a SessionError holding v.
+func NewSessionErrorOutputLength(v MessageOutputLengthError) SessionError {
+	v.Name = MessageOutputLengthErrorNameMessageOutputLengthError
+	return newUnion[SessionError](v)
+}
+
+// NewSessionErrorAborted returns a SessionError holding v.
+func NewSessionErrorAborted(v shared.MessageAbortedError) SessionError {
+	v.Name = shared.MessageAbortedErrorNameMessageAbortedError
+	return newUnion[SessionError](v)
+}
+
+// NewSessionErrorAPI returns a SessionError holding v.
+func NewSessionErrorAPI(v SessionAPIError) SessionError {
+	v.Name = SessionAPIErrorNameAPIError
+	return newUnion[SessionError](v)
+}
+
+// NewConfigMcpLocal returns a ConfigMcp holding v.
+func NewConfigMcpLocal(v McpLocalConfig) ConfigMcp {
+	v.Type = McpLocalConfigTypeLocal
+	return newUnion[ConfigMcp](v)
+}
+
+// NewConfigMcpRemote returns a ConfigMcp holding v.
+func NewConfigMcpRemote(v McpRemoteConfig) ConfigMcp {
+	v.Type = McpRemoteConfigTypeRemote
+	return newUnion[ConfigMcp](v)
+}
+
+// EventVariant is implemented by the Event* variant types accepted by
+// NewEvent.
+type EventVariant interface {
+	newEvent() Event
+}
+
+// NewEvent returns an Event holding v, such as an EventSessionIdle.
+func NewEvent(v EventVariant) Event {
+	return v.newEvent()
+}
+
+func (e EventInstallationUpdated) newEvent() Event {
+	e.Type = EventInstallationUpdatedTypeInstallationUpdated
+	return newUnion[Event](e)
+}
+
+func (e EventLspClientDiagnostics) newEvent() Event {
+	e.Type = EventLspClientDiagnosticsTypeLspClientDiagnostics
+	return newUnion[Event](e)
+}
+
+func (e EventMessageUpdated) newEvent() Event {
+	e.Type = EventMessageUpdatedTypeMessageUpdated
+	return newUnion[Event](e)
+}
+
+func (e EventMessageRemoved) newEvent() Event {
+	e.Type = EventMessageRemovedTypeMessageRemoved
+	return newUnion[Event](e)
+}
+
+func (e EventMessagePartUpdated) newEvent() Event {
+	e.Type = EventMessagePartUpdatedTypeMessagePartUpdated
+	return newUnion[Event](e)
+}
+
+func (e EventMessagePartRemoved) newEvent() Event {
+	e.Type = EventMessagePartRemovedTypeMessagePartRemoved
+	return newUnion[Event](e)
+}
+
+func (e EventSessionCompacted) newEvent() Event {
+	e.Type = EventSessionCompactedTypeSessionCompacted
+	return newUnion[Event](e)
+}
+
+func (e EventPermissionUpdated) newEvent() Event {
+	e.Type = EventPermissionUpdatedTypePermissionUpdated
+	return newUnion[Event](e)
+}
+
+func (e EventPermissionReplied) newEvent() Event {
+	e.Type = EventPermissionRepliedTypePermissionReplied
+	return newUnion[Event](e)
+}
+
+func (e EventFileEdited) newEvent() Event {
+	e.Type = EventFileEditedTypeFileEdited
+	return newUnion[Event](e)
+}
+
+func (e EventFileWatcherUpdated) newEvent() Event {
+	e.Type = EventFileWatcherUpdatedTypeFileWatcherUpdated
+	return newUnion[Event](e)
+}
+
+func (e EventTodoUpdated) newEvent() Event {
+	e.Type = EventTodoUpdatedTypeTodoUpdated
+	return newUnion[Event](e)
+}
+
+func (e EventSessionIdle) newEvent() Event {
+	e.Type = EventSessionIdleTypeSessionIdle
+	return newUnion[Event](e)
+}
+
+func (e EventSessionCreated) newEvent() Event {
+	e.Type = EventSessionCreatedTypeSessionCreated
+	return newUnion[Event](e)
+}
+
+func (e EventSessionUpdated) newEvent() Event {
+	e.Type = EventSessionUpdatedTypeSessionUpdated
+	return newUnion[Event](e)
+}
+
+func (e EventSessionDeleted) newEvent() Event {
+	e.Type = EventSessionDeletedTypeSessionDeleted
+	return newUnion[Event](e)
+}
+
+func (e EventSessionError) newEvent() Event {
+	e.Type = EventSessionErrorTypeSessionError
+	return newUnion[Event](e)
+}
+
+func (e EventServerConnected) newEvent() Event {
+	e.Type = EventServerConnectedTypeServerConnected
+	return newUnion[Event](e)
+}
+
+func (e EventIdeInstalled) newEvent() Event {
+	e.Type = EventIdeInstalledTypeIdeInstalled
+	return newUnion[Event](e)
+}
@@ -0,0 +1,141 @@
+package opencode
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNewTextPartValue(t *testing.T) {
+	text := TextPart{ID: "prt_1", MessageID: "msg_1", SessionID: "ses_1", Text: "hello"}
+	part := NewTextPartValue(text)
+
+	if part.Type != PartTypeText || part.ID != "prt_1" || part.MessageID != "msg_1" || part.SessionID != "ses_1" {
+		t.Errorf("part fields = %+v", part)
+	}
+	got, err := part.AsText()
+	if err != nil {
+		t.Fatalf("AsText: %v", err)
+	}
+	text.Type = TextPartTypeText
+	if !reflect.DeepEqual(*got, text) {
+		t.Errorf("AsText = %+v, want %+v", *got, text)
+	}
+	if _, err := part.AsTool(); !errors.Is(err, ErrWrongVariant) {
+		t.Errorf("AsTool err = %v, want ErrWrongVariant", err)
+	}
+
+	// The constructed part encodes and decodes like one from the server.
+	data, err := json.Marshal(part)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	want, _ := json.Marshal(text)
+	if string(data) != string(want) {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+	var decoded Part
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, part) {
+		t.Errorf("decoded %+v, constructed %+v", decoded, part)
+	}
+}
+
+func TestNewToolPartStateCompleted(t *testing.T) {
+	state := NewToolPartStateCompleted(ToolStateCompleted{
+		Input:  map[string]interface{}{"command": "make"},
+		Output: "ok",
+		Time:   ToolStateCompletedTime{Start: 1, End: 2},
+	})
+	if state.Status != ToolPartStateStatusCompleted {
+		t.Errorf("Status = %q", state.Status)
+	}
+	tool := NewToolPartValue(ToolPart{ID: "prt_2", Tool: "bash", State: state})
+
+	v, err := tool.Any()
+	if err != nil {
+		t.Fatalf("Any: %v", err)
+	}
+	got, ok := v.(*ToolPart)
+	if !ok {
+		t.Fatalf("Any = %T, want *ToolPart", v)
+	}
+	completed, err := got.State.AsCompleted()
+	if err != nil {
+		t.Fatalf("AsCompleted: %v", err)
+	}
+	if completed.Output != "ok" || completed.Input["command"] != "make" || completed.Time.End != 2 {
+		t.Errorf("AsCompleted = %+v", completed)
+	}
+}
+
+func TestNewUnions(t *testing.T) {
+	msg := NewMessageAssistant(AssistantMessage{ID: "msg_1", SessionID: "ses_1", ModelID: "claude"})
+	if msg.Role != MessageRoleAssistant || msg.ID != "msg_1" || msg.SessionID != "ses_1" {
+		t.Errorf("message fields = %+v", msg)
+	}
+	if a, err := msg.AsAssistant(); err != nil || a.ModelID != "claude" {
+		t.Errorf("AsAssistant = %+v, %v", a, err)
+	}
+
+	src := NewFilePartSourceSymbol(SymbolSource{Name: "main", Path: "main.go"})
+	if v, err := src.Any(); err != nil {
+		t.Errorf("FilePartSource.Any: %v", err)
+	} else if s, ok := v.(*SymbolSource); !ok || s.Type != SymbolSourceTypeSymbol {
+		t.Errorf("FilePartSource.Any = %#v", v)
+	}
+
+	asstErr := NewAssistantMessageErrorAPI(AssistantMessageErrorAPIError{
+		Data: AssistantMessageErrorAPIErrorData{Message: "overloaded", IsRetryable: true},
+	})
+	if e, err := asstErr.AsAPI(); err != nil || e.Data.Message != "overloaded" {
+		t.Errorf("AsAPI = %+v, %v", e, err)
+	}
+
+	mcp := NewConfigMcpRemote(McpRemoteConfig{URL: "https://example.com"})
+	if r, err := mcp.AsRemote(); err != nil || r.URL != "https://example.com" {
+		t.Errorf("AsRemote = %+v, %v", r, err)
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	idle := NewEvent(EventSessionIdle{Data: EventSessionIdleData{SessionID: "ses_1"}})
+	if idle.Type != EventTypeSessionIdle {
+		t.Errorf("Type = %q", idle.Type)
+	}
+	if id, ok := idle.SessionID(); !ok || id != "ses_1" {
+		t.Errorf("SessionID = %q, %v", id, ok)
+	}
+	if string(idle.Raw()) != `{"properties":{"sessionID":"ses_1"},"type":"session.idle"}` {
+		t.Errorf("Raw = %s", idle.Raw())
+	}
+
+	// Events nest constructed parts, as a proxy relaying synthesized output
+	// would.
+	part := NewTextPartValue(TextPart{ID: "prt_1", SessionID: "ses_1", Text: "hi"})
+	updated := NewEvent(EventMessagePartUpdated{Data: EventMessagePartUpdatedData{Part: part}})
+	var got string
+	err := updated.Visit(EventVisitorFuncs{MessagePartUpdated: func(e *EventMessagePartUpdated) error {
+		text, err := e.Data.Part.AsText()
+		if err != nil {
+			return err
+		}
+		got = text.Text
+		return nil
+	}})
+	if err != nil || got != "hi" {
+		t.Errorf("Visit text=%q err=%v", got, err)
+	}
+}
+
+func TestNewUnion_PanicsOnUnencodableVariant(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	NewToolPartStateRunning(ToolStateRunning{Input: make(chan int)})
+}
@@ -0,0 +1,327 @@
+package opencode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/dominicnunez/opencode-sdk-go/packages/ssestream"
+)
+
+// ConversationParams configures every prompt a Conversation sends.
+type ConversationParams struct {
+	// Directory scopes prompts and the event stream to one workspace. Nil
+	// uses the server's default directory.
+	Directory *string
+	Agent     *string
+	Model     *SessionPromptParamsModel
+	System    *string
+	Tools     *map[string]bool
+	// StreamOptions are passed to EventService.ListStreaming for each reply.
+	StreamOptions []EventStreamOption
+}
+
+type ConversationEventType string
+
+const (
+	// ConversationEventTypeText carries newly generated assistant text.
+	ConversationEventTypeText ConversationEventType = "text"
+	// ConversationEventTypeReasoning carries newly generated reasoning text.
+	ConversationEventTypeReasoning ConversationEventType = "reasoning"
+	// ConversationEventTypeTool carries a tool call's latest state.
+	ConversationEventTypeTool ConversationEventType = "tool"
+	// ConversationEventTypePermission carries a permission request that must
+	// be answered with SessionPermissionService.Respond before the tool runs.
+	ConversationEventTypePermission ConversationEventType = "permission"
+)
+
+func (r ConversationEventType) IsKnown() bool {
+	switch r {
+	case ConversationEventTypeText, ConversationEventTypeReasoning, ConversationEventTypeTool, ConversationEventTypePermission:
+		return true
+	}
+	return false
+}
+
+// ConversationEvent is one live update to a reply. Only the fields relevant
+// to Type are set.
+type ConversationEvent struct {
+	Type ConversationEventType
+	// MessageID is the assistant message the update belongs to.
+	MessageID string
+	PartID    string
+	// Delta is the text appended by a text or reasoning event.
+	Delta      string
+	Tool       *ToolPart
+	Permission *Permission
+}
+
+// Conversation sends prompts to one session and streams each reply as it is
+// generated. Replies are sequential: Send fails while the previous reply's
+// prompt is still in flight.
+//
+//	conv, err := opencode.NewConversation(client, sessionID, nil)
+//	...
+//	reply, err := conv.Send(ctx, "Summarize the README")
+//	...
+//	for evt := range reply.Events() {
+//		if evt.Type == opencode.ConversationEventTypeText {
+//			fmt.Print(evt.Delta)
+//		}
+//	}
+//	resp, err := reply.Wait()
+type Conversation struct {
+	client    *Client
+	sessionID string
+	params    ConversationParams
+
+	busy atomic.Bool
+}
+
+// NewConversation returns a Conversation for an existing session.
+func NewConversation(client *Client, sessionID string, params *ConversationParams) (*Conversation, error) {
+	if client == nil {
+		return nil, requiredFieldError("client")
+	}
+	if strings.TrimSpace(sessionID) == "" {
+		return nil, missingRequiredParameterError("sessionID")
+	}
+	if params == nil {
+		params = &ConversationParams{}
+	}
+	return &Conversation{client: client, sessionID: sessionID, params: *params}, nil
+}
+
+// SessionID returns the session the conversation prompts.
+func (c *Conversation) SessionID() string {
+	return c.sessionID
+}
+
+// Send prompts the session with text. See SendParts.
+func (c *Conversation) Send(ctx context.Context, text string) (*Reply, error) {
+	return c.SendParts(ctx, []SessionPromptParamsPartUnion{
+		TextPartInputParam{Text: text, Type: TextPartInputTypeText},
+	})
+}
+
+// SendParts opens the event stream, then prompts the session with parts and
+// returns a Reply that streams the assistant's response. If ctx is done
+// before the prompt completes, the session is aborted and Wait reports ctx's
+// error.
+func (c *Conversation) SendParts(ctx context.Context, parts []SessionPromptParamsPartUnion) (*Reply, error) {
+	if ctx == nil {
+		return nil, ErrContextRequired
+	}
+	if len(parts) == 0 {
+		return nil, missingRequiredParameterError("parts")
+	}
+	if !c.busy.CompareAndSwap(false, true) {
+		return nil, errors.New("conversation: previous reply is still in progress")
+	}
+
+	streamCtx, cancelStream := context.WithCancel(ctx)
+	stream := c.client.Event.ListStreaming(streamCtx, &EventListParams{Directory: c.params.Directory}, c.params.StreamOptions...)
+	if err := stream.Err(); err != nil {
+		cancelStream()
+		c.busy.Store(false)
+		return nil, err
+	}
+
+	r := &Reply{
+		events:       make(chan ConversationEvent),
+		done:         make(chan struct{}),
+		streamCtx:    streamCtx,
+		cancelStream: cancelStream,
+	}
+	go r.watch(stream, c.sessionID)
+	go c.prompt(ctx, r, &SessionPromptParams{
+		Parts:     parts,
+		Directory: c.params.Directory,
+		Agent:     c.params.Agent,
+		Model:     c.params.Model,
+		System:    c.params.System,
+		Tools:     c.params.Tools,
+	})
+	return r, nil
+}
+
+func (c *Conversation) prompt(ctx context.Context, r *Reply, params *SessionPromptParams) {
+	resp, err := c.client.Session.Prompt(ctx, c.sessionID, params)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		resp, err = nil, ctxErr
+		// The server keeps generating after the request is dropped, so stop
+		// it explicitly. ctx is already done; give the abort its own deadline.
+		abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.client.timeout)
+		if _, abortErr := c.client.Session.Abort(abortCtx, c.sessionID, &SessionAbortParams{Directory: c.params.Directory}); abortErr != nil {
+			err = errors.Join(err, fmt.Errorf("abort session %s: %w", c.sessionID, abortErr))
+		}
+		cancel()
+	}
+	if err != nil {
+		// No more events will belong to this reply.
+		r.cancelStream()
+	}
+	r.resp, r.err = resp, err
+	c.busy.Store(false)
+	close(r.done)
+}
+
+// Reply is the in-flight response to one Conversation.Send.
+type Reply struct {
+	events chan ConversationEvent
+	// streamCtx is canceled by Close, a failed prompt or the Send context.
+	streamCtx    context.Context
+	cancelStream context.CancelFunc
+
+	done chan struct{}
+	resp *SessionPromptResponse
+	err  error
+}
+
+// Events returns the live updates for the reply. The channel is closed once
+// the session goes idle or reports an error, the prompt fails, the Send
+// context is done, or Close is called. Events need not be drained for Wait
+// to return, but the underlying event stream stays open until one of those
+// happens.
+func (r *Reply) Events() <-chan ConversationEvent {
+	return r.events
+}
+
+// Done is closed when the prompt has completed and Wait will not block.
+func (r *Reply) Done() <-chan struct{} {
+	return r.done
+}
+
+// Wait blocks until the prompt completes and returns the final assistant
+// message and its parts.
+func (r *Reply) Wait() (*SessionPromptResponse, error) {
+	<-r.done
+	return r.resp, r.err
+}
+
+// Close stops event delivery. It does not cancel the prompt; cancel the Send
+// context for that.
+func (r *Reply) Close() error {
+	r.cancelStream()
+	return nil
+}
+
+// watch forwards events belonging to this reply. The reply's user message
+// is the first user message created in the session after the stream opened;
+// its assistant messages are the ones whose parent is that message.
+func (r *Reply) watch(stream *ssestream.Stream[Event], sessionID string) {
+	defer close(r.events)
+	defer r.cancelStream()
+	defer func() { _ = stream.Close() }()
+
+	var userMessageID string
+	assemblers := map[string]*MessageAssembler{}
+	for stream.Next() {
+		event := stream.Current()
+		var out *ConversationEvent
+		switch event.Type {
+		case EventTypeMessageUpdated:
+			evt, err := event.AsMessageUpdated()
+			if err != nil || evt.Data.Info.SessionID != sessionID {
+				continue
+			}
+			info := evt.Data.Info
+			switch info.Role {
+			case MessageRoleUser:
+				if userMessageID == "" {
+					userMessageID = info.ID
+				}
+			case MessageRoleAssistant:
+				assistant, err := info.AsAssistant()
+				if err != nil || userMessageID == "" || assistant.ParentID != userMessageID {
+					continue
+				}
+				if assemblers[info.ID] == nil {
+					assemblers[info.ID] = NewMessageAssembler(sessionID, info.ID)
+				}
+				assemblers[info.ID].Apply(event)
+			}
+
+		case EventTypeMessagePartUpdated:
+			evt, err := event.AsMessagePartUpdated()
+			if err != nil {
+				continue
+			}
+			asm := assemblers[evt.Data.Part.MessageID]
+			if asm == nil {
+				continue
+			}
+			update, ok := asm.Apply(event)
+			if !ok {
+				continue
+			}
+			out = conversationPartEvent(update)
+
+		case EventTypePermissionUpdated:
+			evt, err := event.AsPermissionUpdated()
+			if err != nil || evt.Data.SessionID != sessionID || assemblers[evt.Data.MessageID] == nil {
+				continue
+			}
+			permission := evt.Data
+			out = &ConversationEvent{
+				Type:       ConversationEventTypePermission,
+				MessageID:  permission.MessageID,
+				Permission: &permission,
+			}
+
+		case EventTypeSessionIdle:
+			evt, err := event.AsSessionIdle()
+			if err == nil && evt.Data.SessionID == sessionID {
+				return
+			}
+
+		case EventTypeSessionError:
+			// The prompt reports the failure through Wait.
+			if sessionFailure(event, sessionID) != nil {
+				return
+			}
+		}
+
+		if out == nil {
+			continue
+		}
+		select {
+		case r.events <- *out:
+		case <-r.streamCtx.Done():
+			return
+		}
+	}
+}
+
+// conversationPartEvent converts a part update into a ConversationEvent, or
+// returns nil for updates a conversation does not surface.
+func conversationPartEvent(update MessageUpdate) *ConversationEvent {
+	if update.Removed {
+		return nil
+	}
+	part := update.Part
+	evt := &ConversationEvent{MessageID: part.MessageID, PartID: part.ID}
+	switch part.Type {
+	case PartTypeText, PartTypeReasoning:
+		if update.Delta == "" {
+			return nil
+		}
+		evt.Type = ConversationEventTypeText
+		if part.Type == PartTypeReasoning {
+			evt.Type = ConversationEventTypeReasoning
+		}
+		evt.Delta = update.Delta
+	case PartTypeTool:
+		tool, err := part.AsTool()
+		if err != nil {
+			return nil
+		}
+		evt.Type = ConversationEventTypeTool
+		evt.Tool = tool
+	default:
+		return nil
+	}
+	return evt
+}
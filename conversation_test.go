@@ -0,0 +1,207 @@
+package opencode
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type conversationServer struct {
+	prompted   chan struct{}
+	eventsSent chan struct{}
+	aborted    atomic.Int32
+	// events are written to /event once the prompt arrives.
+	events []string
+	// blockPrompt makes the prompt handler wait for the request to be
+	// canceled instead of responding.
+	blockPrompt bool
+}
+
+func newConversationServer(t *testing.T, cs *conversationServer) *Client {
+	t.Helper()
+	cs.prompted = make(chan struct{})
+	cs.eventsSent = make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/event", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		select {
+		case <-cs.prompted:
+		case <-r.Context().Done():
+			return
+		}
+		for _, evt := range cs.events {
+			_, _ = w.Write([]byte("data: " + evt + "\n\n"))
+		}
+		w.(http.Flusher).Flush()
+		close(cs.eventsSent)
+		<-r.Context().Done()
+	})
+	mux.HandleFunc("/session/s1/message", func(w http.ResponseWriter, r *http.Request) {
+		// Drain the body so the server notices when the client disconnects.
+		_, _ = io.Copy(io.Discard, r.Body)
+		close(cs.prompted)
+		if cs.blockPrompt {
+			<-r.Context().Done()
+			return
+		}
+		<-cs.eventsSent
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"info":{"id":"m2","role":"assistant","sessionID":"s1","parentID":"m1","time":{"created":1,"completed":2}},"parts":[{"id":"p1","messageID":"m2","sessionID":"s1","type":"text","text":"Hello"}]}`))
+	})
+	mux.HandleFunc("/session/s1/abort", func(w http.ResponseWriter, r *http.Request) {
+		cs.aborted.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`true`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(WithBaseURL(server.URL), WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client
+}
+
+func collectConversationEvents(t *testing.T, reply *Reply) []ConversationEvent {
+	t.Helper()
+	var got []ConversationEvent
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case evt, ok := <-reply.Events():
+			if !ok {
+				return got
+			}
+			got = append(got, evt)
+		case <-timeout:
+			t.Fatal("events channel was not closed")
+		}
+	}
+}
+
+func TestConversation_SendStreamsReply(t *testing.T) {
+	client := newConversationServer(t, &conversationServer{events: []string{
+		`{"type":"message.updated","properties":{"info":{"id":"m1","role":"user","sessionID":"s1"}}}`,
+		`{"type":"message.updated","properties":{"info":{"id":"m2","role":"assistant","sessionID":"s1","parentID":"m1","time":{"created":1}}}}`,
+		`{"type":"message.part.updated","properties":{"part":{"id":"p0","messageID":"m2","sessionID":"s1","type":"reasoning","text":"hmm"},"delta":"hmm"}}`,
+		`{"type":"message.part.updated","properties":{"part":{"id":"p1","messageID":"m2","sessionID":"s1","type":"text","text":"Hel"},"delta":"Hel"}}`,
+		`{"type":"message.part.updated","properties":{"part":{"id":"x1","messageID":"other","sessionID":"s2","type":"text","text":"noise"},"delta":"noise"}}`,
+		`{"type":"message.part.updated","properties":{"part":{"id":"p2","messageID":"m2","sessionID":"s1","type":"tool","tool":"bash","callID":"c1","state":{"status":"pending"}}}}`,
+		`{"type":"permission.updated","properties":{"id":"perm1","messageID":"m2","sessionID":"s1","type":"bash","title":"run ls","metadata":{},"time":{"created":1}}}`,
+		`{"type":"message.part.updated","properties":{"part":{"id":"p1","messageID":"m2","sessionID":"s1","type":"text","text":"Hello"},"delta":"lo"}}`,
+		`{"type":"session.idle","properties":{"sessionID":"s1"}}`,
+		`{"type":"message.part.updated","properties":{"part":{"id":"p1","messageID":"m2","sessionID":"s1","type":"text","text":"Hello!"},"delta":"!"}}`,
+	}})
+	conv, err := NewConversation(client, "s1", nil)
+	if err != nil {
+		t.Fatalf("NewConversation failed: %v", err)
+	}
+
+	reply, err := conv.Send(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	events := collectConversationEvents(t, reply)
+
+	var types []string
+	var text strings.Builder
+	for _, evt := range events {
+		types = append(types, string(evt.Type))
+		if evt.Type == ConversationEventTypeText {
+			text.WriteString(evt.Delta)
+		}
+	}
+	if got := strings.Join(types, ","); got != "reasoning,text,tool,permission,text" {
+		t.Fatalf("unexpected event sequence %s", got)
+	}
+	if text.String() != "Hello" {
+		t.Errorf("expected text %q, got %q", "Hello", text.String())
+	}
+	if events[2].Tool == nil || events[2].Tool.CallID != "c1" {
+		t.Errorf("expected tool event for c1, got %+v", events[2])
+	}
+	if events[3].Permission == nil || events[3].Permission.ID != "perm1" {
+		t.Errorf("expected permission perm1, got %+v", events[3])
+	}
+
+	resp, err := reply.Wait()
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if resp.Info.ID != "m2" || len(resp.Parts) != 1 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
+
+func TestConversation_CancelAbortsSession(t *testing.T) {
+	cs := &conversationServer{blockPrompt: true}
+	client := newConversationServer(t, cs)
+	conv, err := NewConversation(client, "s1", nil)
+	if err != nil {
+		t.Fatalf("NewConversation failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reply, err := conv.Send(ctx, "hi")
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	<-cs.prompted
+	cancel()
+
+	select {
+	case <-reply.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("reply did not finish after cancellation")
+	}
+	if _, err := reply.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if cs.aborted.Load() != 1 {
+		t.Fatalf("expected one abort request, got %d", cs.aborted.Load())
+	}
+	collectConversationEvents(t, reply)
+}
+
+func TestConversation_RejectsConcurrentSend(t *testing.T) {
+	cs := &conversationServer{blockPrompt: true}
+	client := newConversationServer(t, cs)
+	conv, err := NewConversation(client, "s1", nil)
+	if err != nil {
+		t.Fatalf("NewConversation failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reply, err := conv.Send(ctx, "first")
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if _, err := conv.Send(ctx, "second"); err == nil {
+		t.Fatal("expected second Send to fail while the first is in flight")
+	}
+	cancel()
+	_, _ = reply.Wait()
+}
+
+func TestNewConversation_Validation(t *testing.T) {
+	if _, err := NewConversation(nil, "s1", nil); !errors.Is(err, ErrRequiredField) {
+		t.Errorf("expected required field error, got %v", err)
+	}
+	client, err := NewClient(WithBaseURL("http://localhost"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := NewConversation(client, "  ", nil); !errors.Is(err, ErrMissingRequiredParameter) {
+		t.Errorf("expected missing parameter error, got %v", err)
+	}
+}
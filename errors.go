@@ -9,6 +9,8 @@ import (
 	"strings"
 	"unicode"
 	"unicode/utf8"
+
+	"github.com/dominicnunez/opencode-sdk-go/packages/ssestream"
 )
 
 var (
@@ -38,6 +40,20 @@ var (
 	// ErrWrongVariant is returned when a union type accessor is called with
 	// a discriminator value that does not match the requested variant.
 	ErrWrongVariant = errors.New("wrong union variant")
+	// ErrUnknownVariant is returned by the Visit and Any methods of union
+	// types for a discriminator value the SDK does not model.
+	ErrUnknownVariant = errors.New("unknown union variant")
+	// ErrUnknownEventType is returned by Event.Any for an event type that is
+	// neither built in nor registered with RegisterEventType. Such errors
+	// also match ErrUnknownVariant.
+	ErrUnknownEventType = errors.New("unknown event type")
+	// ErrSessionFailed matches a session.error event observed while waiting
+	// on a session. Use errors.As with *SessionFailedError for the details.
+	ErrSessionFailed = errors.New("session failed")
+	// ErrInvalidStructuredOutput matches PromptJSON giving up on a reply it
+	// could not decode. Use errors.As with *StructuredOutputError for the
+	// details.
+	ErrInvalidStructuredOutput = errors.New("invalid structured output")
 
 	// ErrNilAuth is returned when AuthSetParams.MarshalJSON is called with a nil
 	// Auth field or a non-nil interface holding a nil pointer.
@@ -45,6 +61,11 @@ var (
 	// ErrUnknownAuthType is returned when AuthSetParams.MarshalJSON encounters
 	// an Auth implementation that is not one of OAuth, ApiAuth, or WellKnownAuth.
 	ErrUnknownAuthType = errors.New("unknown auth union type")
+
+	// ErrStreamStalled matches stream errors caused by WithEventIdleTimeout
+	// expiring. Use errors.As with *ssestream.StreamStalledError to read the
+	// configured timeout.
+	ErrStreamStalled = ssestream.ErrStreamStalled
 )
 
 const maxAPIErrorMessageLength = 512
@@ -95,6 +116,69 @@ func requiredFieldError(field string) error {
 	return &RequiredFieldError{Field: field}
 }
 
+// SessionFailedError reports a session.error event for a session being
+// streamed or waited on.
+type SessionFailedError struct {
+	SessionID string
+	// Err is the error the server reported. Its Name is empty if the event
+	// carried no error payload.
+	Err SessionError
+}
+
+func (e *SessionFailedError) Error() string {
+	if e.Err.Name == "" {
+		return fmt.Sprintf("session %s failed", e.SessionID)
+	}
+	return fmt.Sprintf("session %s failed: %s", e.SessionID, e.Err.Name)
+}
+
+func (e *SessionFailedError) Is(target error) bool {
+	return target == ErrSessionFailed
+}
+
+// StructuredOutputError reports that PromptJSON received no valid JSON
+// answer within its retries.
+type StructuredOutputError struct {
+	SessionID string
+	// Attempts is the number of prompts sent.
+	Attempts int
+	// Text is the text of the last reply.
+	Text string
+	// Err is why the last reply was rejected.
+	Err error
+}
+
+func (e *StructuredOutputError) Error() string {
+	attempts := "attempts"
+	if e.Attempts == 1 {
+		attempts = "attempt"
+	}
+	return fmt.Sprintf("session %s: no valid JSON after %d %s: %v", e.SessionID, e.Attempts, attempts, e.Err)
+}
+
+func (e *StructuredOutputError) Is(target error) bool {
+	return target == ErrInvalidStructuredOutput
+}
+
+func (e *StructuredOutputError) Unwrap() error {
+	return e.Err
+}
+
+// unknownEventTypeError reports an event type the SDK cannot decode. It
+// matches both ErrUnknownEventType and ErrUnknownVariant, so callers handling
+// unknown variants of every union need not special-case events.
+type unknownEventTypeError struct {
+	eventType EventType
+}
+
+func (e *unknownEventTypeError) Error() string {
+	return fmt.Sprintf("%q: %s", e.eventType, ErrUnknownEventType)
+}
+
+func (e *unknownEventTypeError) Is(target error) bool {
+	return target == ErrUnknownEventType || target == ErrUnknownVariant
+}
+
 func wrongVariant(expected, actual string) error {
 	return fmt.Errorf("%s, got %s: %w", expected, actual, ErrWrongVariant)
 }
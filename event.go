@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"mime"
 	"net"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/dominicnunez/opencode-sdk-go/internal/queryparams"
@@ -20,6 +22,49 @@ type EventService struct {
 	client *Client
 }
 
+// EventStreamOption configures a single stream opened by
+// EventService.ListStreaming.
+type EventStreamOption func(*eventStreamConfig) error
+
+type eventStreamConfig struct {
+	recorder     io.Writer
+	recordFormat ssestream.RecordFormat
+	idleTimeout  time.Duration
+}
+
+// WithEventIdleTimeout ends the stream with ErrStreamStalled when no data,
+// including the server's SSE comment keep-alives, arrives for d. Without it a
+// half-open connection leaves Next blocked until the context is cancelled.
+func WithEventIdleTimeout(d time.Duration) EventStreamOption {
+	return func(c *eventStreamConfig) error {
+		if d <= 0 {
+			return errors.New("event idle timeout must be positive")
+		}
+		c.idleTimeout = d
+		return nil
+	}
+}
+
+// WithEventRecorder tees the stream to w, stamped with receive times, in the
+// given format. ssestream.RecordFormatSSE records the response body
+// unparsed, keeping ids, retry hints and comments, with timestamp comments
+// added; ssestream.RecordFormatJSONL keeps only each event's type and data. Recordings can be fed back into a
+// stream with ssestream.NewReplayDecoder to reproduce an event sequence
+// offline. The caller owns w and must close it after the stream is closed.
+func WithEventRecorder(w io.Writer, format ssestream.RecordFormat) EventStreamOption {
+	return func(c *eventStreamConfig) error {
+		if w == nil {
+			return errors.New("event recorder writer cannot be nil")
+		}
+		if format != ssestream.RecordFormatJSONL && format != ssestream.RecordFormatSSE {
+			return fmt.Errorf("unknown event record format %d", format)
+		}
+		c.recorder = w
+		c.recordFormat = format
+		return nil
+	}
+}
+
 // ListStreaming opens an SSE connection and returns a stream of events.
 // The returned stream is never nil. Callers must defer stream.Close() to
 // release the underlying HTTP response body, and check stream.Err() after
@@ -34,11 +79,18 @@ type EventService struct {
 //	if err := stream.Err(); err != nil {
 //	    // handle error
 //	}
-func (s *EventService) ListStreaming(ctx context.Context, params *EventListParams) *ssestream.Stream[Event] {
+func (s *EventService) ListStreaming(ctx context.Context, params *EventListParams, opts ...EventStreamOption) *ssestream.Stream[Event] {
 	if ctx == nil {
 		return ssestream.NewStream[Event](nil, ErrContextRequired)
 	}
 
+	var cfg eventStreamConfig
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return ssestream.NewStream[Event](nil, err)
+		}
+	}
+
 	if params == nil {
 		params = &EventListParams{}
 	}
@@ -79,7 +131,16 @@ func (s *EventService) ListStreaming(ctx context.Context, params *EventListParam
 		return ssestream.NewStream[Event](nil, fmt.Errorf(
 			"event stream: unexpected content type %q, expected text/event-stream", mediaType))
 	}
-	return ssestream.NewStream[Event](ssestream.NewDecoder(resp), nil)
+
+	decoderOpts := []ssestream.DecoderOption{ssestream.WithIdleTimeout(cfg.idleTimeout)}
+	if cfg.recorder != nil && cfg.recordFormat == ssestream.RecordFormatSSE {
+		decoderOpts = append(decoderOpts, ssestream.WithRawRecorder(cfg.recorder))
+	}
+	decoder := ssestream.NewDecoder(resp, decoderOpts...)
+	if cfg.recorder != nil && cfg.recordFormat == ssestream.RecordFormatJSONL {
+		decoder = ssestream.NewRecorder(decoder, cfg.recorder)
+	}
+	return ssestream.NewStream[Event](decoder, nil)
 }
 
 func (s *EventService) doStreamingRequest(ctx context.Context, req *http.Request) (*http.Response, error) {
@@ -220,6 +281,219 @@ func (e Event) MarshalJSON() ([]byte, error) {
 	return e.raw, nil
 }
 
+// Raw returns a copy of the event's JSON payload exactly as received. It is
+// useful for logging or inspecting event types this SDK does not model yet.
+func (e Event) Raw() json.RawMessage {
+	if e.raw == nil {
+		return nil
+	}
+	return append(json.RawMessage(nil), e.raw...)
+}
+
+// SessionID returns the ID of the session an event concerns. It reports
+// false for events that are not scoped to a session or that fail to decode.
+func (e Event) SessionID() (string, bool) {
+	var id string
+	switch e.Type {
+	case EventTypeMessageUpdated:
+		evt, err := e.AsMessageUpdated()
+		if err != nil {
+			return "", false
+		}
+		id = evt.Data.Info.SessionID
+	case EventTypeMessageRemoved:
+		evt, err := e.AsMessageRemoved()
+		if err != nil {
+			return "", false
+		}
+		id = evt.Data.SessionID
+	case EventTypeMessagePartUpdated:
+		evt, err := e.AsMessagePartUpdated()
+		if err != nil {
+			return "", false
+		}
+		id = evt.Data.Part.SessionID
+	case EventTypeMessagePartRemoved:
+		evt, err := e.AsMessagePartRemoved()
+		if err != nil {
+			return "", false
+		}
+		id = evt.Data.SessionID
+	case EventTypeSessionCompacted:
+		evt, err := e.AsSessionCompacted()
+		if err != nil {
+			return "", false
+		}
+		id = evt.Data.SessionID
+	case EventTypePermissionUpdated:
+		evt, err := e.AsPermissionUpdated()
+		if err != nil {
+			return "", false
+		}
+		id = evt.Data.SessionID
+	case EventTypePermissionReplied:
+		evt, err := e.AsPermissionReplied()
+		if err != nil {
+			return "", false
+		}
+		id = evt.Data.SessionID
+	case EventTypeTodoUpdated:
+		evt, err := e.AsTodoUpdated()
+		if err != nil {
+			return "", false
+		}
+		id = evt.Data.SessionID
+	case EventTypeSessionIdle:
+		evt, err := e.AsSessionIdle()
+		if err != nil {
+			return "", false
+		}
+		id = evt.Data.SessionID
+	case EventTypeSessionCreated:
+		evt, err := e.AsSessionCreated()
+		if err != nil {
+			return "", false
+		}
+		id = evt.Data.Info.ID
+	case EventTypeSessionUpdated:
+		evt, err := e.AsSessionUpdated()
+		if err != nil {
+			return "", false
+		}
+		id = evt.Data.Info.ID
+	case EventTypeSessionDeleted:
+		evt, err := e.AsSessionDeleted()
+		if err != nil {
+			return "", false
+		}
+		id = evt.Data.Info.ID
+	case EventTypeSessionError:
+		evt, err := e.AsSessionError()
+		if err != nil || evt.Data.SessionID == nil {
+			return "", false
+		}
+		id = *evt.Data.SessionID
+	}
+	return id, id != ""
+}
+
+// Decode unmarshals the full event payload, including the type and
+// properties fields, into v.
+func (e Event) Decode(v any) error {
+	if e.raw == nil {
+		return fmt.Errorf("decode %s: event has no payload", e.Type)
+	}
+	if err := json.Unmarshal(e.raw, v); err != nil {
+		return fmt.Errorf("decode %s: %w", e.Type, err)
+	}
+	return nil
+}
+
+// Any decodes the event into its concrete type. Built-in types return a
+// pointer to the matching Event* struct, such as *EventSessionIdle. Types
+// registered with RegisterEventType return whatever their decoder produces.
+// Anything else returns an error matching ErrUnknownEventType and
+// ErrUnknownVariant.
+func (e Event) Any() (any, error) {
+	switch e.Type {
+	case EventTypeInstallationUpdated:
+		return anyVariant(e.AsInstallationUpdated())
+	case EventTypeLspClientDiagnostics:
+		return anyVariant(e.AsLspClientDiagnostics())
+	case EventTypeMessageUpdated:
+		return anyVariant(e.AsMessageUpdated())
+	case EventTypeMessageRemoved:
+		return anyVariant(e.AsMessageRemoved())
+	case EventTypeMessagePartUpdated:
+		return anyVariant(e.AsMessagePartUpdated())
+	case EventTypeMessagePartRemoved:
+		return anyVariant(e.AsMessagePartRemoved())
+	case EventTypeSessionCompacted:
+		return anyVariant(e.AsSessionCompacted())
+	case EventTypePermissionUpdated:
+		return anyVariant(e.AsPermissionUpdated())
+	case EventTypePermissionReplied:
+		return anyVariant(e.AsPermissionReplied())
+	case EventTypeFileEdited:
+		return anyVariant(e.AsFileEdited())
+	case EventTypeFileWatcherUpdated:
+		return anyVariant(e.AsFileWatcherUpdated())
+	case EventTypeTodoUpdated:
+		return anyVariant(e.AsTodoUpdated())
+	case EventTypeSessionIdle:
+		return anyVariant(e.AsSessionIdle())
+	case EventTypeSessionCreated:
+		return anyVariant(e.AsSessionCreated())
+	case EventTypeSessionUpdated:
+		return anyVariant(e.AsSessionUpdated())
+	case EventTypeSessionDeleted:
+		return anyVariant(e.AsSessionDeleted())
+	case EventTypeSessionError:
+		return anyVariant(e.AsSessionError())
+	case EventTypeServerConnected:
+		return anyVariant(e.AsServerConnected())
+	case EventTypeIdeInstalled:
+		return anyVariant(e.AsIdeInstalled())
+	}
+
+	eventDecodersMu.RLock()
+	decode, ok := eventDecoders[e.Type]
+	eventDecodersMu.RUnlock()
+	if !ok {
+		return nil, &unknownEventTypeError{eventType: e.Type}
+	}
+	v, err := decode(e.Raw())
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", e.Type, err)
+	}
+	return v, nil
+}
+
+// anyVariant adapts an As* accessor result so a failed decode yields a nil
+// interface rather than an interface holding a nil pointer.
+func anyVariant[T any](v *T, err error) (any, error) {
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+var (
+	eventDecoders   = map[EventType]func(json.RawMessage) (any, error){}
+	eventDecodersMu sync.RWMutex
+)
+
+// RegisterEventType installs a decoder for an event type the SDK does not
+// model, such as one emitted by a plugin or a newer server, so Event.Any can
+// return a typed value for it. decode receives the event's full JSON payload.
+// Built-in types cannot be overridden; registering a custom type again
+// replaces its decoder. RegisterEventType is safe for concurrent use.
+func RegisterEventType(eventType EventType, decode func(raw json.RawMessage) (any, error)) error {
+	if decode == nil {
+		return errors.New("RegisterEventType decoder cannot be nil")
+	}
+	if eventType == "" {
+		return errors.New("RegisterEventType event type cannot be empty")
+	}
+	if eventType.IsKnown() {
+		return fmt.Errorf("RegisterEventType cannot override built-in event type %q", eventType)
+	}
+
+	eventDecodersMu.Lock()
+	eventDecoders[eventType] = decode
+	eventDecodersMu.Unlock()
+	return nil
+}
+
+// IsRegistered reports whether a decoder for r was installed with
+// RegisterEventType. Built-in types, reported by IsKnown, are not registered.
+func (r EventType) IsRegistered() bool {
+	eventDecodersMu.RLock()
+	_, ok := eventDecoders[r]
+	eventDecodersMu.RUnlock()
+	return ok
+}
+
 // AsInstallationUpdated returns the event as EventInstallationUpdated if Type is "installation.updated".
 func (e Event) AsInstallationUpdated() (*EventInstallationUpdated, error) {
 	if e.Type != EventTypeInstallationUpdated {
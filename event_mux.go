@@ -0,0 +1,290 @@
+package opencode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// errEventStreamEnded records a source whose server closed the stream
+// without reporting an error.
+var errEventStreamEnded = errors.New("event stream ended")
+
+// EventSource describes one event stream merged by an EventMux.
+type EventSource struct {
+	// Name identifies the source on every SourcedEvent and in health
+	// reports. It must be unique within a mux.
+	Name   string
+	Client *Client
+	// Params selects the stream, typically by Directory. Nil streams the
+	// server's default directory.
+	Params  *EventListParams
+	Options []EventStreamOption
+}
+
+// SourcedEvent is an event tagged with the source it arrived on.
+type SourcedEvent struct {
+	Source string
+	// Directory is the source's Params.Directory, or "" when unset.
+	Directory  string
+	ReceivedAt time.Time
+	Event      Event
+}
+
+type EventSourceState string
+
+const (
+	EventSourceStateConnecting EventSourceState = "connecting"
+	EventSourceStateConnected  EventSourceState = "connected"
+	EventSourceStateBackoff    EventSourceState = "backoff"
+	EventSourceStateStopped    EventSourceState = "stopped"
+)
+
+func (r EventSourceState) IsKnown() bool {
+	switch r {
+	case EventSourceStateConnecting, EventSourceStateConnected, EventSourceStateBackoff, EventSourceStateStopped:
+		return true
+	}
+	return false
+}
+
+// EventSourceHealth is a point-in-time snapshot of one source's connection.
+type EventSourceHealth struct {
+	Name  string
+	State EventSourceState
+	// Events counts events delivered from this source across reconnects.
+	Events      int64
+	LastEventAt time.Time
+	// Reconnects counts connection attempts after the first.
+	Reconnects int
+	// LastError is the error that ended the most recent connection, or nil
+	// if the source has not disconnected yet.
+	LastError error
+}
+
+// EventMux merges the event streams of several sources, such as different
+// workspace directories or servers, into one channel ordered by arrival. Each
+// source reconnects independently with jittered exponential backoff after
+// network failures, retryable server statuses and streams that end or stall.
+// A source stops when the mux's context ends or on any other error, such as a
+// non-retryable status or a response that is not an event stream; Health
+// reports the error as its LastError.
+//
+//	mux, err := opencode.NewEventMux([]opencode.EventSource{
+//		{Name: "api", Client: client, Params: &opencode.EventListParams{Directory: opencode.Ptr("/src/api")}},
+//		{Name: "web", Client: client, Params: &opencode.EventListParams{Directory: opencode.Ptr("/src/web")}},
+//	})
+//	...
+//	events, err := mux.Start(ctx)
+//	...
+//	for evt := range events {
+//		fmt.Println(evt.Source, evt.Event.Type)
+//	}
+type EventMux struct {
+	sources []*muxSource
+	// backoff returns the delay before reconnect attempt n (0-based).
+	backoff func(attempt int) time.Duration
+
+	mu      sync.Mutex
+	started bool
+}
+
+type muxSource struct {
+	EventSource
+	directory string
+
+	mu     sync.Mutex
+	health EventSourceHealth
+}
+
+// NewEventMux validates the sources and returns a mux ready to Start.
+func NewEventMux(sources []EventSource) (*EventMux, error) {
+	if len(sources) == 0 {
+		return nil, errors.New("event mux requires at least one source")
+	}
+
+	m := &EventMux{backoff: retryBackoffDelay}
+	seen := make(map[string]bool, len(sources))
+	for i, src := range sources {
+		if src.Name == "" {
+			return nil, fmt.Errorf("event source %d: %w", i, requiredFieldError("Name"))
+		}
+		if src.Client == nil {
+			return nil, fmt.Errorf("event source %q: %w", src.Name, requiredFieldError("Client"))
+		}
+		if seen[src.Name] {
+			return nil, fmt.Errorf("duplicate event source name %q", src.Name)
+		}
+		seen[src.Name] = true
+		if err := validateEventSource(src); err != nil {
+			return nil, fmt.Errorf("event source %q: %w", src.Name, err)
+		}
+
+		ms := &muxSource{EventSource: src}
+		if src.Params != nil && src.Params.Directory != nil {
+			ms.directory = *src.Params.Directory
+		}
+		ms.health = EventSourceHealth{Name: src.Name, State: EventSourceStateConnecting}
+		m.sources = append(m.sources, ms)
+	}
+	return m, nil
+}
+
+// validateEventSource catches the configuration errors ListStreaming would
+// otherwise report on every connection attempt.
+func validateEventSource(src EventSource) error {
+	var cfg eventStreamConfig
+	for _, opt := range src.Options {
+		if opt == nil {
+			return errors.New("event stream option cannot be nil")
+		}
+		if err := opt(&cfg); err != nil {
+			return err
+		}
+	}
+	params := src.Params
+	if params == nil {
+		params = &EventListParams{}
+	}
+	_, err := src.Client.buildURL("event", params)
+	return err
+}
+
+// Start connects every source and returns the merged event channel. The
+// channel is closed once ctx is done and every source has stopped, or when
+// all sources have stopped on their own. Start may be called only once.
+func (m *EventMux) Start(ctx context.Context) (<-chan SourcedEvent, error) {
+	if ctx == nil {
+		return nil, ErrContextRequired
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.started {
+		return nil, errors.New("event mux already started")
+	}
+	m.started = true
+
+	out := make(chan SourcedEvent)
+	var wg sync.WaitGroup
+	for _, src := range m.sources {
+		wg.Add(1)
+		go func(src *muxSource) {
+			defer wg.Done()
+			m.runSource(ctx, src, out)
+		}(src)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out, nil
+}
+
+// Health returns a snapshot of every source in the order they were given to
+// NewEventMux.
+func (m *EventMux) Health() []EventSourceHealth {
+	health := make([]EventSourceHealth, 0, len(m.sources))
+	for _, src := range m.sources {
+		src.mu.Lock()
+		health = append(health, src.health)
+		src.mu.Unlock()
+	}
+	return health
+}
+
+func (m *EventMux) runSource(ctx context.Context, src *muxSource, out chan<- SourcedEvent) {
+	attempt := 0
+	for {
+		delivered, err := m.streamSource(ctx, src, out)
+		if ctx.Err() != nil {
+			src.update(func(h *EventSourceHealth) { h.State = EventSourceStateStopped })
+			return
+		}
+		if err == nil {
+			err = errEventStreamEnded
+		}
+
+		if !reconnectable(err) {
+			src.update(func(h *EventSourceHealth) {
+				h.State = EventSourceStateStopped
+				h.LastError = err
+			})
+			return
+		}
+
+		if delivered {
+			attempt = 0
+		}
+		delay := m.backoff(attempt)
+		attempt++
+		src.update(func(h *EventSourceHealth) {
+			h.State = EventSourceStateBackoff
+			h.LastError = err
+		})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			src.update(func(h *EventSourceHealth) { h.State = EventSourceStateStopped })
+			return
+		}
+		src.update(func(h *EventSourceHealth) {
+			h.State = EventSourceStateConnecting
+			h.Reconnects++
+		})
+	}
+}
+
+// streamSource runs one connection to completion and reports whether it
+// delivered any events.
+func (m *EventMux) streamSource(ctx context.Context, src *muxSource, out chan<- SourcedEvent) (bool, error) {
+	stream := src.Client.Event.ListStreaming(ctx, src.Params, src.Options...)
+	defer func() { _ = stream.Close() }()
+
+	delivered := false
+	for stream.Next() {
+		now := time.Now()
+		delivered = true
+		src.update(func(h *EventSourceHealth) {
+			h.State = EventSourceStateConnected
+			h.Events++
+			h.LastEventAt = now
+		})
+
+		select {
+		case out <- SourcedEvent{Source: src.Name, Directory: src.directory, ReceivedAt: now, Event: stream.Current()}:
+		case <-ctx.Done():
+			return delivered, ctx.Err()
+		}
+	}
+	return delivered, stream.Err()
+}
+
+// reconnectable reports whether a source whose connection ended with err
+// may succeed by reconnecting. Errors other than network failures, stalls,
+// and retryable API statuses are permanent, such as a request the client
+// cannot build or a response that is not an event stream.
+func reconnectable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.IsRetryable()
+	}
+	var netErr net.Error
+	return errors.Is(err, errEventStreamEnded) ||
+		errors.Is(err, ErrStreamStalled) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr)
+}
+
+func (s *muxSource) update(fn func(*EventSourceHealth)) {
+	s.mu.Lock()
+	fn(&s.health)
+	s.mu.Unlock()
+}
@@ -0,0 +1,270 @@
+package opencode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newMuxTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client
+}
+
+func TestNewEventMux_Validation(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		sources []EventSource
+	}{
+		{"no sources", nil},
+		{"missing name", []EventSource{{Client: client}}},
+		{"missing client", []EventSource{{Name: "a"}}},
+		{"duplicate name", []EventSource{{Name: "a", Client: client}, {Name: "a", Client: client}}},
+		{"invalid option", []EventSource{{Name: "a", Client: client, Options: []EventStreamOption{WithEventIdleTimeout(0)}}}},
+		{"nil option", []EventSource{{Name: "a", Client: client, Options: []EventStreamOption{nil}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewEventMux(tt.sources); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestEventMux_MergesAndTagsSources(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		dir := r.URL.Query().Get("directory")
+		_, _ = fmt.Fprintf(w, "data: {\"type\":\"file.edited\",\"properties\":{\"file\":%q}}\n\n", dir)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}
+	clientA := newMuxTestClient(t, handler)
+	clientB := newMuxTestClient(t, handler)
+
+	mux, err := NewEventMux([]EventSource{
+		{Name: "a", Client: clientA, Params: &EventListParams{Directory: Ptr("/work/a")}},
+		{Name: "b", Client: clientB, Params: &EventListParams{Directory: Ptr("/work/b")}},
+	})
+	if err != nil {
+		t.Fatalf("NewEventMux failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := mux.Start(ctx)
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	seen := map[string]string{}
+	for len(seen) < 2 {
+		evt, ok := <-events
+		if !ok {
+			t.Fatal("events channel closed early")
+		}
+		edited, err := evt.Event.AsFileEdited()
+		if err != nil {
+			t.Fatalf("unexpected event: %v", err)
+		}
+		if edited.Data.File != evt.Directory {
+			t.Errorf("source %s: event for %q tagged with directory %q", evt.Source, edited.Data.File, evt.Directory)
+		}
+		seen[evt.Source] = evt.Directory
+	}
+	if seen["a"] != "/work/a" || seen["b"] != "/work/b" {
+		t.Fatalf("unexpected source tags: %v", seen)
+	}
+
+	for _, h := range mux.Health() {
+		if h.State != EventSourceStateConnected || h.Events != 1 {
+			t.Errorf("source %s: expected connected with 1 event, got %+v", h.Name, h)
+		}
+	}
+
+	cancel()
+	for range events {
+	}
+	for _, h := range mux.Health() {
+		if h.State != EventSourceStateStopped {
+			t.Errorf("source %s: expected stopped after cancel, got %s", h.Name, h.State)
+		}
+	}
+}
+
+func TestEventMux_ReconnectsSourceIndependently(t *testing.T) {
+	var connects atomic.Int32
+	flaky := newMuxTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		n := connects.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprintf(w, "data: {\"type\":\"ide.installed\",\"properties\":{\"ide\":\"%d\"}}\n\n", n)
+		// Return immediately so the stream ends and the source reconnects.
+	})
+
+	mux, err := NewEventMux([]EventSource{{Name: "flaky", Client: flaky}})
+	if err != nil {
+		t.Fatalf("NewEventMux failed: %v", err)
+	}
+	mux.backoff = func(int) time.Duration { return time.Millisecond }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := mux.Start(ctx)
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, ok := <-events; !ok {
+			t.Fatal("events channel closed early")
+		}
+	}
+	health := mux.Health()[0]
+	if health.Reconnects < 2 {
+		t.Errorf("expected at least 2 reconnects, got %d", health.Reconnects)
+	}
+	if !errors.Is(health.LastError, errEventStreamEnded) {
+		t.Errorf("expected errEventStreamEnded, got %v", health.LastError)
+	}
+	cancel()
+	for range events {
+	}
+}
+
+func TestEventMux_NonRetryableErrorStopsOnlyThatSource(t *testing.T) {
+	denied := newMuxTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusUnauthorized)
+	})
+	healthy := newMuxTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"type\":\"server.connected\",\"properties\":{}}\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	mux, err := NewEventMux([]EventSource{
+		{Name: "denied", Client: denied},
+		{Name: "healthy", Client: healthy},
+	})
+	if err != nil {
+		t.Fatalf("NewEventMux failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := mux.Start(ctx)
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	evt := <-events
+	if evt.Source != "healthy" {
+		t.Fatalf("expected event from healthy source, got %q", evt.Source)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		h := mux.Health()[0]
+		if h.State == EventSourceStateStopped {
+			if !IsUnauthorizedError(h.LastError) {
+				t.Fatalf("expected unauthorized error, got %v", h.LastError)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("denied source never stopped: %+v", h)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	for range events {
+	}
+}
+
+func TestEventMux_PermanentErrorStopsSource(t *testing.T) {
+	// A server that answers with something other than an event stream will
+	// not start streaming on a later attempt.
+	var connects atomic.Int32
+	wrong := newMuxTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		connects.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html></html>"))
+	})
+	// A refused connection is a network error and keeps retrying.
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	refused, err := NewClient(WithBaseURL(closed.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	mux, err := NewEventMux([]EventSource{{Name: "wrong", Client: wrong}, {Name: "refused", Client: refused}})
+	if err != nil {
+		t.Fatalf("NewEventMux failed: %v", err)
+	}
+	mux.backoff = func(int) time.Duration { return time.Millisecond }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := mux.Start(ctx)
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		health := mux.Health()
+		if health[0].State == EventSourceStateStopped && health[1].Reconnects >= 2 {
+			if health[0].LastError == nil || health[0].Reconnects != 0 || connects.Load() != 1 {
+				t.Fatalf("expected wrong source to stop after one attempt, got %+v", health[0])
+			}
+			if health[1].State == EventSourceStateStopped {
+				t.Fatalf("expected refused source to keep retrying, got %+v", health[1])
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected health %+v", health)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	for range events {
+	}
+}
+
+func TestEventMux_StartTwiceFails(t *testing.T) {
+	client, err := NewClient()
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	mux, err := NewEventMux([]EventSource{{Name: "a", Client: client}})
+	if err != nil {
+		t.Fatalf("NewEventMux failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := mux.Start(ctx); err != nil {
+		t.Fatalf("first Start failed: %v", err)
+	}
+	if _, err := mux.Start(ctx); err == nil {
+		t.Fatal("expected second Start to fail")
+	}
+}
@@ -0,0 +1,115 @@
+package opencode_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dominicnunez/opencode-sdk-go"
+	"github.com/dominicnunez/opencode-sdk-go/packages/ssestream"
+)
+
+func TestListStreaming_RecorderRoundTripsThroughReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: {\"type\":\"session.idle\",\"properties\":{\"sessionID\":\"s1\"}}\n\n")
+		_, _ = io.WriteString(w, ": keep-alive\n\n")
+		_, _ = io.WriteString(w, "data: {\"type\":\"file.edited\",\"properties\":{\"file\":\"a.go\"}}\n\n")
+	}))
+	defer server.Close()
+
+	client, err := opencode.NewClient(opencode.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var recording bytes.Buffer
+	stream := client.Event.ListStreaming(context.Background(), nil,
+		opencode.WithEventRecorder(&recording, ssestream.RecordFormatJSONL))
+	var live []opencode.EventType
+	for stream.Next() {
+		live = append(live, stream.Current().Type)
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+	_ = stream.Close()
+
+	if got := strings.Count(recording.String(), "\n"); got != 2 {
+		t.Fatalf("expected 2 recorded lines, got %d: %q", got, recording.String())
+	}
+
+	replay := ssestream.NewStream[opencode.Event](
+		ssestream.NewReplayDecoder(io.NopCloser(&recording)), nil)
+	defer func() { _ = replay.Close() }()
+	var replayed []opencode.EventType
+	for replay.Next() {
+		replayed = append(replayed, replay.Current().Type)
+	}
+	if err := replay.Err(); err != nil {
+		t.Fatalf("unexpected replay error: %v", err)
+	}
+
+	if len(live) != 2 || len(replayed) != 2 || live[0] != replayed[0] || live[1] != replayed[1] {
+		t.Fatalf("replay mismatch: live=%v replayed=%v", live, replayed)
+	}
+	edited, err := replay.Current().AsFileEdited()
+	if err != nil || edited.Data.File != "a.go" {
+		t.Fatalf("expected replayed file.edited for a.go, got %+v, %v", edited, err)
+	}
+}
+
+func TestListStreaming_SSERecorderKeepsRawFrames(t *testing.T) {
+	const body = "retry: 1000\n\nid: 7\r\ndata: {\"type\":\"session.idle\",\"properties\":{\"sessionID\":\"s1\"}}\r\n\r\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, body)
+	}))
+	defer server.Close()
+
+	client, err := opencode.NewClient(opencode.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var recording bytes.Buffer
+	stream := client.Event.ListStreaming(context.Background(), nil,
+		opencode.WithEventRecorder(&recording, ssestream.RecordFormatSSE))
+	for stream.Next() {
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+	_ = stream.Close()
+
+	var stripped strings.Builder
+	for _, line := range strings.SplitAfter(recording.String(), "\n") {
+		if !strings.HasPrefix(line, ": recorded-at ") {
+			stripped.WriteString(line)
+		}
+	}
+	if stripped.String() != body {
+		t.Fatalf("recording without timestamps = %q, want %q", stripped.String(), body)
+	}
+}
+
+func TestListStreaming_RecorderRejectsNilWriter(t *testing.T) {
+	client, err := opencode.NewClient()
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	stream := client.Event.ListStreaming(context.Background(), nil,
+		opencode.WithEventRecorder(nil, ssestream.RecordFormatJSONL))
+	defer func() { _ = stream.Close() }()
+	if stream.Next() {
+		t.Fatal("expected Next() to return false")
+	}
+	if err := stream.Err(); err == nil || !strings.Contains(err.Error(), "writer cannot be nil") {
+		t.Fatalf("expected nil writer error, got %v", err)
+	}
+}
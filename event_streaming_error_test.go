@@ -683,3 +683,55 @@ func (b *closeTrackedBody) Close() error {
 	atomic.AddInt32(b.closed, 1)
 	return nil
 }
+
+func TestListStreaming_IdleTimeoutReportsStreamStalled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"type\":\"server.connected\",\"properties\":{}}\n\n"))
+		w.(http.Flusher).Flush()
+		// Hold the connection open without sending anything, like a
+		// half-open TCP connection would.
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := opencode.NewClient(opencode.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	stream := client.Event.ListStreaming(context.Background(), nil,
+		opencode.WithEventIdleTimeout(100*time.Millisecond))
+	defer func() { _ = stream.Close() }()
+
+	if !stream.Next() {
+		t.Fatalf("expected first event, err=%v", stream.Err())
+	}
+	if stream.Next() {
+		t.Fatal("expected Next() to return false once the stream stalls")
+	}
+	if !errors.Is(stream.Err(), opencode.ErrStreamStalled) {
+		t.Fatalf("expected ErrStreamStalled, got %v", stream.Err())
+	}
+}
+
+func TestListStreaming_IdleTimeoutMustBePositive(t *testing.T) {
+	client, err := opencode.NewClient()
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	stream := client.Event.ListStreaming(context.Background(), nil, opencode.WithEventIdleTimeout(0))
+	defer func() { _ = stream.Close() }()
+	if stream.Next() {
+		t.Fatal("expected Next() to return false")
+	}
+	if err := stream.Err(); err == nil || !strings.Contains(err.Error(), "idle timeout must be positive") {
+		t.Fatalf("expected idle timeout validation error, got %v", err)
+	}
+}
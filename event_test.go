@@ -293,3 +293,189 @@ func TestEvent_AllTypes(t *testing.T) {
 		})
 	}
 }
+
+// saveAndRestoreEventDecoders snapshots the custom event decoder registry and
+// restores it when the test completes.
+func saveAndRestoreEventDecoders(t *testing.T) {
+	t.Helper()
+	eventDecodersMu.Lock()
+	snapshot := make(map[EventType]func(json.RawMessage) (any, error), len(eventDecoders))
+	for k, v := range eventDecoders {
+		snapshot[k] = v
+	}
+	eventDecodersMu.Unlock()
+	t.Cleanup(func() {
+		eventDecodersMu.Lock()
+		eventDecoders = snapshot
+		eventDecodersMu.Unlock()
+	})
+}
+
+func TestEvent_RawAndDecode_UnknownType(t *testing.T) {
+	jsonData := `{"type":"plugin.progress","properties":{"percent":42}}`
+	var event Event
+	if err := json.Unmarshal([]byte(jsonData), &event); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	raw := event.Raw()
+	if string(raw) != jsonData {
+		t.Fatalf("Raw mismatch: got %s", raw)
+	}
+	raw[0] = 'X'
+	if string(event.Raw()) != jsonData {
+		t.Fatal("Raw must return a copy")
+	}
+
+	var decoded struct {
+		Properties struct {
+			Percent int `json:"percent"`
+		} `json:"properties"`
+	}
+	if err := event.Decode(&decoded); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if decoded.Properties.Percent != 42 {
+		t.Errorf("Expected percent 42, got %d", decoded.Properties.Percent)
+	}
+}
+
+func TestEvent_Decode_ZeroValue(t *testing.T) {
+	var event Event
+	if event.Raw() != nil {
+		t.Error("Expected nil Raw for zero Event")
+	}
+	var v map[string]any
+	if err := event.Decode(&v); err == nil {
+		t.Fatal("Expected error decoding zero Event")
+	}
+}
+
+func TestEvent_Any_BuiltInType(t *testing.T) {
+	var event Event
+	if err := json.Unmarshal([]byte(`{"type":"session.idle","properties":{"sessionID":"s1"}}`), &event); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	v, err := event.Any()
+	if err != nil {
+		t.Fatalf("Any failed: %v", err)
+	}
+	idle, ok := v.(*EventSessionIdle)
+	if !ok {
+		t.Fatalf("Expected *EventSessionIdle, got %T", v)
+	}
+	if idle.Data.SessionID != "s1" {
+		t.Errorf("Expected session s1, got %s", idle.Data.SessionID)
+	}
+}
+
+func TestEvent_Any_UnknownType(t *testing.T) {
+	var event Event
+	if err := json.Unmarshal([]byte(`{"type":"future.event","properties":{}}`), &event); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+
+	v, err := event.Any()
+	if !errors.Is(err, ErrUnknownEventType) {
+		t.Fatalf("Expected ErrUnknownEventType, got %v", err)
+	}
+	if v != nil {
+		t.Errorf("Expected nil value, got %v", v)
+	}
+}
+
+type pluginProgressEvent struct {
+	Properties struct {
+		Percent int `json:"percent"`
+	} `json:"properties"`
+}
+
+func TestEvent_Any_RegisteredType(t *testing.T) {
+	saveAndRestoreEventDecoders(t)
+	const pluginType EventType = "plugin.progress"
+
+	if pluginType.IsRegistered() {
+		t.Fatal("Expected type to be unregistered before RegisterEventType")
+	}
+	err := RegisterEventType(pluginType, func(raw json.RawMessage) (any, error) {
+		var evt pluginProgressEvent
+		err := json.Unmarshal(raw, &evt)
+		return &evt, err
+	})
+	if err != nil {
+		t.Fatalf("RegisterEventType failed: %v", err)
+	}
+	if !pluginType.IsRegistered() || pluginType.IsKnown() {
+		t.Fatal("Expected registered, non-built-in event type")
+	}
+
+	var event Event
+	if err := json.Unmarshal([]byte(`{"type":"plugin.progress","properties":{"percent":7}}`), &event); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	v, err := event.Any()
+	if err != nil {
+		t.Fatalf("Any failed: %v", err)
+	}
+	progress, ok := v.(*pluginProgressEvent)
+	if !ok || progress.Properties.Percent != 7 {
+		t.Fatalf("Expected *pluginProgressEvent with percent 7, got %#v", v)
+	}
+}
+
+func TestEvent_Any_RegisteredDecoderError(t *testing.T) {
+	saveAndRestoreEventDecoders(t)
+	decodeErr := errors.New("bad payload")
+	if err := RegisterEventType("plugin.fail", func(json.RawMessage) (any, error) { return nil, decodeErr }); err != nil {
+		t.Fatalf("RegisterEventType failed: %v", err)
+	}
+
+	var event Event
+	if err := json.Unmarshal([]byte(`{"type":"plugin.fail"}`), &event); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if _, err := event.Any(); !errors.Is(err, decodeErr) {
+		t.Fatalf("Expected decoder error, got %v", err)
+	}
+}
+
+func TestRegisterEventType_Validation(t *testing.T) {
+	saveAndRestoreEventDecoders(t)
+	decode := func(json.RawMessage) (any, error) { return nil, nil }
+
+	if err := RegisterEventType("plugin.x", nil); err == nil {
+		t.Error("Expected error for nil decoder")
+	}
+	if err := RegisterEventType("", decode); err == nil {
+		t.Error("Expected error for empty event type")
+	}
+	if err := RegisterEventType(EventTypeSessionIdle, decode); err == nil {
+		t.Error("Expected error when overriding a built-in event type")
+	}
+}
+
+func TestEventSessionID(t *testing.T) {
+	tests := []struct {
+		raw    string
+		want   string
+		wantOK bool
+	}{
+		{`{"type":"message.updated","properties":{"info":{"id":"m1","role":"user","sessionID":"s1"}}}`, "s1", true},
+		{`{"type":"message.part.updated","properties":{"part":{"id":"p1","messageID":"m1","sessionID":"s2","type":"text"}}}`, "s2", true},
+		{`{"type":"session.updated","properties":{"info":{"id":"s3"}}}`, "s3", true},
+		{`{"type":"session.error","properties":{"sessionID":"s4"}}`, "s4", true},
+		{`{"type":"session.error","properties":{}}`, "", false},
+		{`{"type":"file.edited","properties":{"file":"a.go"}}`, "", false},
+	}
+	for _, tt := range tests {
+		var event Event
+		if err := json.Unmarshal([]byte(tt.raw), &event); err != nil {
+			t.Fatalf("failed to unmarshal %s: %v", tt.raw, err)
+		}
+		got, ok := event.SessionID()
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: SessionID() = %q, %v; want %q, %v", event.Type, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
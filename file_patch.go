@@ -0,0 +1,249 @@
+package opencode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dominicnunez/opencode-sdk-go/internal/textlines"
+)
+
+// ErrPatchConflict is returned by FileReadResponsePatch.Apply when a hunk
+// cannot be located in the content it is applied to.
+var ErrPatchConflict = errors.New("patch does not apply")
+
+// maxPatchFuzz is how many context lines at each end of a hunk may be ignored
+// when it does not match in full, as with patch --fuzz.
+const maxPatchFuzz = 2
+
+// Apply applies the patch to original and returns the patched content.
+//
+// Hunks are matched the way patch(1) matches them: each is first looked for
+// at the line it names, adjusted by how far earlier hunks moved, then at the
+// nearest offset in either direction. If the hunk still does not match, up to
+// two lines of leading and trailing context are ignored, and finally lines
+// are compared ignoring whitespace, since the server builds patches with
+// whitespace changes ignored. Context lines keep the content found in
+// original. An error matching ErrPatchConflict names the first hunk that
+// could not be placed.
+func (r FileReadResponsePatch) Apply(original []byte) ([]byte, error) {
+	lines := textlines.Split(string(original))
+	var out []string
+	pos, shift := 0, 0
+
+	for i, h := range r.Hunks {
+		hunk, err := h.parse()
+		if err != nil {
+			return nil, fmt.Errorf("invalid patch hunk %d: %w", i+1, err)
+		}
+		base := int(h.OldStart) - 1
+		if h.OldLines == 0 {
+			base = int(h.OldStart)
+		}
+		at, lead, trail := locateHunk(lines, hunk, pos, base+shift)
+		if at < 0 {
+			return nil, fmt.Errorf("%w: hunk %d (%s) does not match", ErrPatchConflict, i+1, h.header())
+		}
+
+		out = append(out, lines[pos:at]...)
+		cur := at
+		for _, line := range hunk[lead : len(hunk)-trail] {
+			switch line.kind {
+			case ' ':
+				out = append(out, lines[cur])
+				cur++
+			case '-':
+				cur++
+			case '+':
+				out = append(out, line.text)
+			}
+		}
+		pos = cur
+		shift = at - (base + lead)
+	}
+	out = append(out, lines[pos:]...)
+	return []byte(strings.Join(out, "")), nil
+}
+
+// Revert undoes the patch on content it was applied to. It is equivalent to
+// r.Reverse().Apply(patched).
+func (r FileReadResponsePatch) Revert(patched []byte) ([]byte, error) {
+	return r.Reverse().Apply(patched)
+}
+
+// Reverse returns the patch that turns the new file back into the old one.
+func (r FileReadResponsePatch) Reverse() FileReadResponsePatch {
+	rev := FileReadResponsePatch{
+		OldFileName: r.NewFileName,
+		NewFileName: r.OldFileName,
+		OldHeader:   r.NewHeader,
+		NewHeader:   r.OldHeader,
+		Index:       r.Index,
+		Hunks:       make([]FileReadResponsePatchHunk, len(r.Hunks)),
+	}
+	for i, h := range r.Hunks {
+		rh := FileReadResponsePatchHunk{
+			OldStart: h.NewStart,
+			OldLines: h.NewLines,
+			NewStart: h.OldStart,
+			NewLines: h.OldLines,
+			Lines:    make([]string, 0, len(h.Lines)),
+		}
+		// Swap additions and removals, keeping removals first within each
+		// run of changes as diff tools print them. A no-newline marker
+		// stays with the line before it.
+		var removed, added []string
+		flush := func() {
+			rh.Lines = append(rh.Lines, removed...)
+			rh.Lines = append(rh.Lines, added...)
+			removed, added = removed[:0], added[:0]
+		}
+		last := &rh.Lines
+		for _, line := range h.Lines {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				removed = append(removed, "-"+line[1:])
+				last = &removed
+			case strings.HasPrefix(line, "-"):
+				added = append(added, "+"+line[1:])
+				last = &added
+			case strings.HasPrefix(line, `\`):
+				*last = append(*last, line)
+			default:
+				flush()
+				rh.Lines = append(rh.Lines, line)
+				last = &rh.Lines
+			}
+		}
+		flush()
+		rev.Hunks[i] = rh
+	}
+	return rev
+}
+
+// String formats the patch as a unified diff.
+func (r FileReadResponsePatch) String() string {
+	var b strings.Builder
+	writeName := func(prefix, name, header string) {
+		b.WriteString(prefix)
+		b.WriteString(name)
+		if header != "" {
+			b.WriteByte('\t')
+			b.WriteString(header)
+		}
+		b.WriteByte('\n')
+	}
+	writeName("--- ", r.OldFileName, r.OldHeader)
+	writeName("+++ ", r.NewFileName, r.NewHeader)
+	for _, h := range r.Hunks {
+		b.WriteString(h.header())
+		b.WriteByte('\n')
+		for _, line := range h.Lines {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+func (h FileReadResponsePatchHunk) header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", patchRange(h.OldStart, h.OldLines), patchRange(h.NewStart, h.NewLines))
+}
+
+func patchRange(start, lines int64) string {
+	if lines == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+type patchLine struct {
+	kind byte
+	// text is the line including its newline, unless it is the last line
+	// of a file that does not end in one.
+	text string
+}
+
+// parse decodes the hunk's lines and checks them against its line counts.
+func (h FileReadResponsePatchHunk) parse() ([]patchLine, error) {
+	lines := make([]patchLine, 0, len(h.Lines))
+	var oldLines, newLines int64
+	for _, raw := range h.Lines {
+		if strings.HasPrefix(raw, `\`) {
+			if len(lines) == 0 {
+				return nil, fmt.Errorf("%q does not follow a line", raw)
+			}
+			prev := &lines[len(lines)-1]
+			prev.text = strings.TrimSuffix(prev.text, "\n")
+			continue
+		}
+		kind := byte(' ')
+		if raw != "" {
+			kind = raw[0]
+		}
+		switch kind {
+		case ' ':
+			oldLines++
+			newLines++
+		case '-':
+			oldLines++
+		case '+':
+			newLines++
+		default:
+			return nil, fmt.Errorf("unexpected line %q", raw)
+		}
+		// An empty context line may have lost its leading space.
+		text := ""
+		if raw != "" {
+			text = raw[1:]
+		}
+		lines = append(lines, patchLine{kind: kind, text: text + "\n"})
+	}
+	if oldLines != h.OldLines || newLines != h.NewLines {
+		return nil, fmt.Errorf("hunk %s has %d old and %d new lines", h.header(), oldLines, newLines)
+	}
+	return lines, nil
+}
+
+// locateHunk finds where hunk applies in lines, searching from index from
+// outward from near. It returns the index of the first old line matched and
+// how many leading and trailing context lines were ignored, or -1.
+func locateHunk(lines []string, hunk []patchLine, from, near int) (at, lead, trail int) {
+	leadContext, trailContext := 0, 0
+	for leadContext < len(hunk) && hunk[leadContext].kind == ' ' {
+		leadContext++
+	}
+	for trailContext < len(hunk)-leadContext && hunk[len(hunk)-1-trailContext].kind == ' ' {
+		trailContext++
+	}
+
+	for _, equal := range []func(a, b string) bool{textlines.Exact, looseLineEqual} {
+		for fuzz := 0; fuzz <= maxPatchFuzz; fuzz++ {
+			lead, trail = min(fuzz, leadContext), min(fuzz, trailContext)
+			if fuzz > 0 && lead < fuzz && trail < fuzz {
+				// No context left to ignore.
+				break
+			}
+			var old []string
+			for _, line := range hunk[lead : len(hunk)-trail] {
+				if line.kind != '+' {
+					old = append(old, line.text)
+				}
+			}
+			if len(old) == 0 && (lead > 0 || trail > 0) {
+				// Ignoring all context would let the hunk apply anywhere.
+				break
+			}
+			if at = textlines.Find(lines, old, from, near+lead, equal); at >= 0 {
+				return at, lead, trail
+			}
+		}
+	}
+	return -1, 0, 0
+}
+
+// looseLineEqual compares lines ignoring differences in whitespace, including
+// a missing final newline.
+func looseLineEqual(a, b string) bool {
+	return strings.Join(strings.Fields(a), " ") == strings.Join(strings.Fields(b), " ")
+}
@@ -0,0 +1,187 @@
+package opencode_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dominicnunez/opencode-sdk-go"
+)
+
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// parseGitPatch converts the output of git diff for a single file into the
+// structure File.Read returns.
+func parseGitPatch(t *testing.T, text string) opencode.FileReadResponsePatch {
+	t.Helper()
+	var patch opencode.FileReadResponsePatch
+	atoi := func(s string, fallback int64) int64 {
+		if s == "" {
+			return fallback
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			t.Fatalf("parse %q: %v", s, err)
+		}
+		return n
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			patch.OldFileName = strings.TrimPrefix(line, "--- ")
+		case strings.HasPrefix(line, "+++ "):
+			patch.NewFileName = strings.TrimPrefix(line, "+++ ")
+		case strings.HasPrefix(line, "@@"):
+			m := hunkHeaderPattern.FindStringSubmatch(line)
+			if m == nil {
+				t.Fatalf("bad hunk header %q", line)
+			}
+			patch.Hunks = append(patch.Hunks, opencode.FileReadResponsePatchHunk{
+				OldStart: atoi(m[1], 0),
+				OldLines: atoi(m[2], 1),
+				NewStart: atoi(m[3], 0),
+				NewLines: atoi(m[4], 1),
+			})
+		case len(patch.Hunks) > 0:
+			h := &patch.Hunks[len(patch.Hunks)-1]
+			h.Lines = append(h.Lines, line)
+		}
+	}
+	return patch
+}
+
+func readPatchFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "patch", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestFileReadResponsePatch_GitFixtures(t *testing.T) {
+	sectionHeading := regexp.MustCompile(`(?m)^(@@ [^@]* @@).*$`)
+
+	for _, name := range []string{"modify", "noeol", "create", "delete"} {
+		t.Run(name, func(t *testing.T) {
+			before := readPatchFixture(t, name+".before")
+			after := readPatchFixture(t, name+".after")
+			text := string(readPatchFixture(t, name+".diff"))
+			patch := parseGitPatch(t, text)
+
+			got, err := patch.Apply(before)
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if string(got) != string(after) {
+				t.Errorf("Apply =\n%q\nwant\n%q", got, after)
+			}
+
+			got, err = patch.Revert(after)
+			if err != nil {
+				t.Fatalf("Revert: %v", err)
+			}
+			if string(got) != string(before) {
+				t.Errorf("Revert =\n%q\nwant\n%q", got, before)
+			}
+
+			// String reproduces git's output from the file names on,
+			// without the optional section headings after each @@.
+			want := text[strings.Index(text, "--- "):]
+			want = sectionHeading.ReplaceAllString(want, "$1")
+			if got := patch.String(); got != want {
+				t.Errorf("String =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestFileReadResponsePatch_ApplyWithOffsetAndFuzz(t *testing.T) {
+	patch := parseGitPatch(t, string(readPatchFixture(t, "modify.diff")))
+	before := string(readPatchFixture(t, "modify.before"))
+	after := string(readPatchFixture(t, "modify.after"))
+
+	// The local copy has a new header and an edited context line near the
+	// second hunk.
+	local := "// header\n// header\n" + strings.Replace(before, "line 18\n", "line eighteen\n", 1)
+	want := "// header\n// header\n" + strings.Replace(after, "line 18\n", "line eighteen\n", 1)
+
+	got, err := patch.Apply([]byte(local))
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Apply =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFileReadResponsePatch_ApplyIgnoresWhitespace(t *testing.T) {
+	patch := opencode.FileReadResponsePatch{
+		OldFileName: "main.go",
+		NewFileName: "main.go",
+		Hunks: []opencode.FileReadResponsePatchHunk{{
+			OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3,
+			Lines: []string{" func main() {", `-    println("a")`, `+    println("b")`, " }"},
+		}},
+	}
+	got, err := patch.Apply([]byte("func main() {\n\tprintln(\"a\")\n}\n"))
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if want := "func main() {\n    println(\"b\")\n}\n"; string(got) != want {
+		t.Errorf("Apply = %q, want %q", got, want)
+	}
+}
+
+func TestFileReadResponsePatch_Conflict(t *testing.T) {
+	patch := parseGitPatch(t, string(readPatchFixture(t, "modify.diff")))
+	local := strings.Replace(string(readPatchFixture(t, "modify.before")), "line 35\n", "line 35 edited locally\n", 1)
+
+	_, err := patch.Apply([]byte(local))
+	if !errors.Is(err, opencode.ErrPatchConflict) {
+		t.Fatalf("err = %v, want ErrPatchConflict", err)
+	}
+	if !strings.Contains(err.Error(), "hunk 3") {
+		t.Errorf("error %q does not name hunk 3", err)
+	}
+
+	bad := opencode.FileReadResponsePatch{Hunks: []opencode.FileReadResponsePatchHunk{{
+		OldStart: 1, OldLines: 2, NewStart: 1, NewLines: 1, Lines: []string{"-a"},
+	}}}
+	if _, err := bad.Apply([]byte("a\n")); err == nil || errors.Is(err, opencode.ErrPatchConflict) {
+		t.Errorf("inconsistent hunk err = %v, want invalid patch error", err)
+	}
+}
+
+func TestFileReadResponsePatch_FromJSON(t *testing.T) {
+	// The server builds patches with jsdiff's structuredPatch.
+	raw := `{"type":"text","content":"a\nB\nc\n","patch":{
+		"oldFileName":"f.txt","newFileName":"f.txt","oldHeader":"old","newHeader":"new",
+		"hunks":[{"oldStart":1,"oldLines":3,"newStart":1,"newLines":3,"lines":[" a","-b","+B"," c"]}]}}`
+	var resp opencode.FileReadResponse
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := resp.Patch.Apply([]byte("a\nb\nc\n"))
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if string(got) != resp.Content {
+		t.Errorf("Apply = %q, want %q", got, resp.Content)
+	}
+
+	want := "--- f.txt\told\n+++ f.txt\tnew\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"
+	if s := resp.Patch.String(); s != want {
+		t.Errorf("String = %q, want %q", s, want)
+	}
+	wantReverse := "--- f.txt\tnew\n+++ f.txt\told\n@@ -1,3 +1,3 @@\n a\n-B\n+b\n c\n"
+	if s := resp.Patch.Reverse().String(); s != wantReverse {
+		t.Errorf("Reverse().String = %q, want %q", s, wantReverse)
+	}
+}
@@ -0,0 +1,22 @@
+// Package mstime converts the millisecond Unix timestamps used by the API.
+package mstime
+
+import "time"
+
+// Time returns the UTC time ms milliseconds after the Unix epoch. Zero and
+// negative values mean the time is unset and return the zero time.
+func Time(ms float64) time.Time {
+	if ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(ms)).UTC()
+}
+
+// Ptr is like Time but returns nil for an unset time.
+func Ptr(ms float64) *time.Time {
+	if ms <= 0 {
+		return nil
+	}
+	t := Time(ms)
+	return &t
+}
@@ -0,0 +1,24 @@
+package mstime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTime(t *testing.T) {
+	want := time.Date(2025, 1, 2, 3, 4, 5, 6e6, time.UTC)
+	if got := Time(float64(want.UnixMilli())); !got.Equal(want) || got.Location() != time.UTC {
+		t.Errorf("Time = %v, want %v", got, want)
+	}
+	for _, ms := range []float64{0, -1} {
+		if got := Time(ms); !got.IsZero() {
+			t.Errorf("Time(%v) = %v, want zero", ms, got)
+		}
+		if got := Ptr(ms); got != nil {
+			t.Errorf("Ptr(%v) = %v, want nil", ms, got)
+		}
+	}
+	if got := Ptr(1); got == nil || got.UnixMilli() != 1 {
+		t.Errorf("Ptr(1) = %v", got)
+	}
+}
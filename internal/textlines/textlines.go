@@ -0,0 +1,50 @@
+// Package textlines splits text into lines and locates blocks of lines, for
+// the patch code in the root package and packages/diff.
+package textlines
+
+import "strings"
+
+// Split splits s after each newline. A final line without a newline is kept,
+// so "x" and "x\n" compare as different lines.
+func Split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Exact reports whether a and b are the same line.
+func Exact(a, b string) bool {
+	return a == b
+}
+
+// Find returns the index at or after from where block occurs in lines,
+// comparing lines with equal and preferring the occurrence closest to near,
+// or -1.
+func Find(lines, block []string, from, near int, equal func(a, b string) bool) int {
+	matches := func(at int) bool {
+		if at < from || at+len(block) > len(lines) {
+			return false
+		}
+		for j, line := range block {
+			if !equal(lines[at+j], line) {
+				return false
+			}
+		}
+		return true
+	}
+	near = min(max(near, from), len(lines))
+	for delta := 0; near-delta >= from || near+delta <= len(lines); delta++ {
+		if matches(near - delta) {
+			return near - delta
+		}
+		if matches(near + delta) {
+			return near + delta
+		}
+	}
+	return -1
+}
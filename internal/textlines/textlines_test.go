@@ -0,0 +1,39 @@
+package textlines
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	if got := Split(""); got != nil {
+		t.Errorf("Split(\"\") = %q", got)
+	}
+	if got := Split("a\nb"); !reflect.DeepEqual(got, []string{"a\n", "b"}) {
+		t.Errorf("Split = %q", got)
+	}
+	if got := Split("a\n\n"); !reflect.DeepEqual(got, []string{"a\n", "\n"}) {
+		t.Errorf("Split = %q", got)
+	}
+}
+
+func TestFind(t *testing.T) {
+	lines := []string{"x", "a", "b", "x", "a", "b", "x"}
+	block := []string{"a", "b"}
+	for _, tt := range []struct{ from, near, want int }{
+		{0, 0, 1},
+		{0, 5, 4},
+		{2, 0, 4},
+		{5, 5, -1},
+		{0, 100, 4},
+	} {
+		if got := Find(lines, block, tt.from, tt.near, Exact); got != tt.want {
+			t.Errorf("Find(from=%d, near=%d) = %d, want %d", tt.from, tt.near, got, tt.want)
+		}
+	}
+	fold := func(a, b string) bool { return strings.EqualFold(a, b) }
+	if got := Find(lines, []string{"A", "B"}, 0, 0, fold); got != 1 {
+		t.Errorf("Find with custom equal = %d, want 1", got)
+	}
+}
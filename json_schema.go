@@ -0,0 +1,241 @@
+package opencode
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// jsonSchema is the subset of JSON Schema that schemaFor derives from Go
+// types.
+type jsonSchema struct {
+	Type        string
+	Format      string
+	Description string
+	// Properties are in field order, which is kept when encoding.
+	Properties []schemaProperty
+	Required   []string
+	// Additional is the schema of a map's values. Objects derived from
+	// structs allow no additional properties.
+	Additional *jsonSchema
+	Items      *jsonSchema
+}
+
+type schemaProperty struct {
+	Name   string
+	Schema *jsonSchema
+}
+
+func (s *jsonSchema) MarshalJSON() ([]byte, error) {
+	var fields []string
+	add := func(name string, v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, fmt.Sprintf("%q:%s", name, data))
+		return nil
+	}
+	if s.Type != "" {
+		_ = add("type", s.Type)
+	}
+	if s.Format != "" {
+		_ = add("format", s.Format)
+	}
+	if s.Description != "" {
+		_ = add("description", s.Description)
+	}
+	if s.Type == "object" && s.Additional == nil {
+		if len(s.Properties) > 0 {
+			props := make([]string, 0, len(s.Properties))
+			for _, p := range s.Properties {
+				data, err := json.Marshal(p.Schema)
+				if err != nil {
+					return nil, err
+				}
+				props = append(props, fmt.Sprintf("%q:%s", p.Name, data))
+			}
+			fields = append(fields, `"properties":{`+strings.Join(props, ",")+"}")
+		}
+		if len(s.Required) > 0 {
+			_ = add("required", s.Required)
+		}
+		_ = add("additionalProperties", false)
+	}
+	if s.Additional != nil {
+		if err := add("additionalProperties", s.Additional); err != nil {
+			return nil, err
+		}
+	}
+	if s.Items != nil {
+		if err := add("items", s.Items); err != nil {
+			return nil, err
+		}
+	}
+	return []byte("{" + strings.Join(fields, ",") + "}"), nil
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage(nil))
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemaFor derives the JSON Schema of the values encoding/json produces for
+// t. Struct fields are required unless they are pointers or tagged
+// omitempty, and a field's `description` tag becomes its description. Types
+// with custom JSON encoding, interfaces and recursive references accept any
+// value.
+func schemaFor(t reflect.Type) *jsonSchema {
+	return schemaOf(t, map[reflect.Type]bool{})
+}
+
+func schemaOf(t reflect.Type, visiting map[reflect.Type]bool) *jsonSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &jsonSchema{Type: "string", Format: "date-time"}
+	case t == rawMessageType, t.Implements(jsonMarshalerType), reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &jsonSchema{}
+	case t.Implements(textMarshalerType), reflect.PointerTo(t).Implements(textMarshalerType):
+		return &jsonSchema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json writes []byte as base64.
+			return &jsonSchema{Type: "string"}
+		}
+		return &jsonSchema{Type: "array", Items: schemaOf(t.Elem(), visiting)}
+	case reflect.Map:
+		// encoding/json writes every supported key type as a string.
+		return &jsonSchema{Type: "object", Additional: schemaOf(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return &jsonSchema{}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		s := &jsonSchema{Type: "object"}
+		addFields(s, t, visiting)
+		return s
+	}
+	return &jsonSchema{}
+}
+
+// addFields adds the encoded fields of struct type t to s, promoting the
+// fields of untagged embedded structs as encoding/json does.
+func addFields(s *jsonSchema, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			if !visiting[ft] {
+				visiting[ft] = true
+				addFields(s, ft, visiting)
+				delete(visiting, ft)
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := schemaOf(f.Type, visiting)
+		if hasTagOption(opts, "string") {
+			prop = &jsonSchema{Type: "string"}
+		}
+		prop.Description = f.Tag.Get("description")
+		s.Properties = append(s.Properties, schemaProperty{Name: name, Schema: prop})
+		if f.Type.Kind() != reflect.Pointer && !hasTagOption(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks that v, as decoded into an interface value, has the
+// required properties of s, reporting the first one missing by its path.
+// Types are checked by decoding into the target type, so they are not
+// checked here.
+func (s *jsonSchema) validate(v any, path string) error {
+	switch v := v.(type) {
+	case map[string]any:
+		if s.Type != "object" {
+			return nil
+		}
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("missing required property %q", joinPath(path, name))
+			}
+		}
+		for _, p := range s.Properties {
+			if value, ok := v[p.Name]; ok && value != nil {
+				if err := p.Schema.validate(value, joinPath(path, p.Name)); err != nil {
+					return err
+				}
+			}
+		}
+		if s.Additional != nil {
+			for name, value := range v {
+				if err := s.Additional.validate(value, joinPath(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		if s.Items == nil {
+			return nil
+		}
+		for i, item := range v {
+			if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,82 @@
+package opencode
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type schemaBase struct {
+	ID string `json:"id"`
+}
+
+type schemaNode struct {
+	schemaBase
+	Name     string            `json:"name" description:"display name"`
+	Size     int64             `json:"size,string"`
+	Weight   float64           `json:"weight,omitempty"`
+	Tags     map[string]bool   `json:"tags"`
+	Created  time.Time         `json:"created"`
+	Parent   *schemaNode       `json:"parent"`
+	Children []schemaNode      `json:"children"`
+	Extra    json.RawMessage   `json:"extra"`
+	Labels   map[int]string    `json:"labels,omitempty"`
+	Hidden   string            `json:"-"`
+	Untagged bool              // named after the field
+	internal string            //nolint:unused // unexported fields are not encoded
+	Data     []byte            `json:"data"`
+	Nested   map[string][]bool `json:"nested,omitempty"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	data, err := json.Marshal(schemaFor(reflect.TypeOf(schemaNode{})))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	want := `{"type":"object","properties":{` +
+		`"id":{"type":"string"},` +
+		`"name":{"type":"string","description":"display name"},` +
+		`"size":{"type":"string"},` +
+		`"weight":{"type":"number"},` +
+		`"tags":{"type":"object","additionalProperties":{"type":"boolean"}},` +
+		`"created":{"type":"string","format":"date-time"},` +
+		`"parent":{},` +
+		`"children":{"type":"array","items":{}},` +
+		`"extra":{},` +
+		`"labels":{"type":"object","additionalProperties":{"type":"string"}},` +
+		`"Untagged":{"type":"boolean"},` +
+		`"data":{"type":"string"},` +
+		`"nested":{"type":"object","additionalProperties":{"type":"array","items":{"type":"boolean"}}}},` +
+		`"required":["id","name","size","tags","created","children","extra","Untagged","data"],` +
+		`"additionalProperties":false}`
+	if string(data) != want {
+		t.Errorf("schema =\n%s\nwant\n%s", data, want)
+	}
+
+	if data, _ := json.Marshal(schemaFor(reflect.TypeOf([]*int{}))); string(data) != `{"type":"array","items":{"type":"integer"}}` {
+		t.Errorf("slice schema = %s", data)
+	}
+}
+
+func TestJSONSchema_Validate(t *testing.T) {
+	schema := schemaFor(reflect.TypeOf(struct {
+		Items []struct {
+			Name string `json:"name"`
+		} `json:"items"`
+		Note string `json:"note,omitempty"`
+	}{}))
+
+	var v any
+	_ = json.Unmarshal([]byte(`{"items":[{"name":"a"},{}]}`), &v)
+	err := schema.validate(v, "")
+	if err == nil || !strings.Contains(err.Error(), `"items[1].name"`) {
+		t.Errorf("validate = %v, want missing items[1].name", err)
+	}
+
+	_ = json.Unmarshal([]byte(`{"items":[{"name":"a"}]}`), &v)
+	if err := schema.validate(v, ""); err != nil {
+		t.Errorf("validate = %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+package opencode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// MessageDecoder reads a JSON array of session messages one element at a
+// time, so a long session history never has to be held in memory as a whole.
+// Use Session.MessagesStreaming to decode a server response or
+// NewMessageDecoder for any other reader:
+//
+//	messages := client.Session.MessagesStreaming(ctx, sessionID, nil)
+//	defer messages.Close()
+//	for messages.Next() {
+//	    msg := messages.Current()
+//	    // handle msg
+//	}
+//	if err := messages.Err(); err != nil {
+//	    // handle error
+//	}
+type MessageDecoder struct {
+	body   io.Closer
+	cancel context.CancelFunc
+	window *windowReader
+	dec    *json.Decoder
+	limit  int64
+	// after, when set, skips messages whose ID does not sort after it.
+	after string
+
+	index   int
+	started bool
+	done    bool
+	cur     SessionMessagesResponse
+	err     error
+}
+
+// NewMessageDecoder returns a decoder over the JSON array of messages read
+// from r. A positive maxMessageSize bounds the encoded size of each message
+// rather than the array as a whole; zero disables the bound. If r is also an
+// io.Closer, Close closes it.
+func NewMessageDecoder(r io.Reader, maxMessageSize int64) *MessageDecoder {
+	d := &MessageDecoder{limit: maxMessageSize}
+	if closer, ok := r.(io.Closer); ok {
+		d.body = closer
+	}
+	if maxMessageSize > 0 {
+		d.window = &windowReader{reader: r}
+		r = d.window
+	}
+	d.dec = json.NewDecoder(r)
+	return d
+}
+
+// MessagesStreaming fetches the messages of a session and decodes them one
+// at a time as the response body arrives. Unlike Messages, the client's
+// success body size limit applies to each message instead of the whole
+// response. The returned decoder is never nil; request errors are reported
+// by Err. Callers must Close the decoder to release the response body.
+func (s *SessionService) MessagesStreaming(ctx context.Context, id string, params *SessionMessagesParams) *MessageDecoder {
+	if params == nil {
+		params = &SessionMessagesParams{}
+	}
+	return s.messagesStreaming(ctx, id, params)
+}
+
+func (s *SessionService) messagesStreaming(ctx context.Context, id string, params interface{}) *MessageDecoder {
+	if ctx == nil {
+		return &MessageDecoder{err: ErrContextRequired}
+	}
+	if strings.TrimSpace(id) == "" {
+		return &MessageDecoder{err: missingRequiredParameterError("id")}
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if _, ok := ctx.Deadline(); !ok {
+		ctx, cancel = context.WithTimeout(ctx, s.client.timeout)
+	}
+
+	resp, err := s.client.doRaw(ctx, http.MethodGet, "session/"+url.PathEscape(id)+"/message", params)
+	if err != nil {
+		cancel()
+		return &MessageDecoder{err: err}
+	}
+
+	d := NewMessageDecoder(resp.Body, s.client.maxSuccessBodySize)
+	d.cancel = cancel
+	return d
+}
+
+// Next advances to the next message, returning false at the end of the array
+// or on error.
+func (d *MessageDecoder) Next() bool {
+	if d.err != nil || d.done {
+		return false
+	}
+	d.window.reset(d.limit)
+
+	if !d.started {
+		tok, err := d.dec.Token()
+		if err != nil {
+			d.fail(err)
+			return false
+		}
+		if tok == nil {
+			return d.finish()
+		}
+		if tok != json.Delim('[') {
+			d.err = fmt.Errorf("decode messages: expected array, got %v", tok)
+			return false
+		}
+		d.started = true
+	}
+
+	for {
+		if !d.dec.More() {
+			if _, err := d.dec.Token(); err != nil {
+				d.fail(err)
+				return false
+			}
+			return d.finish()
+		}
+
+		start := d.dec.InputOffset()
+		var msg SessionMessagesResponse
+		if err := d.dec.Decode(&msg); err != nil {
+			d.fail(err)
+			return false
+		}
+		if d.limit > 0 && d.dec.InputOffset()-start > d.limit {
+			d.err = d.limitError()
+			return false
+		}
+		d.index++
+		if d.after != "" && msg.Info.ID <= d.after {
+			d.window.reset(d.limit)
+			continue
+		}
+
+		d.cur = msg
+		return true
+	}
+}
+
+// Current returns the message decoded by the last successful call to Next.
+func (d *MessageDecoder) Current() SessionMessagesResponse {
+	return d.cur
+}
+
+// Err returns the first error encountered, or nil if the array was decoded
+// to completion.
+func (d *MessageDecoder) Err() error {
+	return d.err
+}
+
+// Close releases the underlying response body. It is safe to call more than
+// once.
+func (d *MessageDecoder) Close() error {
+	var err error
+	if d.body != nil {
+		err = d.body.Close()
+		d.body = nil
+	}
+	if d.cancel != nil {
+		d.cancel()
+		d.cancel = nil
+	}
+	return err
+}
+
+// finish checks that nothing but whitespace follows the array and ends
+// iteration.
+func (d *MessageDecoder) finish() bool {
+	d.done = true
+	if _, err := d.dec.Token(); !errors.Is(err, io.EOF) {
+		if err == nil {
+			d.err = errors.New("decode messages: unexpected trailing JSON value")
+			return false
+		}
+		d.fail(err)
+	}
+	return false
+}
+
+func (d *MessageDecoder) fail(err error) {
+	if d.window.exhausted() {
+		d.err = d.limitError()
+		return
+	}
+	d.err = fmt.Errorf("decode message %d: %w", d.index, err)
+}
+
+func (d *MessageDecoder) limitError() error {
+	return fmt.Errorf("decode message %d: message exceeds %d bytes limit", d.index, d.limit)
+}
+
+// windowReader allows at most n bytes to be read between resets, bounding
+// how much of the body a single json.Decoder value can pull in.
+type windowReader struct {
+	reader io.Reader
+	n      int64
+	hit    bool
+}
+
+func (w *windowReader) reset(limit int64) {
+	if w == nil {
+		return
+	}
+	// One byte past the limit lets an oversized value be told apart from a
+	// truncated body.
+	w.n = limit + 1
+	w.hit = false
+}
+
+func (w *windowReader) exhausted() bool {
+	return w != nil && w.hit
+}
+
+func (w *windowReader) Read(p []byte) (int, error) {
+	if w.n <= 0 {
+		w.hit = true
+		return 0, io.EOF
+	}
+	if int64(len(p)) > w.n {
+		p = p[:w.n]
+	}
+	n, err := w.reader.Read(p)
+	w.n -= int64(n)
+	return n, err
+}
@@ -0,0 +1,154 @@
+package opencode
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMessageDecoder_DecodesEachMessage(t *testing.T) {
+	body := `[
+		{"info":{"id":"msg_1","role":"user","sessionID":"ses_1"},"parts":[]},
+		{"info":{"id":"msg_2","role":"assistant","sessionID":"ses_1"},"parts":[{"id":"prt_1","type":"text","text":"hi","messageID":"msg_2","sessionID":"ses_1"}]}
+	]`
+	d := NewMessageDecoder(strings.NewReader(body), 0)
+	defer func() { _ = d.Close() }()
+
+	var ids []string
+	for d.Next() {
+		ids = append(ids, d.Current().Info.ID)
+	}
+	if err := d.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(ids, ",") != "msg_1,msg_2" {
+		t.Fatalf("expected msg_1,msg_2, got %v", ids)
+	}
+}
+
+func TestMessageDecoder_EmptyAndNull(t *testing.T) {
+	for _, body := range []string{`[]`, `null`, " [ ] \n"} {
+		d := NewMessageDecoder(strings.NewReader(body), 0)
+		if d.Next() {
+			t.Errorf("%q: expected no messages", body)
+		}
+		if err := d.Err(); err != nil {
+			t.Errorf("%q: unexpected error: %v", body, err)
+		}
+	}
+}
+
+func TestMessageDecoder_RejectsMalformedInput(t *testing.T) {
+	tests := map[string]string{
+		"object":    `{"info":{}}`,
+		"truncated": `[{"info":{"id":"msg_1","role":"user"}},`,
+		"trailing":  `[] []`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := NewMessageDecoder(strings.NewReader(body), 0)
+			for d.Next() {
+			}
+			if d.Err() == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestMessageDecoder_LimitAppliesPerMessage(t *testing.T) {
+	msg := `{"info":{"id":"msg_1","role":"user","sessionID":"ses_1"},"parts":[]}`
+	body := "[" + strings.Repeat(msg+",", 49) + msg + "]"
+
+	d := NewMessageDecoder(strings.NewReader(body), int64(len(msg)+8))
+	count := 0
+	for d.Next() {
+		count++
+	}
+	if err := d.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 50 {
+		t.Fatalf("expected 50 messages, got %d", count)
+	}
+
+	big := `{"info":{"id":"msg_2","role":"user","sessionID":"ses_1"},"parts":[{"type":"text","text":"` + strings.Repeat("x", 4096) + `"}]}`
+	d = NewMessageDecoder(strings.NewReader("["+msg+","+big+"]"), int64(len(msg)+8))
+	if !d.Next() {
+		t.Fatalf("expected first message, got error %v", d.Err())
+	}
+	if d.Next() {
+		t.Fatal("expected oversized message to stop iteration")
+	}
+	if err := d.Err(); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Fatalf("expected size limit error, got %v", err)
+	}
+}
+
+func TestSessionMessagesStreaming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/session/ses_1/message" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("directory"); got != "/repo" {
+			t.Errorf("expected directory /repo, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"info":{"id":"msg_1","role":"user","sessionID":"ses_1"},"parts":[]}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	messages := client.Session.MessagesStreaming(context.Background(), "ses_1", &SessionMessagesParams{Directory: Ptr("/repo")})
+	defer func() { _ = messages.Close() }()
+	if !messages.Next() {
+		t.Fatalf("expected a message, got error %v", messages.Err())
+	}
+	if messages.Current().Info.ID != "msg_1" {
+		t.Errorf("expected msg_1, got %s", messages.Current().Info.ID)
+	}
+	if messages.Next() {
+		t.Error("expected end of messages")
+	}
+	if err := messages.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSessionMessagesStreaming_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"no such session"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	messages := client.Session.MessagesStreaming(context.Background(), "ses_1", nil)
+	if messages.Next() {
+		t.Fatal("expected no messages")
+	}
+	if !errors.Is(messages.Err(), ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", messages.Err())
+	}
+	_ = messages.Close()
+
+	messages = client.Session.MessagesStreaming(context.Background(), " ", nil)
+	if !errors.Is(messages.Err(), &MissingRequiredParameterError{Parameter: "id"}) {
+		t.Errorf("expected missing id error, got %v", messages.Err())
+	}
+	//nolint:staticcheck // Intentional nil context regression test.
+	messages = client.Session.MessagesStreaming(nil, "ses_1", nil)
+	if !errors.Is(messages.Err(), ErrContextRequired) {
+		t.Errorf("expected ErrContextRequired, got %v", messages.Err())
+	}
+}
@@ -0,0 +1,187 @@
+package opencode
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/dominicnunez/opencode-sdk-go/internal/queryparams"
+)
+
+// SessionMessagesAfterParams selects the messages of a session that follow a
+// given message.
+type SessionMessagesAfterParams struct {
+	Directory *string `json:"-" query:"directory,omitempty"`
+	// After is the ID of the last message already seen. Only messages with
+	// a greater ID are returned; an empty After returns every message.
+	After string `json:"-" query:"after,omitempty"`
+}
+
+// URLQuery serializes [SessionMessagesAfterParams]'s query parameters as
+// `url.Values`.
+func (r SessionMessagesAfterParams) URLQuery() (url.Values, error) {
+	return queryparams.Marshal(r)
+}
+
+// MessagesAfter streams the messages of a session whose ID sorts after
+// params.After. Message IDs are assigned in ascending order, so this is
+// everything created since that message. The after query parameter lets a
+// server that supports it skip the older history; otherwise the full list is
+// streamed and filtered client side, one message at a time. Callers must
+// Close the returned decoder.
+func (s *SessionService) MessagesAfter(ctx context.Context, id string, params *SessionMessagesAfterParams) *MessageDecoder {
+	if params == nil {
+		params = &SessionMessagesAfterParams{}
+	}
+	d := s.messagesStreaming(ctx, id, params)
+	d.after = params.After
+	return d
+}
+
+// MessageIteratorParams configures Session.NewMessageIterator.
+type MessageIteratorParams struct {
+	Directory *string
+	// After skips the history up to and including this message ID. The
+	// iterator otherwise starts from the first message of the session.
+	After string
+}
+
+// MessageChanges lists what changed in a session's messages between two
+// calls to MessageIterator.Next, in message order.
+type MessageChanges struct {
+	Added   []SessionMessagesResponse
+	Updated []SessionMessagesResponse
+	// Removed holds the IDs of messages after the iterator's cursor that no
+	// longer exist, such as those dropped by a revert.
+	Removed []string
+}
+
+// Empty reports whether nothing changed.
+func (c MessageChanges) Empty() bool {
+	return len(c.Added) == 0 && len(c.Updated) == 0 && len(c.Removed) == 0
+}
+
+// MessageIterator polls a session for new and changed messages. Each call to
+// Next fetches only the messages after the newest one known to be final and
+// diffs them against a cached snapshot, so a long session is never
+// re-downloaded in full. A MessageIterator is not safe for concurrent use.
+type MessageIterator struct {
+	session   *SessionService
+	sessionID string
+	directory *string
+
+	// cursor is the ID of the newest message such that it and every
+	// message before it are final. Messages after it may still change.
+	cursor   string
+	messages []SessionMessagesResponse
+	sums     map[string][sha256.Size]byte
+}
+
+// NewMessageIterator returns an iterator over the messages of session id.
+// No request is made until the first call to Next.
+func (s *SessionService) NewMessageIterator(id string, params *MessageIteratorParams) (*MessageIterator, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, missingRequiredParameterError("id")
+	}
+	if params == nil {
+		params = &MessageIteratorParams{}
+	}
+	return &MessageIterator{
+		session:   s,
+		sessionID: id,
+		directory: params.Directory,
+		cursor:    params.After,
+		sums:      make(map[string][sha256.Size]byte),
+	}, nil
+}
+
+// Next fetches the session's recent messages and reports what changed since
+// the previous call. The first call reports every message after the starting
+// point as added.
+func (it *MessageIterator) Next(ctx context.Context) (MessageChanges, error) {
+	messages := it.session.MessagesAfter(ctx, it.sessionID, &SessionMessagesAfterParams{
+		Directory: it.directory,
+		After:     it.cursor,
+	})
+	defer func() { _ = messages.Close() }()
+
+	var changes MessageChanges
+	var next []SessionMessagesResponse
+	sums := make(map[string][sha256.Size]byte, len(it.messages))
+	for messages.Next() {
+		msg := messages.Current()
+		sum, err := messageChecksum(msg)
+		if err != nil {
+			return MessageChanges{}, err
+		}
+		id := msg.Info.ID
+		sums[id] = sum
+		next = append(next, msg)
+
+		prev, ok := it.sums[id]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, msg)
+		case prev != sum:
+			changes.Updated = append(changes.Updated, msg)
+		}
+	}
+	if err := messages.Err(); err != nil {
+		return MessageChanges{}, err
+	}
+
+	for _, msg := range it.messages {
+		if _, ok := sums[msg.Info.ID]; !ok {
+			changes.Removed = append(changes.Removed, msg.Info.ID)
+		}
+	}
+
+	it.messages = next
+	it.sums = sums
+	it.advance()
+	return changes, nil
+}
+
+// Messages returns the cached messages that may still change: everything
+// after Cursor as of the last call to Next.
+func (it *MessageIterator) Messages() []SessionMessagesResponse {
+	return append([]SessionMessagesResponse(nil), it.messages...)
+}
+
+// Cursor returns the ID of the newest message known to be final. The next
+// call to Next only fetches messages after it.
+func (it *MessageIterator) Cursor() string {
+	return it.cursor
+}
+
+// advance moves the cursor past the leading run of final messages and drops
+// them from the cache; they will not be fetched again.
+func (it *MessageIterator) advance() {
+	n := 0
+	for n < len(it.messages) && messageFinal(it.messages[n].Info) {
+		it.cursor = it.messages[n].Info.ID
+		delete(it.sums, it.cursor)
+		n++
+	}
+	it.messages = append([]SessionMessagesResponse(nil), it.messages[n:]...)
+}
+
+// messageFinal reports whether a message can no longer change: user
+// messages once created, assistant messages once completed or failed.
+func messageFinal(msg Message) bool {
+	if msg.Role == MessageRoleUser {
+		return true
+	}
+	return messageSettled(&msg)
+}
+
+func messageChecksum(msg SessionMessagesResponse) ([sha256.Size]byte, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return [sha256.Size]byte{}, fmt.Errorf("encode message %s: %w", msg.Info.ID, err)
+	}
+	return sha256.Sum256(data), nil
+}
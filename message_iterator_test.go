@@ -0,0 +1,196 @@
+package opencode
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type messageServer struct {
+	mu       sync.Mutex
+	messages []string
+	afters   []string
+	// honorAfter makes the server filter by the after query parameter.
+	honorAfter bool
+}
+
+func (s *messageServer) set(messages ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = messages
+}
+
+func (s *messageServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	after := r.URL.Query().Get("after")
+	s.afters = append(s.afters, after)
+
+	var out []string
+	for _, msg := range s.messages {
+		if s.honorAfter && after != "" && messageIDOf(msg) <= after {
+			continue
+		}
+		out = append(out, msg)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte("[" + strings.Join(out, ",") + "]"))
+}
+
+func messageIDOf(raw string) string {
+	start := strings.Index(raw, `"id":"`) + len(`"id":"`)
+	return raw[start : start+strings.Index(raw[start:], `"`)]
+}
+
+const (
+	iterUser1      = `{"info":{"id":"msg_01","role":"user","sessionID":"ses_1"},"parts":[]}`
+	iterAssistant1 = `{"info":{"id":"msg_02","role":"assistant","sessionID":"ses_1","parentID":"msg_01","time":{"created":1,"completed":2}},"parts":[]}`
+	iterUser2      = `{"info":{"id":"msg_03","role":"user","sessionID":"ses_1"},"parts":[]}`
+	iterPending    = `{"info":{"id":"msg_04","role":"assistant","sessionID":"ses_1","parentID":"msg_03","time":{"created":3}},"parts":[{"id":"prt_1","type":"text","text":"wor","messageID":"msg_04","sessionID":"ses_1"}]}`
+	iterGrown      = `{"info":{"id":"msg_04","role":"assistant","sessionID":"ses_1","parentID":"msg_03","time":{"created":3}},"parts":[{"id":"prt_1","type":"text","text":"working","messageID":"msg_04","sessionID":"ses_1"}]}`
+	iterDone       = `{"info":{"id":"msg_04","role":"assistant","sessionID":"ses_1","parentID":"msg_03","time":{"created":3,"completed":4}},"parts":[{"id":"prt_1","type":"text","text":"working","messageID":"msg_04","sessionID":"ses_1"}]}`
+)
+
+func changeIDs(messages []SessionMessagesResponse) string {
+	ids := make([]string, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.Info.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestMessageIterator(t *testing.T) {
+	for _, honor := range []bool{true, false} {
+		name := "client filter"
+		if honor {
+			name = "server filter"
+		}
+		t.Run(name, func(t *testing.T) {
+			srv := &messageServer{honorAfter: honor}
+			srv.set(iterUser1, iterAssistant1, iterUser2, iterPending)
+			server := httptest.NewServer(srv)
+			defer server.Close()
+
+			client, err := NewClient(WithBaseURL(server.URL))
+			if err != nil {
+				t.Fatalf("failed to create client: %v", err)
+			}
+			it, err := client.Session.NewMessageIterator("ses_1", nil)
+			if err != nil {
+				t.Fatalf("NewMessageIterator: %v", err)
+			}
+			ctx := context.Background()
+
+			changes, err := it.Next(ctx)
+			if err != nil {
+				t.Fatalf("Next: %v", err)
+			}
+			if got := changeIDs(changes.Added); got != "msg_01,msg_02,msg_03,msg_04" {
+				t.Errorf("first Next added %s", got)
+			}
+			if it.Cursor() != "msg_03" {
+				t.Errorf("expected cursor msg_03, got %q", it.Cursor())
+			}
+
+			changes, err = it.Next(ctx)
+			if err != nil {
+				t.Fatalf("Next: %v", err)
+			}
+			if !changes.Empty() {
+				t.Errorf("expected no changes, got %+v", changes)
+			}
+
+			srv.set(iterUser1, iterAssistant1, iterUser2, iterGrown)
+			changes, err = it.Next(ctx)
+			if err != nil {
+				t.Fatalf("Next: %v", err)
+			}
+			if len(changes.Added) != 0 || changeIDs(changes.Updated) != "msg_04" {
+				t.Fatalf("expected msg_04 updated, got %+v", changes)
+			}
+			part, err := changes.Updated[0].Parts[0].AsText()
+			if err != nil || part.Text != "working" {
+				t.Errorf("expected updated text, got %+v (%v)", part, err)
+			}
+
+			srv.set(iterUser1, iterAssistant1, iterUser2, iterDone, `{"info":{"id":"msg_05","role":"user","sessionID":"ses_1"},"parts":[]}`)
+			changes, err = it.Next(ctx)
+			if err != nil {
+				t.Fatalf("Next: %v", err)
+			}
+			if changeIDs(changes.Updated) != "msg_04" || changeIDs(changes.Added) != "msg_05" {
+				t.Errorf("unexpected changes %+v", changes)
+			}
+			if it.Cursor() != "msg_05" || len(it.Messages()) != 0 {
+				t.Errorf("expected cursor msg_05 and empty cache, got %q and %d", it.Cursor(), len(it.Messages()))
+			}
+
+			want := []string{"", "msg_03", "msg_03", "msg_03"}
+			if strings.Join(srv.afters, ",") != strings.Join(want, ",") {
+				t.Errorf("expected after params %v, got %v", want, srv.afters)
+			}
+		})
+	}
+}
+
+func TestMessageIterator_ReportsRemoved(t *testing.T) {
+	srv := &messageServer{}
+	srv.set(iterUser2, iterPending)
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	client, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	it, err := client.Session.NewMessageIterator("ses_1", &MessageIteratorParams{After: "msg_02"})
+	if err != nil {
+		t.Fatalf("NewMessageIterator: %v", err)
+	}
+	if _, err := it.Next(context.Background()); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+
+	srv.set(iterUser2)
+	changes, err := it.Next(context.Background())
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if strings.Join(changes.Removed, ",") != "msg_04" {
+		t.Errorf("expected msg_04 removed, got %v", changes.Removed)
+	}
+}
+
+func TestMessageIterator_Errors(t *testing.T) {
+	client, err := NewClient(WithBaseURL("http://localhost"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if _, err := client.Session.NewMessageIterator("", nil); !errors.Is(err, &MissingRequiredParameterError{Parameter: "id"}) {
+		t.Errorf("expected missing id error, got %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"info":`))
+	}))
+	defer server.Close()
+	client, err = NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	it, err := client.Session.NewMessageIterator("ses_1", nil)
+	if err != nil {
+		t.Fatalf("NewMessageIterator: %v", err)
+	}
+	if _, err := it.Next(context.Background()); err == nil {
+		t.Fatal("expected decode error")
+	}
+	if it.Cursor() != "" {
+		t.Errorf("cursor should not move on error, got %q", it.Cursor())
+	}
+}
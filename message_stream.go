@@ -0,0 +1,336 @@
+package opencode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dominicnunez/opencode-sdk-go/packages/ssestream"
+)
+
+// MessageUpdate is one change to a message being assembled from events.
+type MessageUpdate struct {
+	// Part is the part's full state after the update. For text and reasoning
+	// parts its text already includes Delta.
+	Part Part
+	// Delta is the text this update appended to a text or reasoning part, or
+	// "" for other updates.
+	Delta string
+	// Removed reports that Part was deleted from the message.
+	Removed bool
+}
+
+// MessageAssembler rebuilds one message from message.updated and
+// message.part.* events, stitching streamed text deltas onto their parts. It
+// is not safe for concurrent use; MessageStream wraps it for the common case
+// of consuming an event stream.
+type MessageAssembler struct {
+	sessionID string
+	messageID string
+
+	info    *Message
+	parts   []Part
+	indexOf map[string]int
+}
+
+// NewMessageAssembler returns an assembler for the given message. Events for
+// other sessions or messages are ignored.
+func NewMessageAssembler(sessionID, messageID string) *MessageAssembler {
+	return &MessageAssembler{
+		sessionID: sessionID,
+		messageID: messageID,
+		indexOf:   map[string]int{},
+	}
+}
+
+// Apply folds one event into the message. It returns the resulting part
+// update and true when the event changed a part; message.updated events are
+// recorded but return false.
+func (a *MessageAssembler) Apply(event Event) (MessageUpdate, bool) {
+	switch event.Type {
+	case EventTypeMessageUpdated:
+		evt, err := event.AsMessageUpdated()
+		if err != nil || evt.Data.Info.SessionID != a.sessionID || evt.Data.Info.ID != a.messageID {
+			return MessageUpdate{}, false
+		}
+		info := evt.Data.Info
+		a.info = &info
+		return MessageUpdate{}, false
+
+	case EventTypeMessagePartUpdated:
+		evt, err := event.AsMessagePartUpdated()
+		if err != nil {
+			return MessageUpdate{}, false
+		}
+		part := evt.Data.Part
+		if part.SessionID != a.sessionID || part.MessageID != a.messageID {
+			return MessageUpdate{}, false
+		}
+		return a.updatePart(part, evt.Data.Delta), true
+
+	case EventTypeMessagePartRemoved:
+		evt, err := event.AsMessagePartRemoved()
+		if err != nil || evt.Data.SessionID != a.sessionID || evt.Data.MessageID != a.messageID {
+			return MessageUpdate{}, false
+		}
+		i, ok := a.indexOf[evt.Data.PartID]
+		if !ok {
+			return MessageUpdate{}, false
+		}
+		removed := a.parts[i]
+		a.parts = append(a.parts[:i], a.parts[i+1:]...)
+		delete(a.indexOf, removed.ID)
+		for id, j := range a.indexOf {
+			if j > i {
+				a.indexOf[id] = j - 1
+			}
+		}
+		return MessageUpdate{Part: removed, Removed: true}, true
+	}
+	return MessageUpdate{}, false
+}
+
+func (a *MessageAssembler) updatePart(part Part, delta *string) MessageUpdate {
+	i, seen := a.indexOf[part.ID]
+	if delta != nil && (part.Type == PartTypeText || part.Type == PartTypeReasoning) {
+		prev := ""
+		if seen {
+			prev, _ = partText(a.parts[i])
+		}
+		// The server usually sends the accumulated text alongside the delta,
+		// and it is authoritative: prev is stale if an earlier event was
+		// missed or the assembler joined mid-stream. Build the text from the
+		// delta only when the server's is empty or lags behind it.
+		merged := prev + *delta
+		if text, _ := partText(part); text == "" || (len(text) < len(merged) && strings.HasPrefix(merged, text)) {
+			if withText, err := withPartText(part, merged); err == nil {
+				part = withText
+			}
+		}
+	}
+
+	if seen {
+		a.parts[i] = part
+	} else {
+		a.indexOf[part.ID] = len(a.parts)
+		a.parts = append(a.parts, part)
+	}
+
+	update := MessageUpdate{Part: part}
+	if delta != nil {
+		update.Delta = *delta
+	}
+	return update
+}
+
+// Parts returns the message's parts in the order they first arrived.
+func (a *MessageAssembler) Parts() []Part {
+	return append([]Part(nil), a.parts...)
+}
+
+// Text returns the concatenated text of the message's text parts.
+func (a *MessageAssembler) Text() string {
+	var b strings.Builder
+	for _, part := range a.parts {
+		if part.Type != PartTypeText {
+			continue
+		}
+		text, _ := partText(part)
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+// Done reports whether the server has marked the assistant message complete.
+func (a *MessageAssembler) Done() bool {
+	if a.info == nil {
+		return false
+	}
+	assistant, err := a.info.AsAssistant()
+	return err == nil && assistant.Time.Completed > 0
+}
+
+// Result returns the message in the shape Session.Prompt returns. It fails if
+// no message.updated event for an assistant message has been applied yet.
+func (a *MessageAssembler) Result() (SessionPromptResponse, error) {
+	if a.info == nil {
+		return SessionPromptResponse{}, fmt.Errorf("message %s: no message info received", a.messageID)
+	}
+	assistant, err := a.info.AsAssistant()
+	if err != nil {
+		return SessionPromptResponse{}, fmt.Errorf("message %s: %w", a.messageID, err)
+	}
+	return SessionPromptResponse{Info: *assistant, Parts: a.Parts()}, nil
+}
+
+// partText returns the text of a text or reasoning part.
+func partText(part Part) (string, bool) {
+	switch part.Type {
+	case PartTypeText:
+		p, err := part.AsText()
+		if err != nil {
+			return "", false
+		}
+		return p.Text, true
+	case PartTypeReasoning:
+		p, err := part.AsReasoning()
+		if err != nil {
+			return "", false
+		}
+		return p.Text, true
+	}
+	return "", false
+}
+
+// withPartText returns a copy of part with its text field replaced, keeping
+// every other field of the raw payload intact.
+func withPartText(part Part, text string) (Part, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(part.raw, &fields); err != nil {
+		return Part{}, err
+	}
+	encoded, err := json.Marshal(text)
+	if err != nil {
+		return Part{}, err
+	}
+	fields["text"] = encoded
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return Part{}, err
+	}
+	var out Part
+	if err := json.Unmarshal(data, &out); err != nil {
+		return Part{}, err
+	}
+	return out, nil
+}
+
+// MessageStream yields the parts of one assistant message as they stream in.
+// Open the event stream before sending the prompt so no early event is
+// missed:
+//
+//	events := client.Event.ListStreaming(ctx, nil)
+//	msg := opencode.NewMessageStream(events, sessionID, messageID)
+//	defer msg.Close()
+//	for msg.Next() {
+//		fmt.Print(msg.Current().Delta)
+//	}
+//	if err := msg.Err(); err != nil {
+//		...
+//	}
+//	resp, err := msg.Result()
+//
+// Next returns false once the message completes, the session reports an
+// error, or the event stream ends.
+type MessageStream struct {
+	events    *ssestream.Stream[Event]
+	assembler *MessageAssembler
+	cur       MessageUpdate
+	err       error
+	done      bool
+}
+
+// NewMessageStream assembles the message identified by sessionID and
+// messageID from events. The MessageStream takes ownership of events.
+func NewMessageStream(events *ssestream.Stream[Event], sessionID, messageID string) *MessageStream {
+	s := &MessageStream{events: events, assembler: NewMessageAssembler(sessionID, messageID)}
+	switch {
+	case events == nil:
+		s.err = requiredFieldError("events")
+	case strings.TrimSpace(sessionID) == "":
+		s.err = missingRequiredParameterError("sessionID")
+	case strings.TrimSpace(messageID) == "":
+		s.err = missingRequiredParameterError("messageID")
+	}
+	return s
+}
+
+// Next advances to the next part update.
+func (s *MessageStream) Next() bool {
+	if s.err != nil || s.done {
+		return false
+	}
+	for s.events.Next() {
+		event := s.events.Current()
+		if event.Type == EventTypeSessionError {
+			if err := sessionFailure(event, s.assembler.sessionID); err != nil {
+				s.err = err
+				return false
+			}
+			continue
+		}
+		update, ok := s.assembler.Apply(event)
+		if ok {
+			s.cur = update
+			return true
+		}
+		if s.assembler.Done() {
+			s.done = true
+			return false
+		}
+	}
+	s.err = s.events.Err()
+	if s.err == nil {
+		s.err = fmt.Errorf("message %s: %w", s.assembler.messageID, errEventStreamEnded)
+	}
+	return false
+}
+
+// Current returns the update produced by the last call to Next.
+func (s *MessageStream) Current() MessageUpdate {
+	return s.cur
+}
+
+// Parts returns the message's parts received so far.
+func (s *MessageStream) Parts() []Part {
+	return s.assembler.Parts()
+}
+
+// Text returns the message's text received so far.
+func (s *MessageStream) Text() string {
+	return s.assembler.Text()
+}
+
+// Result returns the consolidated message once Next has returned false
+// without error.
+func (s *MessageStream) Result() (SessionPromptResponse, error) {
+	if s.err != nil {
+		return SessionPromptResponse{}, s.err
+	}
+	if !s.done {
+		return SessionPromptResponse{}, errors.New("message stream is not complete")
+	}
+	return s.assembler.Result()
+}
+
+// Err returns the error that stopped the stream, if any.
+func (s *MessageStream) Err() error {
+	return s.err
+}
+
+// Close closes the underlying event stream.
+func (s *MessageStream) Close() error {
+	if s.events == nil {
+		return nil
+	}
+	return s.events.Close()
+}
+
+// sessionFailure returns a *SessionFailedError if event is a session.error
+// for sessionID.
+func sessionFailure(event Event, sessionID string) error {
+	evt, err := event.AsSessionError()
+	if err != nil {
+		return nil
+	}
+	data := evt.Data
+	if data.SessionID == nil || *data.SessionID != sessionID {
+		return nil
+	}
+	failed := &SessionFailedError{SessionID: sessionID}
+	if data.Error != nil {
+		failed.Err = *data.Error
+	}
+	return failed
+}
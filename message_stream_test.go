@@ -0,0 +1,180 @@
+package opencode
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dominicnunez/opencode-sdk-go/packages/ssestream"
+)
+
+// eventStreamOf returns a Stream that yields the given event payloads.
+func eventStreamOf(payloads ...string) *ssestream.Stream[Event] {
+	var b strings.Builder
+	for _, p := range payloads {
+		b.WriteString("data: " + p + "\n\n")
+	}
+	res := &http.Response{Header: http.Header{}, Body: io.NopCloser(strings.NewReader(b.String()))}
+	return ssestream.NewStream[Event](ssestream.NewDecoder(res), nil)
+}
+
+const (
+	assistantStarted   = `{"type":"message.updated","properties":{"info":{"id":"m2","role":"assistant","sessionID":"s1","parentID":"m1","time":{"created":1}}}}`
+	assistantCompleted = `{"type":"message.updated","properties":{"info":{"id":"m2","role":"assistant","sessionID":"s1","parentID":"m1","time":{"created":1,"completed":2}}}}`
+)
+
+func TestMessageAssembler_AppendsDeltasToParts(t *testing.T) {
+	asm := NewMessageAssembler("s1", "m2")
+
+	// Deltas arrive with a part whose text may or may not already include them.
+	events := []string{
+		`{"type":"message.part.updated","properties":{"part":{"id":"p1","messageID":"m2","sessionID":"s1","type":"text","text":""},"delta":"Hel"}}`,
+		`{"type":"message.part.updated","properties":{"part":{"id":"p1","messageID":"m2","sessionID":"s1","type":"text","text":"Hello"},"delta":"lo"}}`,
+		`{"type":"message.part.updated","properties":{"part":{"id":"p1","messageID":"m2","sessionID":"s1","type":"text","text":"Hel"},"delta":", world"}}`,
+	}
+	var deltas []string
+	for _, raw := range events {
+		update, ok := asm.Apply(mustEvent(t, raw))
+		if !ok {
+			t.Fatalf("expected part update for %s", raw)
+		}
+		deltas = append(deltas, update.Delta)
+	}
+
+	if got := asm.Text(); got != "Hello, world" {
+		t.Fatalf("expected %q, got %q", "Hello, world", got)
+	}
+	if strings.Join(deltas, "") != "Hello, world" {
+		t.Errorf("expected deltas to spell the text, got %q", deltas)
+	}
+	text, err := asm.Parts()[0].AsText()
+	if err != nil || text.ID != "p1" || text.SessionID != "s1" {
+		t.Fatalf("expected merged part to keep its other fields, got %+v, %v", text, err)
+	}
+}
+
+func TestMessageAssembler_JoinsMidStream(t *testing.T) {
+	asm := NewMessageAssembler("s1", "m2")
+
+	// The assembler missed everything before "ld", then the event carrying
+	// "!!" and its text; the server's accumulated text wins over stale state.
+	events := []string{
+		`{"type":"message.part.updated","properties":{"part":{"id":"p1","messageID":"m2","sessionID":"s1","type":"text","text":"Hello, world"},"delta":"ld"}}`,
+		`{"type":"message.part.updated","properties":{"part":{"id":"p1","messageID":"m2","sessionID":"s1","type":"text","text":"Hello, world!!?"},"delta":"?"}}`,
+	}
+	for _, raw := range events {
+		if _, ok := asm.Apply(mustEvent(t, raw)); !ok {
+			t.Fatalf("expected part update for %s", raw)
+		}
+	}
+	if got := asm.Text(); got != "Hello, world!!?" {
+		t.Fatalf("expected %q, got %q", "Hello, world!!?", got)
+	}
+}
+
+func TestMessageAssembler_OrdersAndRemovesParts(t *testing.T) {
+	asm := NewMessageAssembler("s1", "m2")
+	for _, raw := range []string{
+		`{"type":"message.part.updated","properties":{"part":{"id":"p1","messageID":"m2","sessionID":"s1","type":"reasoning","text":"think"}}}`,
+		`{"type":"message.part.updated","properties":{"part":{"id":"p2","messageID":"m2","sessionID":"s1","type":"tool","tool":"bash","callID":"c1","state":{"status":"pending"}}}}`,
+		`{"type":"message.part.updated","properties":{"part":{"id":"p3","messageID":"m2","sessionID":"s1","type":"text","text":"done"}}}`,
+		`{"type":"message.part.updated","properties":{"part":{"id":"p2","messageID":"m2","sessionID":"s1","type":"tool","tool":"bash","callID":"c1","state":{"status":"running"}}}}`,
+		`{"type":"message.part.updated","properties":{"part":{"id":"x","messageID":"other","sessionID":"s1","type":"text","text":"ignored"}}}`,
+	} {
+		asm.Apply(mustEvent(t, raw))
+	}
+
+	parts := asm.Parts()
+	if len(parts) != 3 || parts[0].ID != "p1" || parts[1].ID != "p2" || parts[2].ID != "p3" {
+		t.Fatalf("expected parts [p1 p2 p3], got %+v", parts)
+	}
+
+	update, ok := asm.Apply(mustEvent(t, `{"type":"message.part.removed","properties":{"sessionID":"s1","messageID":"m2","partID":"p2"}}`))
+	if !ok || !update.Removed || update.Part.ID != "p2" {
+		t.Fatalf("expected removal of p2, got %+v", update)
+	}
+	parts = asm.Parts()
+	if len(parts) != 2 || parts[1].ID != "p3" {
+		t.Fatalf("expected parts [p1 p3], got %+v", parts)
+	}
+	asm.Apply(mustEvent(t, `{"type":"message.part.updated","properties":{"part":{"id":"p3","messageID":"m2","sessionID":"s1","type":"text","text":"done!"}}}`))
+	if asm.Text() != "done!" {
+		t.Fatalf("expected index to track removal, got text %q", asm.Text())
+	}
+}
+
+func TestMessageStream_YieldsUpdatesUntilComplete(t *testing.T) {
+	stream := NewMessageStream(eventStreamOf(
+		assistantStarted,
+		`{"type":"message.part.updated","properties":{"part":{"id":"p1","messageID":"m2","sessionID":"s1","type":"text","text":"Hi"},"delta":"Hi"}}`,
+		`{"type":"message.part.updated","properties":{"part":{"id":"p1","messageID":"m2","sessionID":"s1","type":"text","text":"Hi there"},"delta":" there"}}`,
+		assistantCompleted,
+		`{"type":"message.part.updated","properties":{"part":{"id":"p9","messageID":"m2","sessionID":"s1","type":"text","text":"late"}}}`,
+	), "s1", "m2")
+	defer func() { _ = stream.Close() }()
+
+	var out strings.Builder
+	for stream.Next() {
+		out.WriteString(stream.Current().Delta)
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "Hi there" {
+		t.Fatalf("expected %q, got %q", "Hi there", out.String())
+	}
+
+	resp, err := stream.Result()
+	if err != nil {
+		t.Fatalf("Result failed: %v", err)
+	}
+	if resp.Info.ID != "m2" || resp.Info.Time.Completed != 2 || len(resp.Parts) != 1 {
+		t.Fatalf("unexpected result %+v", resp)
+	}
+}
+
+func TestMessageStream_SessionErrorStopsStream(t *testing.T) {
+	stream := NewMessageStream(eventStreamOf(
+		assistantStarted,
+		`{"type":"session.error","properties":{"sessionID":"other","error":{"name":"UnknownError","data":{"message":"x"}}}}`,
+		`{"type":"session.error","properties":{"sessionID":"s1","error":{"name":"MessageAbortedError","data":{"message":"aborted"}}}}`,
+	), "s1", "m2")
+	defer func() { _ = stream.Close() }()
+
+	for stream.Next() {
+	}
+	var failed *SessionFailedError
+	if !errors.As(stream.Err(), &failed) || !errors.Is(stream.Err(), ErrSessionFailed) {
+		t.Fatalf("expected SessionFailedError, got %v", stream.Err())
+	}
+	if _, err := failed.Err.AsAborted(); err != nil {
+		t.Fatalf("expected aborted error, got %v", err)
+	}
+	if _, err := stream.Result(); err == nil {
+		t.Fatal("expected Result to fail")
+	}
+}
+
+func TestMessageStream_EndedEarly(t *testing.T) {
+	stream := NewMessageStream(eventStreamOf(assistantStarted), "s1", "m2")
+	defer func() { _ = stream.Close() }()
+
+	if stream.Next() {
+		t.Fatal("expected no updates")
+	}
+	if !errors.Is(stream.Err(), errEventStreamEnded) {
+		t.Fatalf("expected stream ended error, got %v", stream.Err())
+	}
+}
+
+func TestMessageStream_RequiresIDs(t *testing.T) {
+	stream := NewMessageStream(eventStreamOf(), "s1", " ")
+	if stream.Next() {
+		t.Fatal("expected Next to return false")
+	}
+	if !errors.Is(stream.Err(), ErrMissingRequiredParameter) {
+		t.Fatalf("expected missing parameter error, got %v", stream.Err())
+	}
+}
@@ -0,0 +1,273 @@
+// Package checkpoint labels points in an opencode session so they can be
+// reverted to by name, on top of Session.Revert and Session.Unrevert.
+//
+//	m, err := checkpoint.New(client, checkpoint.NewFileStore(".opencode-checkpoints.json"), nil)
+//	_, err = m.Create(ctx, sessionID, "before-refactor")
+//	...
+//	files, err := m.Preview(ctx, sessionID, "before-refactor")
+//	session, err := m.Revert(ctx, sessionID, "before-refactor")
+//	session, err = m.Undo(ctx, sessionID)
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	opencode "github.com/dominicnunez/opencode-sdk-go"
+)
+
+var (
+	// ErrNotFound is returned for a label that does not exist in a session.
+	ErrNotFound = errors.New("checkpoint: not found")
+	// ErrExists is returned by Create for a label already used in a session.
+	ErrExists = errors.New("checkpoint: already exists")
+	// ErrNothingToRevert is returned by Revert when no messages follow the
+	// checkpoint.
+	ErrNothingToRevert = errors.New("checkpoint: nothing to revert")
+)
+
+// Checkpoint is a labeled point in a session.
+type Checkpoint struct {
+	SessionID string `json:"sessionID"`
+	Label     string `json:"label"`
+	// MessageID is the last message in the session when the checkpoint was
+	// created, or empty if the session had none. Everything after it is
+	// undone by reverting to the checkpoint.
+	MessageID string    `json:"messageID,omitempty"`
+	Created   time.Time `json:"created"`
+}
+
+// Status is a checkpoint with what has happened in the session since.
+type Status struct {
+	Checkpoint
+	// Messages is the number of messages after the checkpoint.
+	Messages int
+	// Files are the changes made by those messages, as reported by
+	// Session.Diff.
+	Files []opencode.FileDiff
+	// Reverted is true when the session's pending revert is at this
+	// checkpoint.
+	Reverted bool
+}
+
+// Options configures a Manager.
+type Options struct {
+	Directory *string
+}
+
+// Manager creates checkpoints and reverts sessions to them. A session has at
+// most one pending revert: reverting to another checkpoint replaces it, and
+// Undo restores everything it hid. Messages hidden by a pending revert are
+// discarded by the server once the session is prompted again.
+type Manager struct {
+	client    *opencode.Client
+	store     Store
+	directory *string
+}
+
+// New returns a Manager that keeps labels in store.
+func New(client *opencode.Client, store Store, opts *Options) (*Manager, error) {
+	if client == nil {
+		return nil, errors.New("checkpoint: client is required")
+	}
+	if store == nil {
+		return nil, errors.New("checkpoint: store is required")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	return &Manager{client: client, store: store, directory: opts.Directory}, nil
+}
+
+// Create labels the current end of the session. Messages hidden by a pending
+// revert are not part of the session's current state, so a checkpoint
+// created then marks the last message before the revert.
+func (m *Manager) Create(ctx context.Context, sessionID, label string) (Checkpoint, error) {
+	if strings.TrimSpace(label) == "" {
+		return Checkpoint{}, errors.New("checkpoint: label is required")
+	}
+	if _, err := m.store.Load(ctx, sessionID, label); err == nil {
+		return Checkpoint{}, fmt.Errorf("%w: %q in session %s", ErrExists, label, sessionID)
+	} else if !errors.Is(err, ErrNotFound) {
+		return Checkpoint{}, err
+	}
+
+	st, err := m.state(ctx, sessionID)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	visible := st.messageIDs
+	if st.session.Revert != nil {
+		visible = visible[:sort.SearchStrings(visible, st.session.Revert.MessageID)]
+	}
+
+	c := Checkpoint{SessionID: sessionID, Label: label, Created: time.Now()}
+	if len(visible) > 0 {
+		c.MessageID = visible[len(visible)-1]
+	}
+	if err := m.store.Save(ctx, c); err != nil {
+		return Checkpoint{}, err
+	}
+	return c, nil
+}
+
+// Get returns a checkpoint by label.
+func (m *Manager) Get(ctx context.Context, sessionID, label string) (Checkpoint, error) {
+	return m.store.Load(ctx, sessionID, label)
+}
+
+// Delete removes a checkpoint. The session is not changed.
+func (m *Manager) Delete(ctx context.Context, sessionID, label string) error {
+	return m.store.Delete(ctx, sessionID, label)
+}
+
+// List returns the session's checkpoints, oldest first, each with the files
+// changed since.
+func (m *Manager) List(ctx context.Context, sessionID string) ([]Status, error) {
+	checkpoints, err := m.store.List(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(checkpoints) == 0 {
+		return nil, nil
+	}
+	st, err := m.state(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(checkpoints))
+	for i, c := range checkpoints {
+		status := Status{Checkpoint: c}
+		target, following := st.after(c)
+		status.Messages = following
+		if target != "" {
+			status.Files, err = m.diff(ctx, sessionID, target)
+			if err != nil {
+				return nil, err
+			}
+			status.Reverted = st.session.Revert != nil && st.session.Revert.MessageID == target
+		}
+		statuses[i] = status
+	}
+	return statuses, nil
+}
+
+// Preview returns the file changes that reverting to the checkpoint would
+// undo, without changing anything.
+func (m *Manager) Preview(ctx context.Context, sessionID, label string) ([]opencode.FileDiff, error) {
+	c, err := m.store.Load(ctx, sessionID, label)
+	if err != nil {
+		return nil, err
+	}
+	st, err := m.state(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	target, _ := st.after(c)
+	if target == "" {
+		return nil, nil
+	}
+	return m.diff(ctx, sessionID, target)
+}
+
+// Revert reverts the session to the checkpoint, hiding every later message
+// and restoring the files they changed. If a revert is already pending at an
+// earlier point, it is undone first so the session can move forward to the
+// checkpoint.
+func (m *Manager) Revert(ctx context.Context, sessionID, label string) (*opencode.Session, error) {
+	c, err := m.store.Load(ctx, sessionID, label)
+	if err != nil {
+		return nil, err
+	}
+	st, err := m.state(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	target, _ := st.after(c)
+	if target == "" {
+		return nil, fmt.Errorf("%w: no messages after %q", ErrNothingToRevert, label)
+	}
+
+	if pending := st.session.Revert; pending != nil {
+		if pending.MessageID == target {
+			return st.session, nil
+		}
+		if pending.MessageID < target {
+			if _, err := m.Undo(ctx, sessionID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	session, err := m.client.Session.Revert(ctx, sessionID, &opencode.SessionRevertParams{
+		MessageID: target,
+		Directory: m.directory,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint: revert to %q: %w", label, err)
+	}
+	return session, nil
+}
+
+// Undo restores everything hidden by the session's pending revert.
+func (m *Manager) Undo(ctx context.Context, sessionID string) (*opencode.Session, error) {
+	session, err := m.client.Session.Unrevert(ctx, sessionID, &opencode.SessionUnrevertParams{Directory: m.directory})
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint: unrevert: %w", err)
+	}
+	return session, nil
+}
+
+func (m *Manager) diff(ctx context.Context, sessionID, messageID string) ([]opencode.FileDiff, error) {
+	files, err := m.client.Session.Diff(ctx, sessionID, &opencode.SessionDiffParams{
+		Directory: m.directory,
+		MessageID: opencode.Ptr(messageID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint: diff since %s: %w", messageID, err)
+	}
+	return files, nil
+}
+
+type sessionState struct {
+	session *opencode.Session
+	// messageIDs are sorted, which is also creation order.
+	messageIDs []string
+}
+
+// after returns the first message following the checkpoint, which is the
+// point Session.Revert takes, and how many messages follow it.
+func (s *sessionState) after(c Checkpoint) (string, int) {
+	i := sort.SearchStrings(s.messageIDs, c.MessageID)
+	if i < len(s.messageIDs) && s.messageIDs[i] == c.MessageID {
+		i++
+	}
+	if i == len(s.messageIDs) {
+		return "", 0
+	}
+	return s.messageIDs[i], len(s.messageIDs) - i
+}
+
+func (m *Manager) state(ctx context.Context, sessionID string) (*sessionState, error) {
+	session, err := m.client.Session.Get(ctx, sessionID, &opencode.SessionGetParams{Directory: m.directory})
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint: get session %s: %w", sessionID, err)
+	}
+
+	messages := m.client.Session.MessagesStreaming(ctx, sessionID, &opencode.SessionMessagesParams{Directory: m.directory})
+	defer func() { _ = messages.Close() }()
+	var ids []string
+	for messages.Next() {
+		ids = append(ids, messages.Current().Info.ID)
+	}
+	if err := messages.Err(); err != nil {
+		return nil, fmt.Errorf("checkpoint: messages for session %s: %w", sessionID, err)
+	}
+	sort.Strings(ids)
+	return &sessionState{session: session, messageIDs: ids}, nil
+}
@@ -0,0 +1,251 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	opencode "github.com/dominicnunez/opencode-sdk-go"
+)
+
+// fakeServer serves one session whose messages and pending revert can be
+// changed by the test and by revert/unrevert requests.
+type fakeServer struct {
+	mu       sync.Mutex
+	messages []string
+	revert   string
+	calls    []string
+}
+
+func (f *fakeServer) add(ids ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = append(f.messages, ids...)
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	session := func() {
+		revert := ""
+		if f.revert != "" {
+			revert = fmt.Sprintf(`,"revert":{"messageID":%q}`, f.revert)
+		}
+		fmt.Fprintf(w, `{"id":"ses_1","title":"t"%s}`, revert)
+	}
+
+	switch r.Method + " " + r.URL.Path {
+	case "GET /session/ses_1":
+		session()
+	case "GET /session/ses_1/message":
+		var items []string
+		for _, id := range f.messages {
+			items = append(items, fmt.Sprintf(`{"info":{"id":%q,"role":"user","sessionID":"ses_1","time":{"created":1}},"parts":[]}`, id))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(items, ","))
+	case "GET /session/ses_1/diff":
+		id := r.URL.Query().Get("messageID")
+		f.calls = append(f.calls, "diff "+id)
+		fmt.Fprintf(w, `[{"file":"changed-since-%s.go","before":"a\n","after":"b\n","additions":1,"deletions":1}]`, id)
+	case "POST /session/ses_1/revert":
+		var params struct {
+			MessageID string `json:"messageID"`
+		}
+		_ = json.Unmarshal(body, &params)
+		f.calls = append(f.calls, "revert "+params.MessageID)
+		f.revert = params.MessageID
+		session()
+	case "POST /session/ses_1/unrevert":
+		f.calls = append(f.calls, "unrevert")
+		f.revert = ""
+		session()
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestManager(t *testing.T, store Store) (*Manager, *fakeServer) {
+	t.Helper()
+	fake := &fakeServer{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	client, err := opencode.NewClient(opencode.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	m, err := New(client, store, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return m, fake
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	m, fake := newTestManager(t, NewMemoryStore())
+
+	start, err := m.Create(ctx, "ses_1", "start")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if start.MessageID != "" {
+		t.Errorf("checkpoint of empty session has MessageID %q", start.MessageID)
+	}
+	fake.add("msg_01", "msg_02")
+	refactor, err := m.Create(ctx, "ses_1", "before-refactor")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if refactor.MessageID != "msg_02" {
+		t.Errorf("MessageID = %q, want msg_02", refactor.MessageID)
+	}
+	if _, err := m.Create(ctx, "ses_1", "before-refactor"); !errors.Is(err, ErrExists) {
+		t.Errorf("duplicate Create err = %v, want ErrExists", err)
+	}
+	if _, err := m.Revert(ctx, "ses_1", "before-refactor"); !errors.Is(err, ErrNothingToRevert) {
+		t.Errorf("Revert with no later messages err = %v, want ErrNothingToRevert", err)
+	}
+	fake.add("msg_03", "msg_04")
+
+	statuses, err := m.List(ctx, "ses_1")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].Label != "start" || statuses[1].Label != "before-refactor" {
+		t.Fatalf("List = %+v", statuses)
+	}
+	if statuses[0].Messages != 4 || statuses[1].Messages != 2 {
+		t.Errorf("message counts = %d, %d", statuses[0].Messages, statuses[1].Messages)
+	}
+	if got := statuses[1].Files; len(got) != 1 || got[0].File != "changed-since-msg_03.go" {
+		t.Errorf("Files = %+v", got)
+	}
+
+	files, err := m.Preview(ctx, "ses_1", "start")
+	if err != nil || len(files) != 1 || files[0].File != "changed-since-msg_01.go" {
+		t.Errorf("Preview = %+v, %v", files, err)
+	}
+
+	session, err := m.Revert(ctx, "ses_1", "before-refactor")
+	if err != nil {
+		t.Fatalf("Revert: %v", err)
+	}
+	if session.Revert == nil || session.Revert.MessageID != "msg_03" {
+		t.Errorf("Revert = %+v", session.Revert)
+	}
+	statuses, err = m.List(ctx, "ses_1")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if statuses[0].Reverted || !statuses[1].Reverted {
+		t.Errorf("Reverted flags = %v, %v", statuses[0].Reverted, statuses[1].Reverted)
+	}
+
+	// A checkpoint taken while a revert is pending ignores hidden messages.
+	pending, err := m.Create(ctx, "ses_1", "while-reverted")
+	if err != nil || pending.MessageID != "msg_02" {
+		t.Errorf("Create while reverted = %+v, %v", pending, err)
+	}
+
+	// Reverting further back replaces the pending revert; moving forward
+	// again unreverts first.
+	if _, err := m.Revert(ctx, "ses_1", "start"); err != nil {
+		t.Fatalf("Revert start: %v", err)
+	}
+	if _, err := m.Revert(ctx, "ses_1", "before-refactor"); err != nil {
+		t.Fatalf("Revert before-refactor: %v", err)
+	}
+	if _, err := m.Undo(ctx, "ses_1"); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+
+	fake.mu.Lock()
+	var actions []string
+	for _, call := range fake.calls {
+		if !strings.HasPrefix(call, "diff") {
+			actions = append(actions, call)
+		}
+	}
+	fake.mu.Unlock()
+	want := "revert msg_03,revert msg_01,unrevert,revert msg_03,unrevert"
+	if got := strings.Join(actions, ","); got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+
+	if err := m.Delete(ctx, "ses_1", "start"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := m.Get(ctx, "ses_1", "start"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete err = %v, want ErrNotFound", err)
+	}
+	if _, err := m.Revert(ctx, "ses_1", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Revert unknown label err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "nested", "checkpoints.json")
+	store := NewFileStore(path)
+
+	if list, err := store.List(ctx, "ses_1"); err != nil || len(list) != 0 {
+		t.Fatalf("List on missing file = %v, %v", list, err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, label := range []string{"b", "a"} {
+		c := Checkpoint{SessionID: "ses_1", Label: label, MessageID: "msg_1", Created: now.Add(time.Duration(i) * time.Minute)}
+		if err := store.Save(ctx, c); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	if err := store.Save(ctx, Checkpoint{SessionID: "ses_2", Label: "a", Created: now}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// A second store over the same file sees the saved checkpoints.
+	reopened := NewFileStore(path)
+	list, err := reopened.List(ctx, "ses_1")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 2 || list[0].Label != "b" || list[1].Label != "a" || !list[0].Created.Equal(now) {
+		t.Errorf("List = %+v", list)
+	}
+	if err := reopened.Delete(ctx, "ses_1", "b"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Load(ctx, "ses_1", "b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load deleted err = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, "ses_1", "b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete twice err = %v, want ErrNotFound", err)
+	}
+	if c, err := store.Load(ctx, "ses_2", "a"); err != nil || c.SessionID != "ses_2" {
+		t.Errorf("Load other session = %+v, %v", c, err)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New(nil, NewMemoryStore(), nil); err == nil {
+		t.Error("expected error for nil client")
+	}
+	client, err := opencode.NewClient(opencode.WithBaseURL("http://localhost"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(client, nil, nil); err == nil {
+		t.Error("expected error for nil store")
+	}
+}
@@ -0,0 +1,221 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Store persists checkpoints. Implementations must be safe for concurrent
+// use. Load and Delete return an error matching ErrNotFound for an unknown
+// label.
+type Store interface {
+	Save(ctx context.Context, c Checkpoint) error
+	Load(ctx context.Context, sessionID, label string) (Checkpoint, error)
+	// List returns the checkpoints of a session, oldest first.
+	List(ctx context.Context, sessionID string) ([]Checkpoint, error)
+	Delete(ctx context.Context, sessionID, label string) error
+}
+
+type storeKey struct {
+	sessionID string
+	label     string
+}
+
+// MemoryStore keeps checkpoints in memory for the life of the process.
+type MemoryStore struct {
+	mu          sync.Mutex
+	checkpoints map[storeKey]Checkpoint
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{checkpoints: make(map[storeKey]Checkpoint)}
+}
+
+func (s *MemoryStore) Save(_ context.Context, c Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[storeKey{c.SessionID, c.Label}] = c
+	return nil
+}
+
+func (s *MemoryStore) Load(_ context.Context, sessionID, label string) (Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.checkpoints[storeKey{sessionID, label}]
+	if !ok {
+		return Checkpoint{}, notFound(sessionID, label)
+	}
+	return c, nil
+}
+
+func (s *MemoryStore) List(_ context.Context, sessionID string) ([]Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []Checkpoint
+	for key, c := range s.checkpoints {
+		if key.sessionID == sessionID {
+			list = append(list, c)
+		}
+	}
+	sortCheckpoints(list)
+	return list, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, sessionID, label string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := storeKey{sessionID, label}
+	if _, ok := s.checkpoints[key]; !ok {
+		return notFound(sessionID, label)
+	}
+	delete(s.checkpoints, key)
+	return nil
+}
+
+// FileStore keeps checkpoints in a JSON file, rewritten atomically on every
+// change. It is safe for concurrent use within one process; separate
+// processes sharing a file may overwrite each other's changes.
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileStore returns a store backed by the file at path. The file and its
+// directory are created on the first Save.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (s *FileStore) Save(_ context.Context, c Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i, existing := range all {
+		if existing.SessionID == c.SessionID && existing.Label == c.Label {
+			all[i] = c
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		all = append(all, c)
+	}
+	return s.write(all)
+}
+
+func (s *FileStore) Load(_ context.Context, sessionID, label string) (Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.read()
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	for _, c := range all {
+		if c.SessionID == sessionID && c.Label == label {
+			return c, nil
+		}
+	}
+	return Checkpoint{}, notFound(sessionID, label)
+}
+
+func (s *FileStore) List(_ context.Context, sessionID string) ([]Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	var list []Checkpoint
+	for _, c := range all {
+		if c.SessionID == sessionID {
+			list = append(list, c)
+		}
+	}
+	sortCheckpoints(list)
+	return list, nil
+}
+
+func (s *FileStore) Delete(_ context.Context, sessionID, label string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+	for i, c := range all {
+		if c.SessionID == sessionID && c.Label == label {
+			return s.write(append(all[:i], all[i+1:]...))
+		}
+	}
+	return notFound(sessionID, label)
+}
+
+func (s *FileStore) read() ([]Checkpoint, error) {
+	data, err := os.ReadFile(s.path) //nolint:gosec // the store path is chosen by the caller
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint: read store: %w", err)
+	}
+	var all []Checkpoint
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("checkpoint: decode store %s: %w", s.path, err)
+	}
+	return all, nil
+}
+
+func (s *FileStore) write(all []Checkpoint) error {
+	if all == nil {
+		all = []Checkpoint{}
+	}
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("checkpoint: encode store: %w", err)
+	}
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("checkpoint: create store directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("checkpoint: write store: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("checkpoint: write store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("checkpoint: write store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("checkpoint: write store: %w", err)
+	}
+	return nil
+}
+
+func notFound(sessionID, label string) error {
+	return fmt.Errorf("%w: %q in session %s", ErrNotFound, label, sessionID)
+}
+
+func sortCheckpoints(list []Checkpoint) {
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Created.Equal(list[j].Created) {
+			return list[i].Created.Before(list[j].Created)
+		}
+		return list[i].Label < list[j].Label
+	})
+}
@@ -0,0 +1,217 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dominicnunez/opencode-sdk-go/internal/textlines"
+)
+
+// ErrConflict is returned by Apply when a file in the checkout has changed in
+// a way the diff cannot be applied to. Use the result's Conflicts for
+// details.
+var ErrConflict = errors.New("diff: conflict")
+
+// ApplyOptions configures Apply.
+type ApplyOptions struct {
+	// DryRun checks every file for conflicts without writing anything.
+	DryRun bool
+}
+
+// Conflict describes a file Apply could not change.
+type Conflict struct {
+	Path   string
+	Reason string
+}
+
+func (c Conflict) String() string {
+	return c.Path + ": " + c.Reason
+}
+
+// ApplyResult reports what Apply did with each file.
+type ApplyResult struct {
+	// Applied lists files that were written, created or deleted, or
+	// would be in a dry run.
+	Applied []string
+	// Merged lists the subset of Applied whose content differed from
+	// Before and were patched hunk by hunk.
+	Merged []string
+	// Unchanged lists files that already matched After or already contain
+	// every hunk.
+	Unchanged []string
+	Conflicts []Conflict
+}
+
+// Apply brings the files under root to their After state. A file whose
+// current content equals Before is replaced by After outright. If it has
+// since changed elsewhere, each hunk is located by its context and applied
+// in place, allowing for lines shifted by unrelated edits. A file that
+// already equals After is left alone.
+//
+// Every file is checked before any is written, so if any file conflicts
+// nothing is changed and the error matches ErrConflict. Paths must be
+// relative and stay within root.
+func Apply(root string, files []File, opts *ApplyOptions) (*ApplyResult, error) {
+	if opts == nil {
+		opts = &ApplyOptions{}
+	}
+
+	type write struct {
+		path    string
+		content string
+		remove  bool
+	}
+	var writes []write
+	result := &ApplyResult{}
+
+	for _, f := range files {
+		target, err := resolve(root, f.Path)
+		if err != nil {
+			result.Conflicts = append(result.Conflicts, Conflict{Path: f.Path, Reason: err.Error()})
+			continue
+		}
+
+		data, err := os.ReadFile(target) //nolint:gosec // target is confined to root by resolve
+		exists := err == nil
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("diff: read %s: %w", f.Path, err)
+		}
+		current := string(data)
+		deleting := f.After == "" && f.Before != ""
+
+		switch {
+		case deleting && !exists, !deleting && exists && current == f.After:
+			result.Unchanged = append(result.Unchanged, f.Path)
+		case !exists && f.Before != "":
+			result.Conflicts = append(result.Conflicts, Conflict{Path: f.Path, Reason: "file does not exist"})
+		case exists && f.Before == "" && current != "":
+			result.Conflicts = append(result.Conflicts, Conflict{Path: f.Path, Reason: "file already exists"})
+		case current == f.Before:
+			writes = append(writes, write{path: target, content: f.After, remove: deleting})
+			result.Applied = append(result.Applied, f.Path)
+		case deleting:
+			result.Conflicts = append(result.Conflicts, Conflict{Path: f.Path, Reason: "file to delete has changed"})
+		default:
+			hunks := f.Hunks(DefaultContext)
+			merged, err := applyHunks(current, hunks)
+			if err != nil && alreadyApplied(current, hunks) {
+				result.Unchanged = append(result.Unchanged, f.Path)
+				continue
+			}
+			if err != nil {
+				result.Conflicts = append(result.Conflicts, Conflict{Path: f.Path, Reason: err.Error()})
+				continue
+			}
+			writes = append(writes, write{path: target, content: merged})
+			result.Applied = append(result.Applied, f.Path)
+			result.Merged = append(result.Merged, f.Path)
+		}
+	}
+
+	if len(result.Conflicts) > 0 {
+		return result, fmt.Errorf("%w in %d file(s)", ErrConflict, len(result.Conflicts))
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	for _, w := range writes {
+		if w.remove {
+			if err := os.Remove(w.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return result, fmt.Errorf("diff: remove %s: %w", w.path, err)
+			}
+			continue
+		}
+		if err := writeFile(w.path, w.content); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// resolve joins a diff path to root, rejecting paths that would escape it.
+func resolve(root, path string) (string, error) {
+	local := filepath.FromSlash(path)
+	if !filepath.IsLocal(local) {
+		return "", fmt.Errorf("path %q is not within the checkout", path)
+	}
+	return filepath.Join(root, local), nil
+}
+
+func writeFile(path, content string) error {
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { //nolint:gosec // checkout directories are meant to be readable
+		return fmt.Errorf("diff: create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		return fmt.Errorf("diff: write %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyHunks patches content hunk by hunk. Each hunk's old lines must appear
+// verbatim; the search starts where the hunk expects to be, adjusted by the
+// shift of earlier hunks, and widens in both directions.
+func applyHunks(content string, hunks []Hunk) (string, error) {
+	lines := textlines.Split(content)
+	var out []string
+	pos, shift := 0, 0
+
+	for i, h := range hunks {
+		var old, repl []string
+		for _, line := range h.Lines {
+			if line.Kind != LineInsert {
+				old = append(old, line.raw())
+			}
+			if line.Kind != LineDelete {
+				repl = append(repl, line.raw())
+			}
+		}
+
+		// OldStart is 1-based, except that a hunk with no old lines names
+		// the line it follows.
+		base := h.OldStart - 1
+		if h.OldLines == 0 {
+			base = h.OldStart
+		}
+		at := textlines.Find(lines, old, pos, base+shift, textlines.Exact)
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d (%s) does not match the current file", i+1, h.Header())
+		}
+		out = append(out, lines[pos:at]...)
+		out = append(out, repl...)
+		pos = at + len(old)
+		shift = at - base
+	}
+	out = append(out, lines[pos:]...)
+	return strings.Join(out, ""), nil
+}
+
+// alreadyApplied reports whether every hunk's new lines are present in
+// content, as after an earlier Apply merged them into a file that had
+// diverged from Before.
+func alreadyApplied(content string, hunks []Hunk) bool {
+	reversed := make([]Hunk, len(hunks))
+	for i, h := range hunks {
+		r := Hunk{OldStart: h.NewStart, OldLines: h.NewLines, NewStart: h.OldStart, NewLines: h.OldLines}
+		for _, line := range h.Lines {
+			switch line.Kind {
+			case LineDelete:
+				line.Kind = LineInsert
+			case LineInsert:
+				line.Kind = LineDelete
+			}
+			r.Lines = append(r.Lines, line)
+		}
+		reversed[i] = r
+	}
+	_, err := applyHunks(content, reversed)
+	return err == nil
+}
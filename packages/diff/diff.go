@@ -0,0 +1,351 @@
+// Package diff renders and applies the file changes returned by
+// Session.Diff and carried in SessionSummary.Diffs.
+//
+//	diffs, err := client.Session.Diff(ctx, sessionID, nil)
+//	files := diff.FromFileDiffs(diffs)
+//	fmt.Print(diff.Unified(files[0], diff.DefaultContext))
+//	result, err := diff.Apply("/path/to/checkout", files, nil)
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	opencode "github.com/dominicnunez/opencode-sdk-go"
+	"github.com/dominicnunez/opencode-sdk-go/internal/textlines"
+)
+
+// DefaultContext is the number of unchanged lines shown around each change,
+// as in diff -u.
+const DefaultContext = 3
+
+// File is the full text of a file before and after a change. An empty Before
+// means the file was created and an empty After that it was deleted.
+type File struct {
+	Path   string
+	Before string
+	After  string
+}
+
+// FromFileDiff converts a diff returned by Session.Diff.
+func FromFileDiff(d opencode.FileDiff) File {
+	return File{Path: d.File, Before: d.Before, After: d.After}
+}
+
+// FromFileDiffs converts the diffs returned by Session.Diff.
+func FromFileDiffs(diffs []opencode.FileDiff) []File {
+	files := make([]File, len(diffs))
+	for i, d := range diffs {
+		files[i] = FromFileDiff(d)
+	}
+	return files
+}
+
+// FromSummary converts the diffs carried in a session summary.
+func FromSummary(summary *opencode.SessionSummary) []File {
+	if summary == nil {
+		return nil
+	}
+	files := make([]File, len(summary.Diffs))
+	for i, d := range summary.Diffs {
+		files[i] = File{Path: d.File, Before: d.Before, After: d.After}
+	}
+	return files
+}
+
+// LineKind says whether a diff line is unchanged, removed or added.
+type LineKind byte
+
+const (
+	LineContext LineKind = ' '
+	LineDelete  LineKind = '-'
+	LineInsert  LineKind = '+'
+)
+
+// Line is one line of a hunk.
+type Line struct {
+	Kind LineKind
+	// Text is the line without its trailing newline.
+	Text string
+	// NoNewline is true for a last line that does not end in a newline.
+	NoNewline bool
+	// OldNumber and NewNumber are 1-based line numbers in Before and After,
+	// or 0 for a line that does not exist on that side.
+	OldNumber int
+	NewNumber int
+}
+
+func (l Line) raw() string {
+	if l.NoNewline {
+		return l.Text
+	}
+	return l.Text + "\n"
+}
+
+// Hunk is a group of nearby changes with surrounding context.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []Line
+}
+
+// Header returns the hunk's @@ range line.
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines))
+}
+
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// Stats counts added and removed lines.
+func (f File) Stats() (additions, deletions int) {
+	for _, e := range diffLines(textlines.Split(f.Before), textlines.Split(f.After)) {
+		switch e.kind {
+		case LineInsert:
+			additions++
+		case LineDelete:
+			deletions++
+		}
+	}
+	return additions, deletions
+}
+
+// Hunks compares Before and After line by line and groups the changes into
+// hunks with up to context unchanged lines around each. A negative context is
+// treated as zero.
+func (f File) Hunks(context int) []Hunk {
+	if context < 0 {
+		context = 0
+	}
+	a, b := textlines.Split(f.Before), textlines.Split(f.After)
+	edits := diffLines(a, b)
+
+	var hunks []Hunk
+	for i := 0; i < len(edits); {
+		if edits[i].kind == LineContext {
+			i++
+			continue
+		}
+		// Extend the hunk while at most 2*context unchanged lines separate
+		// the next change from the previous one.
+		start := max(i-context, 0)
+		end := i
+		for j := i; j < len(edits); j++ {
+			if edits[j].kind == LineContext {
+				continue
+			}
+			if j-end-1 > 2*context {
+				break
+			}
+			end = j
+		}
+		end = min(end+context+1, len(edits))
+
+		hunks = append(hunks, buildHunk(edits[start:end], a, b))
+		i = end
+	}
+	return hunks
+}
+
+func buildHunk(edits []edit, a, b []string) Hunk {
+	h := Hunk{OldStart: edits[0].a + 1, NewStart: edits[0].b + 1}
+	for _, e := range edits {
+		var line Line
+		switch e.kind {
+		case LineContext:
+			line = Line{Kind: LineContext, Text: a[e.a], OldNumber: e.a + 1, NewNumber: e.b + 1}
+			h.OldLines++
+			h.NewLines++
+		case LineDelete:
+			line = Line{Kind: LineDelete, Text: a[e.a], OldNumber: e.a + 1}
+			h.OldLines++
+		case LineInsert:
+			line = Line{Kind: LineInsert, Text: b[e.b], NewNumber: e.b + 1}
+			h.NewLines++
+		}
+		text, terminated := strings.CutSuffix(line.Text, "\n")
+		line.Text, line.NoNewline = text, !terminated
+		h.Lines = append(h.Lines, line)
+	}
+	// An empty side starts at the line before, as diff -u prints it.
+	if h.OldLines == 0 {
+		h.OldStart--
+	}
+	if h.NewLines == 0 {
+		h.NewStart--
+	}
+	return h
+}
+
+// Unified returns f as a unified diff with context lines around each change,
+// or "" if Before and After are equal.
+func Unified(f File, context int) string {
+	hunks := f.Hunks(context)
+	if len(hunks) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	oldName, newName := "a/"+f.Path, "b/"+f.Path
+	if f.Before == "" {
+		oldName = "/dev/null"
+	}
+	if f.After == "" {
+		newName = "/dev/null"
+	}
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks {
+		b.WriteString(h.Header())
+		b.WriteByte('\n')
+		for _, line := range h.Lines {
+			b.WriteByte(byte(line.Kind))
+			b.WriteString(line.Text)
+			b.WriteByte('\n')
+			if line.NoNewline {
+				b.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// UnifiedAll concatenates the unified diffs of files.
+func UnifiedAll(files []File, context int) string {
+	var b strings.Builder
+	for _, f := range files {
+		b.WriteString(Unified(f, context))
+	}
+	return b.String()
+}
+
+type edit struct {
+	kind LineKind
+	// a and b index the line in each input. For an insert a is the
+	// position in the old input the line goes before, and vice versa.
+	a, b int
+}
+
+// maxTrace bounds the memory the Myers search may use. Inputs that differ by
+// more than it allows fall back to replacing the differing block wholesale,
+// which is correct but not minimal.
+const maxTrace = 1 << 22
+
+// diffLines returns the edit script turning a into b, using Myers' O(ND)
+// algorithm after trimming the common prefix and suffix.
+func diffLines(a, b []string) []edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var edits []edit
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, edit{LineContext, i, i})
+	}
+	for _, e := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		e.a += prefix
+		e.b += prefix
+		edits = append(edits, e)
+	}
+	for i := suffix; i > 0; i-- {
+		edits = append(edits, edit{LineContext, len(a) - i, len(b) - i})
+	}
+	return edits
+}
+
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+	limit := n + m
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+	traced := 0
+
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+				return backtrack(trace, n, m)
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		traced += 2*d + 1
+		if traced > maxTrace {
+			return replaceAll(n, m)
+		}
+	}
+	return replaceAll(n, m)
+}
+
+func backtrack(trace [][]int, n, m int) []edit {
+	var edits []edit
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+d-1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{LineContext, x, y})
+		}
+		if x == prevX {
+			edits = append(edits, edit{LineInsert, x, prevY})
+		} else {
+			edits = append(edits, edit{LineDelete, prevX, y})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		edits = append(edits, edit{LineContext, x, y})
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+func replaceAll(n, m int) []edit {
+	edits := make([]edit, 0, n+m)
+	for i := 0; i < n; i++ {
+		edits = append(edits, edit{LineDelete, i, 0})
+	}
+	for j := 0; j < m; j++ {
+		edits = append(edits, edit{LineInsert, n, j})
+	}
+	return edits
+}
@@ -0,0 +1,269 @@
+package diff
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dominicnunez/opencode-sdk-go/internal/textlines"
+)
+
+func TestUnified(t *testing.T) {
+	f := File{
+		Path:   "main.go",
+		Before: "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n",
+		After:  "package main\n\nfunc main() {\n\tprintln(\"hello\")\n\tprintln(\"world\")\n}\n",
+	}
+	want := "--- a/main.go\n+++ b/main.go\n@@ -1,5 +1,6 @@\n" +
+		" package main\n" +
+		" \n" +
+		" func main() {\n" +
+		"-\tprintln(\"hi\")\n" +
+		"+\tprintln(\"hello\")\n" +
+		"+\tprintln(\"world\")\n" +
+		" }\n"
+	if got := Unified(f, DefaultContext); got != want {
+		t.Errorf("Unified =\n%s\nwant\n%s", got, want)
+	}
+	if got := Unified(File{Path: "x", Before: "a\n", After: "a\n"}, 3); got != "" {
+		t.Errorf("Unified of equal files = %q, want empty", got)
+	}
+}
+
+func TestHunks_Context(t *testing.T) {
+	var before, after strings.Builder
+	for i := 1; i <= 20; i++ {
+		line := strings.Repeat("x", i) + "\n"
+		before.WriteString(line)
+		if i == 3 || i == 10 || i == 18 {
+			line = "changed\n"
+		}
+		after.WriteString(line)
+	}
+	f := File{Path: "f", Before: before.String(), After: after.String()}
+
+	// Changes 7 lines apart merge when the context (2*3) bridges the gap,
+	// and split when it does not.
+	if hunks := f.Hunks(3); len(hunks) != 2 {
+		t.Fatalf("Hunks(3) = %d hunks, want 2", len(hunks))
+	} else if got := hunks[0].Header(); got != "@@ -1,13 +1,13 @@" {
+		t.Errorf("first header = %q", got)
+	}
+	if hunks := f.Hunks(1); len(hunks) != 3 {
+		t.Errorf("Hunks(1) = %d hunks, want 3", len(hunks))
+	}
+	if hunks := f.Hunks(0); len(hunks) != 3 || hunks[1].Header() != "@@ -10 +10 @@" {
+		t.Errorf("Hunks(0) = %+v", hunks)
+	}
+	if add, del := f.Stats(); add != 3 || del != 3 {
+		t.Errorf("Stats = +%d -%d, want +3 -3", add, del)
+	}
+}
+
+func TestUnified_NoNewlineAndCreateDelete(t *testing.T) {
+	got := Unified(File{Path: "a.txt", Before: "one\ntwo", After: "one\ntwo\n"}, 3)
+	want := "--- a/a.txt\n+++ b/a.txt\n@@ -1,2 +1,2 @@\n one\n-two\n\\ No newline at end of file\n+two\n"
+	if got != want {
+		t.Errorf("no newline:\n%s\nwant\n%s", got, want)
+	}
+
+	got = Unified(File{Path: "new.txt", After: "hello\n"}, 3)
+	want = "--- /dev/null\n+++ b/new.txt\n@@ -0,0 +1 @@\n+hello\n"
+	if got != want {
+		t.Errorf("create:\n%s\nwant\n%s", got, want)
+	}
+
+	got = Unified(File{Path: "old.txt", Before: "a\nb\n"}, 3)
+	want = "--- a/old.txt\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-a\n-b\n"
+	if got != want {
+		t.Errorf("delete:\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHunks_RoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1)) //nolint:gosec // deterministic test input
+	words := []string{"a\n", "b\n", "c\n", "d\n", "e"}
+	random := func() string {
+		var b strings.Builder
+		for n := rng.Intn(30); n > 0; n-- {
+			b.WriteString(words[rng.Intn(len(words))])
+		}
+		return b.String()
+	}
+
+	for i := 0; i < 500; i++ {
+		f := File{Path: "f", Before: random(), After: random()}
+		got, err := applyHunks(f.Before, f.Hunks(rng.Intn(4)))
+		if err != nil {
+			t.Fatalf("case %d: %v\n%s", i, err, Unified(f, 3))
+		}
+		if got != f.After {
+			t.Fatalf("case %d: applying hunks gave %q, want %q", i, got, f.After)
+		}
+
+		// Myers finds a shortest edit script, so it can never be longer
+		// than replacing everything that differs.
+		add, del := f.Stats()
+		if add > len(textlines.Split(f.After)) || del > len(textlines.Split(f.Before)) {
+			t.Fatalf("case %d: stats +%d -%d exceed file lengths", i, add, del)
+		}
+	}
+}
+
+func TestWriteColor(t *testing.T) {
+	var buf bytes.Buffer
+	files := []File{{Path: "a.txt", Before: "old\nsame\n", After: "new\nsame\n"}}
+	if err := WriteColor(&buf, files, 3); err != nil {
+		t.Fatal(err)
+	}
+	want := ansiBold + "--- a/a.txt" + ansiReset + "\n" +
+		ansiBold + "+++ b/a.txt" + ansiReset + "\n" +
+		ansiCyan + "@@ -1,2 +1,2 @@" + ansiReset + "\n" +
+		ansiRed + "-old" + ansiReset + "\n" +
+		ansiGreen + "+new" + ansiReset + "\n" +
+		" same\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteColor = %q, want %q", got, want)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	files := []File{
+		{Path: "a.txt", Before: "one\n<two>\nthree\n", After: "one\n2\nthree\nfour\n"},
+		{Path: "same.txt", Before: "x\n", After: "x\n"},
+	}
+	if err := WriteHTML(&buf, files, 3); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<tr class="change"><td class="num">2</td><td class="old">&lt;two&gt;</td><td class="num">2</td><td class="new">2</td></tr>`,
+		`<tr class="insert"><td class="num"></td><td class="old empty"></td><td class="num">4</td><td class="new">four</td></tr>`,
+		`<span class="add">+2</span> <span class="del">-1</span>`,
+		"No changes.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML missing %q", want)
+		}
+	}
+}
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func readFile(t *testing.T, root, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, path)) //nolint:gosec // test fixture path
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestApply(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"clean.txt":   "a\nb\nc\n",
+		"done.txt":    "new\n",
+		"gone.txt":    "bye\n",
+		"shifted.txt": "header\nheader\na\nb\nc\nd\ne\nf\ng\nh\ni\nj\n",
+	})
+	files := []File{
+		{Path: "clean.txt", Before: "a\nb\nc\n", After: "a\nB\nc\n"},
+		{Path: "done.txt", Before: "old\n", After: "new\n"},
+		{Path: "gone.txt", Before: "bye\n"},
+		{Path: "dir/created.txt", After: "hello\n"},
+		// Two lines were added above the change since the diff was taken.
+		{Path: "shifted.txt", Before: "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n", After: "a\nb\nc\nd\nE\nf\ng\nh\ni\nj\n"},
+	}
+
+	result, err := Apply(root, files, nil)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if len(result.Applied) != 4 || len(result.Merged) != 1 || result.Merged[0] != "shifted.txt" {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Unchanged) != 1 || result.Unchanged[0] != "done.txt" {
+		t.Errorf("Unchanged = %v", result.Unchanged)
+	}
+
+	if got := readFile(t, root, "clean.txt"); got != "a\nB\nc\n" {
+		t.Errorf("clean.txt = %q", got)
+	}
+	if got := readFile(t, root, "dir/created.txt"); got != "hello\n" {
+		t.Errorf("created.txt = %q", got)
+	}
+	if got := readFile(t, root, "shifted.txt"); got != "header\nheader\na\nb\nc\nd\nE\nf\ng\nh\ni\nj\n" {
+		t.Errorf("shifted.txt = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(root, "gone.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("gone.txt still exists: %v", err)
+	}
+
+	// Applying again is a no-op.
+	result, err = Apply(root, files, nil)
+	if err != nil {
+		t.Fatalf("second Apply: %v", err)
+	}
+	if len(result.Applied) != 0 || len(result.Unchanged) != len(files) {
+		t.Errorf("second result = %+v", result)
+	}
+}
+
+func TestApply_ConflictWritesNothing(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"ok.txt":       "a\n",
+		"conflict.txt": "x\nlocally edited\nz\n",
+	})
+	files := []File{
+		{Path: "ok.txt", Before: "a\n", After: "A\n"},
+		{Path: "conflict.txt", Before: "x\ny\nz\n", After: "x\nY\nz\n"},
+		{Path: "../escape.txt", After: "nope\n"},
+		{Path: "missing.txt", Before: "m\n", After: "M\n"},
+	}
+
+	result, err := Apply(root, files, nil)
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("err = %v, want ErrConflict", err)
+	}
+	if len(result.Conflicts) != 3 {
+		t.Errorf("Conflicts = %v", result.Conflicts)
+	}
+	if got := readFile(t, root, "ok.txt"); got != "a\n" {
+		t.Errorf("ok.txt was written despite conflicts: %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(root), "escape.txt")); err == nil {
+		t.Error("file written outside root")
+	}
+}
+
+func TestApply_DryRun(t *testing.T) {
+	root := writeTree(t, map[string]string{"a.txt": "a\n"})
+	result, err := Apply(root, []File{{Path: "a.txt", Before: "a\n", After: "b\n"}}, &ApplyOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Applied) != 1 {
+		t.Errorf("Applied = %v", result.Applied)
+	}
+	if got := readFile(t, root, "a.txt"); got != "a\n" {
+		t.Errorf("dry run wrote a.txt: %q", got)
+	}
+}
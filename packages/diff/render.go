@@ -0,0 +1,183 @@
+package diff
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// ANSI escape sequences used by WriteColor.
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// WriteColor writes files as unified diffs colored with ANSI escapes for a
+// terminal: file headers bold, hunk headers cyan, removals red and additions
+// green.
+func WriteColor(w io.Writer, files []File, context int) error {
+	var b strings.Builder
+	for _, f := range files {
+		unified := Unified(f, context)
+		for _, line := range strings.SplitAfter(unified, "\n") {
+			if line == "" {
+				continue
+			}
+			text := strings.TrimSuffix(line, "\n")
+			switch {
+			case strings.HasPrefix(text, "--- "), strings.HasPrefix(text, "+++ "):
+				b.WriteString(ansiBold + text + ansiReset)
+			case strings.HasPrefix(text, "@@"):
+				b.WriteString(ansiCyan + text + ansiReset)
+			case strings.HasPrefix(text, "-"):
+				b.WriteString(ansiRed + text + ansiReset)
+			case strings.HasPrefix(text, "+"):
+				b.WriteString(ansiGreen + text + ansiReset)
+			default:
+				b.WriteString(text)
+			}
+			b.WriteByte('\n')
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Row is one line of a side-by-side view. A side is empty when the line
+// exists only on the other side; Old and New are 0 there.
+type Row struct {
+	Old, New         int
+	OldText, NewText string
+	Kind             RowKind
+}
+
+// RowKind classifies a side-by-side row.
+type RowKind string
+
+const (
+	RowContext RowKind = "context"
+	RowDelete  RowKind = "delete"
+	RowInsert  RowKind = "insert"
+	RowChange  RowKind = "change"
+)
+
+// SideBySide pairs the lines of a hunk for a two-column view. Runs of
+// removals followed by additions share rows, so a changed line sits next to
+// its replacement.
+func (h Hunk) SideBySide() []Row {
+	var rows []Row
+	for i := 0; i < len(h.Lines); {
+		line := h.Lines[i]
+		if line.Kind == LineContext {
+			rows = append(rows, Row{Old: line.OldNumber, New: line.NewNumber, OldText: line.Text, NewText: line.Text, Kind: RowContext})
+			i++
+			continue
+		}
+
+		var dels, ins []Line
+		for i < len(h.Lines) && h.Lines[i].Kind == LineDelete {
+			dels = append(dels, h.Lines[i])
+			i++
+		}
+		for i < len(h.Lines) && h.Lines[i].Kind == LineInsert {
+			ins = append(ins, h.Lines[i])
+			i++
+		}
+		for j := 0; j < max(len(dels), len(ins)); j++ {
+			var row Row
+			switch {
+			case j < len(dels) && j < len(ins):
+				row = Row{Old: dels[j].OldNumber, New: ins[j].NewNumber, OldText: dels[j].Text, NewText: ins[j].Text, Kind: RowChange}
+			case j < len(dels):
+				row = Row{Old: dels[j].OldNumber, OldText: dels[j].Text, Kind: RowDelete}
+			default:
+				row = Row{New: ins[j].NewNumber, NewText: ins[j].Text, Kind: RowInsert}
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+type htmlFile struct {
+	Path      string
+	Additions int
+	Deletions int
+	Hunks     []htmlHunk
+	Identical bool
+	Status    string
+}
+
+type htmlHunk struct {
+	Header string
+	Rows   []Row
+}
+
+// WriteHTML writes files as a self-contained HTML page with a side-by-side
+// table per file.
+func WriteHTML(w io.Writer, files []File, context int) error {
+	var data []htmlFile
+	for _, f := range files {
+		hf := htmlFile{Path: f.Path, Status: "modified"}
+		switch {
+		case f.Before == "" && f.After != "":
+			hf.Status = "added"
+		case f.After == "" && f.Before != "":
+			hf.Status = "deleted"
+		}
+		hf.Additions, hf.Deletions = f.Stats()
+		for _, h := range f.Hunks(context) {
+			hf.Hunks = append(hf.Hunks, htmlHunk{Header: h.Header(), Rows: h.SideBySide()})
+		}
+		hf.Identical = len(hf.Hunks) == 0
+		data = append(data, hf)
+	}
+	if err := htmlTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("diff: render html: %w", err)
+	}
+	return nil
+}
+
+var htmlTemplate = template.Must(template.New("diff").Funcs(template.FuncMap{
+	"lineno": func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return fmt.Sprint(n)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Diff</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2rem; color: #1f2328; }
+section { border: 1px solid #d0d7de; border-radius: 6px; margin-bottom: 1.5rem; overflow: hidden; }
+h2 { font-size: 0.95rem; margin: 0; padding: 0.5rem 0.75rem; background: #f6f8fa; border-bottom: 1px solid #d0d7de; }
+h2 .stat { font-weight: normal; margin-left: 0.75rem; }
+.add { color: #1a7f37; } .del { color: #cf222e; } .status { color: #656d76; font-weight: normal; margin-left: 0.5rem; }
+table { border-collapse: collapse; width: 100%; table-layout: fixed; font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 12px; }
+td { padding: 0 0.5rem; vertical-align: top; white-space: pre-wrap; word-break: break-all; }
+td.num { width: 3.5rem; text-align: right; color: #656d76; user-select: none; }
+tr.hunk td { background: #ddf4ff; color: #656d76; padding: 0.25rem 0.5rem; }
+.delete .old, .change .old { background: #ffebe9; }
+.insert .new, .change .new { background: #dafbe1; }
+.empty { background: #f6f8fa; }
+p.same { padding: 0.5rem 0.75rem; margin: 0; color: #656d76; }
+</style>
+</head>
+<body>
+{{range .}}<section>
+<h2>{{.Path}}<span class="status">{{.Status}}</span><span class="stat"><span class="add">+{{.Additions}}</span> <span class="del">-{{.Deletions}}</span></span></h2>
+{{if .Identical}}<p class="same">No changes.</p>{{else}}<table>
+{{range .Hunks}}<tr class="hunk"><td colspan="4">{{.Header}}</td></tr>
+{{range .Rows}}<tr class="{{.Kind}}"><td class="num">{{lineno .Old}}</td><td class="old{{if eq .Kind "insert"}} empty{{end}}">{{.OldText}}</td><td class="num">{{lineno .New}}</td><td class="new{{if eq .Kind "delete"}} empty{{end}}">{{.NewText}}</td></tr>
+{{end}}{{end}}</table>{{end}}
+</section>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,161 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrEmptyQuery is returned by Index.Search when the query text contains no
+// searchable terms.
+var ErrEmptyQuery = errors.New("search: empty query")
+
+// Query selects parts from an Index.
+//
+// Text holds whitespace-separated terms and double-quoted phrases, all of
+// which must match. Matching is case-insensitive and ignores punctuation, so
+// a term such as billing.go matches the words "billing" and "go" next to each
+// other, as in "internal/billing.go".
+type Query struct {
+	Text string
+	// SessionIDs restricts hits to these sessions. Empty means all.
+	SessionIDs []string
+	// Kinds restricts hits to these part kinds. Empty means all.
+	Kinds []Kind
+	// After and Before bound the creation time of the message containing
+	// the part. When either is set, parts of messages the index has no
+	// time for are excluded.
+	After  time.Time
+	Before time.Time
+	// Limit caps the number of hits. Zero returns all of them.
+	Limit int
+}
+
+func (q Query) allows(doc *document, created time.Time) bool {
+	if len(q.SessionIDs) > 0 && !contains(q.SessionIDs, doc.sessionID) {
+		return false
+	}
+	if len(q.Kinds) > 0 && !contains(q.Kinds, doc.kind) {
+		return false
+	}
+	if !q.After.IsZero() || !q.Before.IsZero() {
+		if created.IsZero() {
+			return false
+		}
+		if !q.After.IsZero() && !created.After(q.After) {
+			return false
+		}
+		if !q.Before.IsZero() && !created.Before(q.Before) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains[T comparable](values []T, v T) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// parseQuery splits query text into clauses, each a sequence of terms that
+// must appear consecutively.
+func parseQuery(text string) ([][]string, error) {
+	var clauses [][]string
+	add := func(s string) {
+		var terms []string
+		for _, tok := range tokenize(s) {
+			terms = append(terms, tok.term)
+		}
+		if len(terms) > 0 {
+			clauses = append(clauses, terms)
+		}
+	}
+
+	rest := text
+	for {
+		open := strings.IndexByte(rest, '"')
+		if open < 0 {
+			for _, field := range strings.Fields(rest) {
+				add(field)
+			}
+			break
+		}
+		for _, field := range strings.Fields(rest[:open]) {
+			add(field)
+		}
+		end := strings.IndexByte(rest[open+1:], '"')
+		if end < 0 {
+			return nil, errors.New("search: unterminated quote in query")
+		}
+		add(rest[open+1 : open+1+end])
+		rest = rest[open+1+end+1:]
+	}
+
+	if len(clauses) == 0 {
+		return nil, ErrEmptyQuery
+	}
+	return clauses, nil
+}
+
+// matchClauses reports whether every clause occurs in doc, and the token
+// index of the first clause's first occurrence.
+func matchClauses(doc *document, clauses [][]string) (int, bool) {
+	first := -1
+	for _, clause := range clauses {
+		at := findPhrase(doc.tokens, clause)
+		if at < 0 {
+			return 0, false
+		}
+		if first < 0 {
+			first = at
+		}
+	}
+	return first, true
+}
+
+func findPhrase(tokens []token, terms []string) int {
+	for i := 0; i+len(terms) <= len(tokens); i++ {
+		match := true
+		for j, term := range terms {
+			if tokens[i+j].term != term {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+type token struct {
+	term  string
+	start int
+}
+
+// tokenize splits text into lowercase runs of letters and digits, recording
+// the byte offset of each.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case word && start < 0:
+			start = i
+		case !word && start >= 0:
+			tokens = append(tokens, token{term: strings.ToLower(text[start:i]), start: start})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{term: strings.ToLower(text[start:]), start: start})
+	}
+	return tokens
+}
@@ -0,0 +1,450 @@
+// Package search is an in-memory full-text index over opencode session
+// messages. It indexes text, reasoning and tool parts, stays current by
+// applying message events, and answers term and phrase queries with hits that
+// carry the session, message and part IDs needed to link back to the source.
+//
+//	ix := search.NewIndex()
+//	err := ix.IndexSession(ctx, client, sessionID, nil)
+//	hits, err := ix.Search(search.Query{Text: `billing.go "rate limit"`})
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	opencode "github.com/dominicnunez/opencode-sdk-go"
+	"github.com/dominicnunez/opencode-sdk-go/internal/mstime"
+)
+
+// Kind is the type of part a document was built from.
+type Kind string
+
+const (
+	KindText      Kind = "text"
+	KindReasoning Kind = "reasoning"
+	KindTool      Kind = "tool"
+)
+
+// Hit is one part matching a query.
+type Hit struct {
+	SessionID string
+	MessageID string
+	PartID    string
+	Kind      Kind
+	// Tool is the tool name for KindTool hits.
+	Tool string
+	// Time is when the message containing the part was created, or zero if
+	// the index has not seen the message.
+	Time    time.Time
+	Score   float64
+	Snippet string
+}
+
+type document struct {
+	sessionID string
+	messageID string
+	partID    string
+	kind      Kind
+	tool      string
+	text      string
+	tokens    []token
+	freqs     map[string]int
+}
+
+// Index is a full-text index over session parts. It is safe for concurrent
+// use, so one goroutine can apply events while others search.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*document
+	postings map[string]map[string]struct{}
+	// created holds message creation times, keyed by message ID.
+	created map[string]time.Time
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[string]*document),
+		postings: make(map[string]map[string]struct{}),
+		created:  make(map[string]time.Time),
+	}
+}
+
+// Len returns the number of indexed parts.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// IndexSession fetches every message of a session and indexes it, replacing
+// whatever the index held for that session. Messages are decoded one at a
+// time, so long sessions are not subject to the client's body size limit.
+func (ix *Index) IndexSession(ctx context.Context, client *opencode.Client, sessionID string, directory *string) error {
+	if client == nil {
+		return fmt.Errorf("search: client is required")
+	}
+	messages := client.Session.MessagesStreaming(ctx, sessionID, &opencode.SessionMessagesParams{Directory: directory})
+	defer func() { _ = messages.Close() }()
+
+	var all []opencode.SessionMessagesResponse
+	for messages.Next() {
+		all = append(all, messages.Current())
+	}
+	if err := messages.Err(); err != nil {
+		return fmt.Errorf("search: messages for session %s: %w", sessionID, err)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.removeWhere(func(d *document) bool { return d.sessionID == sessionID })
+	ix.addMessages(all)
+	return nil
+}
+
+// AddMessages indexes messages as returned by Session.Messages, replacing
+// any parts already indexed under the same IDs.
+func (ix *Index) AddMessages(messages []opencode.SessionMessagesResponse) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.addMessages(messages)
+}
+
+func (ix *Index) addMessages(messages []opencode.SessionMessagesResponse) {
+	for _, msg := range messages {
+		ix.recordMessage(msg.Info)
+		for _, part := range msg.Parts {
+			ix.addPart(part)
+		}
+	}
+}
+
+// Apply updates the index from an event and reports whether it changed
+// anything. It handles message.updated, message.part.updated,
+// message.part.removed, message.removed and session.deleted; other events are
+// ignored. Feed it the stream from Event.ListStreaming to keep an index built
+// with IndexSession current.
+func (ix *Index) Apply(evt opencode.Event) bool {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	switch evt.Type {
+	case opencode.EventTypeMessageUpdated:
+		e, err := evt.AsMessageUpdated()
+		if err != nil {
+			return false
+		}
+		return ix.recordMessage(e.Data.Info)
+	case opencode.EventTypeMessagePartUpdated:
+		e, err := evt.AsMessagePartUpdated()
+		if err != nil {
+			return false
+		}
+		return ix.addPart(e.Data.Part)
+	case opencode.EventTypeMessagePartRemoved:
+		e, err := evt.AsMessagePartRemoved()
+		if err != nil {
+			return false
+		}
+		return ix.removeDoc(e.Data.PartID)
+	case opencode.EventTypeMessageRemoved:
+		e, err := evt.AsMessageRemoved()
+		if err != nil {
+			return false
+		}
+		delete(ix.created, e.Data.MessageID)
+		return ix.removeWhere(func(d *document) bool { return d.messageID == e.Data.MessageID })
+	case opencode.EventTypeSessionDeleted:
+		e, err := evt.AsSessionDeleted()
+		if err != nil {
+			return false
+		}
+		return ix.removeWhere(func(d *document) bool { return d.sessionID == e.Data.Info.ID })
+	}
+	return false
+}
+
+// recordMessage remembers a message's creation time for hit times and
+// filters. It reports whether the time is new to the index.
+func (ix *Index) recordMessage(msg opencode.Message) bool {
+	var ms float64
+	switch msg.Role {
+	case opencode.MessageRoleUser:
+		if user, err := msg.AsUser(); err == nil {
+			ms = user.Time.Created
+		}
+	case opencode.MessageRoleAssistant:
+		if assistant, err := msg.AsAssistant(); err == nil {
+			ms = assistant.Time.Created
+		}
+	}
+	created := mstime.Time(ms)
+	if created.IsZero() {
+		return false
+	}
+	if prev, ok := ix.created[msg.ID]; ok && prev.Equal(created) {
+		return false
+	}
+	ix.created[msg.ID] = created
+	return true
+}
+
+// addPart indexes a searchable part, replacing any previous version. It
+// reports whether the index changed.
+func (ix *Index) addPart(part opencode.Part) bool {
+	removed := ix.removeDoc(part.ID)
+	doc := buildDocument(part)
+	if doc == nil {
+		return removed
+	}
+	ix.docs[doc.partID] = doc
+	for term := range doc.freqs {
+		ids := ix.postings[term]
+		if ids == nil {
+			ids = make(map[string]struct{})
+			ix.postings[term] = ids
+		}
+		ids[doc.partID] = struct{}{}
+	}
+	return true
+}
+
+// removeWhere drops every document matching fn and reports whether any was
+// dropped.
+func (ix *Index) removeWhere(fn func(*document) bool) bool {
+	removed := false
+	for id, doc := range ix.docs {
+		if fn(doc) {
+			removed = ix.removeDoc(id) || removed
+		}
+	}
+	return removed
+}
+
+func (ix *Index) removeDoc(id string) bool {
+	doc, ok := ix.docs[id]
+	if !ok {
+		return false
+	}
+	for term := range doc.freqs {
+		ids := ix.postings[term]
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+	delete(ix.docs, id)
+	return true
+}
+
+func buildDocument(part opencode.Part) *document {
+	doc := &document{sessionID: part.SessionID, messageID: part.MessageID, partID: part.ID}
+	switch part.Type {
+	case opencode.PartTypeText:
+		text, err := part.AsText()
+		if err != nil {
+			return nil
+		}
+		doc.kind, doc.text = KindText, text.Text
+	case opencode.PartTypeReasoning:
+		reasoning, err := part.AsReasoning()
+		if err != nil {
+			return nil
+		}
+		doc.kind, doc.text = KindReasoning, reasoning.Text
+	case opencode.PartTypeTool:
+		tool, err := part.AsTool()
+		if err != nil {
+			return nil
+		}
+		doc.kind, doc.tool, doc.text = KindTool, tool.Tool, toolText(tool)
+	default:
+		return nil
+	}
+	if strings.TrimSpace(doc.text) == "" {
+		return nil
+	}
+	doc.tokens = tokenize(doc.text)
+	doc.freqs = make(map[string]int, len(doc.tokens))
+	for _, tok := range doc.tokens {
+		doc.freqs[tok.term]++
+	}
+	return doc
+}
+
+// toolText flattens a tool call into searchable text: the tool name, its
+// title, the string values of its input, and its output or error.
+func toolText(tool *opencode.ToolPart) string {
+	lines := []string{tool.Tool}
+	var input interface{}
+	var result string
+	switch tool.State.Status {
+	case opencode.ToolPartStateStatusRunning:
+		if state, err := tool.State.AsRunning(); err == nil {
+			lines = append(lines, state.Title)
+			input = state.Input
+		}
+	case opencode.ToolPartStateStatusCompleted:
+		if state, err := tool.State.AsCompleted(); err == nil {
+			lines = append(lines, state.Title)
+			input, result = state.Input, state.Output
+		}
+	case opencode.ToolPartStateStatusError:
+		if state, err := tool.State.AsError(); err == nil {
+			input, result = state.Input, state.Error
+		}
+	}
+	lines = appendStrings(lines, input)
+	lines = append(lines, result)
+	return strings.Join(lines, "\n")
+}
+
+func appendStrings(dst []string, v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return append(dst, v)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			dst = appendStrings(dst, v[k])
+		}
+	case []interface{}:
+		for _, item := range v {
+			dst = appendStrings(dst, item)
+		}
+	case json.Number:
+		return append(dst, v.String())
+	}
+	return dst
+}
+
+// Search returns the parts matching q, best first. Every term and phrase in
+// q.Text must occur in a part for it to match. Parts are scored by TF-IDF;
+// ties go to the more recent message.
+func (ix *Index) Search(q Query) ([]Hit, error) {
+	clauses, err := parseQuery(q.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var hits []Hit
+	for _, id := range ix.candidates(clauses) {
+		doc := ix.docs[id]
+		created := ix.created[doc.messageID]
+		if !q.allows(doc, created) {
+			continue
+		}
+		first, ok := matchClauses(doc, clauses)
+		if !ok {
+			continue
+		}
+		hits = append(hits, Hit{
+			SessionID: doc.sessionID,
+			MessageID: doc.messageID,
+			PartID:    doc.partID,
+			Kind:      doc.kind,
+			Tool:      doc.tool,
+			Time:      created,
+			Score:     ix.score(doc, clauses),
+			Snippet:   snippet(doc.text, doc.tokens[first].start),
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if !hits[i].Time.Equal(hits[j].Time) {
+			return hits[i].Time.After(hits[j].Time)
+		}
+		return hits[i].PartID < hits[j].PartID
+	})
+	if q.Limit > 0 && len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	return hits, nil
+}
+
+// candidates returns the IDs of documents containing every query term,
+// starting from the rarest term's postings.
+func (ix *Index) candidates(clauses [][]string) []string {
+	var terms []string
+	for _, clause := range clauses {
+		terms = append(terms, clause...)
+	}
+	sort.Slice(terms, func(i, j int) bool { return len(ix.postings[terms[i]]) < len(ix.postings[terms[j]]) })
+
+	var ids []string
+	for id := range ix.postings[terms[0]] {
+		ok := true
+		for _, term := range terms[1:] {
+			if _, found := ix.postings[term][id]; !found {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (ix *Index) score(doc *document, clauses [][]string) float64 {
+	n := float64(len(ix.docs))
+	var score float64
+	for _, clause := range clauses {
+		for _, term := range clause {
+			idf := math.Log(1 + n/float64(len(ix.postings[term])))
+			score += float64(doc.freqs[term]) / float64(len(doc.tokens)) * idf
+		}
+	}
+	return score
+}
+
+const snippetRadius = 60
+
+// snippet returns the text around byte offset at, on rune boundaries and
+// with whitespace collapsed.
+func snippet(text string, at int) string {
+	start := at - snippetRadius
+	if start < 0 {
+		start = 0
+	}
+	end := at + 2*snippetRadius
+	if end > len(text) {
+		end = len(text)
+	}
+	for start > 0 && !isRuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !isRuneStart(text[end]) {
+		end++
+	}
+
+	out := strings.Join(strings.Fields(text[start:end]), " ")
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(text) {
+		out += "…"
+	}
+	return out
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
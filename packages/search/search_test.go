@@ -0,0 +1,234 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	opencode "github.com/dominicnunez/opencode-sdk-go"
+)
+
+const messagesFixture = `[
+	{"info":{"id":"msg_1","role":"user","sessionID":"ses_1","time":{"created":1700000000000}},"parts":[
+		{"id":"prt_1","messageID":"msg_1","sessionID":"ses_1","type":"text","text":"The invoice totals in billing are off by a cent."}
+	]},
+	{"info":{"id":"msg_2","role":"assistant","sessionID":"ses_1","time":{"created":1700000060000}},"parts":[
+		{"id":"prt_2","messageID":"msg_2","sessionID":"ses_1","type":"reasoning","text":"Rounding happens before the rate limit check.","time":{"start":1}},
+		{"id":"prt_3","messageID":"msg_2","sessionID":"ses_1","type":"tool","tool":"edit","callID":"call_1","state":{"status":"completed","input":{"filePath":"/src/internal/billing.go","oldString":"math.Floor","newString":"math.Round"},"output":"Edit applied","title":"internal/billing.go","metadata":{},"time":{"start":1,"end":2}}},
+		{"id":"prt_4","messageID":"msg_2","sessionID":"ses_1","type":"step-finish","reason":"stop","cost":0,"tokens":{"input":1,"output":1,"reasoning":0,"cache":{"read":0,"write":0}}},
+		{"id":"prt_5","messageID":"msg_2","sessionID":"ses_1","type":"text","text":"Billing now rounds instead of flooring."}
+	]}
+]`
+
+const otherFixture = `[
+	{"info":{"id":"msg_9","role":"user","sessionID":"ses_2","time":{"created":1700000100000}},"parts":[
+		{"id":"prt_9","messageID":"msg_9","sessionID":"ses_2","type":"text","text":"Add a rate limit to the billing API."}
+	]}
+]`
+
+func loadMessages(t *testing.T, raw string) []opencode.SessionMessagesResponse {
+	t.Helper()
+	var msgs []opencode.SessionMessagesResponse
+	if err := json.Unmarshal([]byte(raw), &msgs); err != nil {
+		t.Fatalf("unmarshal messages: %v", err)
+	}
+	return msgs
+}
+
+func mustEvent(t *testing.T, raw string) opencode.Event {
+	t.Helper()
+	var evt opencode.Event
+	if err := json.Unmarshal([]byte(raw), &evt); err != nil {
+		t.Fatalf("unmarshal event: %v", err)
+	}
+	return evt
+}
+
+func fixtureIndex(t *testing.T) *Index {
+	t.Helper()
+	ix := NewIndex()
+	ix.AddMessages(loadMessages(t, messagesFixture))
+	ix.AddMessages(loadMessages(t, otherFixture))
+	return ix
+}
+
+func hitIDs(hits []Hit) string {
+	ids := make([]string, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.PartID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestSearch(t *testing.T) {
+	ix := fixtureIndex(t)
+	if ix.Len() != 5 {
+		t.Fatalf("expected 5 indexed parts, got %d", ix.Len())
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"billing.go", "prt_3"},
+		{"BILLING", "prt_5,prt_3,prt_9,prt_1"},
+		{`"rate limit"`, "prt_2,prt_9"},
+		{`"limit rate"`, ""},
+		{`billing "rate limit"`, "prt_9"},
+		{"math.Round", "prt_3"},
+		{"applied", "prt_3"},
+		{"nonexistent", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			hits, err := ix.Search(Query{Text: tt.query})
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if got := hitIDs(hits); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearch_HitDetails(t *testing.T) {
+	hits, err := fixtureIndex(t).Search(Query{Text: "billing.go"})
+	if err != nil || len(hits) != 1 {
+		t.Fatalf("expected one hit, got %v (%v)", hits, err)
+	}
+	hit := hits[0]
+	if hit.SessionID != "ses_1" || hit.MessageID != "msg_2" || hit.Kind != KindTool || hit.Tool != "edit" {
+		t.Errorf("unexpected hit %+v", hit)
+	}
+	if !hit.Time.Equal(time.UnixMilli(1700000060000)) {
+		t.Errorf("unexpected time %v", hit.Time)
+	}
+	if !strings.Contains(hit.Snippet, "billing.go") || hit.Score <= 0 {
+		t.Errorf("unexpected snippet %q or score %v", hit.Snippet, hit.Score)
+	}
+}
+
+func TestSearch_Filters(t *testing.T) {
+	ix := fixtureIndex(t)
+
+	tests := []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{"session", Query{Text: "billing", SessionIDs: []string{"ses_2"}}, "prt_9"},
+		{"kind", Query{Text: "billing", Kinds: []Kind{KindText}}, "prt_5,prt_9,prt_1"},
+		{"after", Query{Text: "billing", After: time.UnixMilli(1700000000000)}, "prt_5,prt_3,prt_9"},
+		{"before", Query{Text: "billing", Before: time.UnixMilli(1700000060000)}, "prt_1"},
+		{"limit", Query{Text: "billing", Limit: 2}, "prt_5,prt_3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := ix.Search(tt.query)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if got := hitIDs(hits); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearch_InvalidQuery(t *testing.T) {
+	ix := fixtureIndex(t)
+	if _, err := ix.Search(Query{Text: "  ... "}); !errors.Is(err, ErrEmptyQuery) {
+		t.Errorf("expected ErrEmptyQuery, got %v", err)
+	}
+	if _, err := ix.Search(Query{Text: `"rate limit`}); err == nil {
+		t.Error("expected unterminated quote error")
+	}
+}
+
+func TestIndex_Apply(t *testing.T) {
+	ix := fixtureIndex(t)
+
+	changed := ix.Apply(mustEvent(t, `{"type":"message.part.updated","properties":{"part":
+		{"id":"prt_5","messageID":"msg_2","sessionID":"ses_1","type":"text","text":"Ledger rounding fixed."}}}`))
+	if !changed {
+		t.Fatal("expected part update to change the index")
+	}
+	if hits, _ := ix.Search(Query{Text: "ledger"}); hitIDs(hits) != "prt_5" {
+		t.Errorf("expected updated part to be searchable, got %s", hitIDs(hits))
+	}
+	if hits, _ := ix.Search(Query{Text: "flooring"}); len(hits) != 0 {
+		t.Errorf("expected old text to be gone, got %s", hitIDs(hits))
+	}
+
+	ix.Apply(mustEvent(t, `{"type":"message.updated","properties":{"info":{"id":"msg_3","role":"assistant","sessionID":"ses_1","time":{"created":1700000200000}}}}`))
+	ix.Apply(mustEvent(t, `{"type":"message.part.updated","properties":{"part":
+		{"id":"prt_6","messageID":"msg_3","sessionID":"ses_1","type":"text","text":"ledger totals reconciled"}}}`))
+	hits, _ := ix.Search(Query{Text: "ledger", After: time.UnixMilli(1700000100000)})
+	if hitIDs(hits) != "prt_6" {
+		t.Errorf("expected new part with message time, got %s", hitIDs(hits))
+	}
+
+	ix.Apply(mustEvent(t, `{"type":"message.part.removed","properties":{"sessionID":"ses_1","messageID":"msg_3","partID":"prt_6"}}`))
+	ix.Apply(mustEvent(t, `{"type":"message.removed","properties":{"sessionID":"ses_1","messageID":"msg_1"}}`))
+	for _, text := range []string{"reconciled", "invoice"} {
+		if hits, _ := ix.Search(Query{Text: text}); len(hits) != 0 {
+			t.Errorf("expected removed parts to be gone, got %s for %q", hitIDs(hits), text)
+		}
+	}
+
+	ix.Apply(mustEvent(t, `{"type":"session.deleted","properties":{"info":{"id":"ses_2"}}}`))
+	if hits, _ := ix.Search(Query{Text: "billing"}); strings.Contains(hitIDs(hits), "prt_9") {
+		t.Errorf("expected deleted session to be gone, got %s", hitIDs(hits))
+	}
+	if ix.Apply(mustEvent(t, `{"type":"session.idle","properties":{"sessionID":"ses_1"}}`)) {
+		t.Error("expected unrelated event to be ignored")
+	}
+}
+
+func TestIndex_IndexSession(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/session/ses_1/message" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(messagesFixture))
+	}))
+	defer server.Close()
+	client, err := opencode.NewClient(opencode.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ix := NewIndex()
+	ix.Apply(mustEvent(t, `{"type":"message.part.updated","properties":{"part":
+		{"id":"prt_stale","messageID":"msg_0","sessionID":"ses_1","type":"text","text":"stale billing note"}}}`))
+	if err := ix.IndexSession(context.Background(), client, "ses_1", nil); err != nil {
+		t.Fatalf("IndexSession: %v", err)
+	}
+	if ix.Len() != 4 {
+		t.Errorf("expected session contents to be replaced, got %d parts", ix.Len())
+	}
+
+	if err := ix.IndexSession(context.Background(), client, "ses_missing", nil); !errors.Is(err, opencode.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	text := strings.Repeat("é", 100) + " needle " + strings.Repeat("ü", 200)
+	got := snippet(text, strings.Index(text, "needle"))
+	if !utf8.ValidString(got) || !strings.Contains(got, "needle") {
+		t.Errorf("unexpected snippet %q", got)
+	}
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("expected ellipses on both ends, got %q", got)
+	}
+}
@@ -0,0 +1,382 @@
+package ssestream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// recordedAtPrefix marks the comment line that carries an event's receive
+// time in RecordFormatSSE recordings.
+const recordedAtPrefix = "recorded-at "
+
+// RecordFormat selects the layout written by NewRecorder and read by
+// NewReplayDecoder.
+type RecordFormat int
+
+const (
+	// RecordFormatJSONL writes one RecordedEvent JSON object per line. Only
+	// each event's type and data are kept; ids, retry hints and comments are
+	// dropped.
+	RecordFormatJSONL RecordFormat = iota
+	// RecordFormatSSE writes text/event-stream frames, each preceded by a
+	// ": recorded-at <RFC 3339 time>" comment. Any SSE parser can read the
+	// file; the timestamp comments are only interpreted during replay.
+	// Recordings made with WithRawRecorder hold the stream unparsed, plus
+	// the timestamp comments; NewRecorder re-encodes the decoded events
+	// instead.
+	RecordFormatSSE
+)
+
+// RecordedEvent is one line of a RecordFormatJSONL recording.
+type RecordedEvent struct {
+	Time time.Time `json:"time"`
+	Type string    `json:"event,omitempty"`
+	Data string    `json:"data"`
+}
+
+// RecordOption configures a decoder returned by NewRecorder.
+type RecordOption func(*recordingDecoder)
+
+// WithRecordFormat selects the recording layout. The default is
+// RecordFormatJSONL.
+func WithRecordFormat(format RecordFormat) RecordOption {
+	return func(r *recordingDecoder) {
+		r.format = format
+	}
+}
+
+// NewRecorder returns a Decoder that passes every event from dec through
+// unchanged while appending it, stamped with its receive time, to w. A failed
+// write stops the stream and is reported by Err, so a recording is never
+// silently truncated. Closing the returned decoder closes dec but not w.
+//
+// NewRecorder sees only decoded events, so it records their type and data
+// and nothing else. Use WithRawRecorder to record a text/event-stream
+// response unparsed, plus timestamp comments.
+func NewRecorder(dec Decoder, w io.Writer, opts ...RecordOption) Decoder {
+	if dec == nil {
+		dec = &nilDecoder{}
+	}
+	r := &recordingDecoder{dec: dec, w: w, now: time.Now}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+type recordingDecoder struct {
+	dec    Decoder
+	w      io.Writer
+	format RecordFormat
+	now    func() time.Time
+	err    error
+}
+
+func (r *recordingDecoder) Next() bool {
+	if r.err != nil {
+		return false
+	}
+	if !r.dec.Next() {
+		return false
+	}
+	if err := r.record(r.dec.Event()); err != nil {
+		r.err = fmt.Errorf("ssestream: record event: %w", err)
+		return false
+	}
+	return true
+}
+
+func (r *recordingDecoder) record(evt Event) error {
+	if r.w == nil {
+		return errors.New("writer is nil")
+	}
+	receivedAt := r.now().UTC()
+
+	var frame []byte
+	switch r.format {
+	case RecordFormatJSONL:
+		line, err := json.Marshal(RecordedEvent{
+			Time: receivedAt,
+			Type: evt.Type,
+			Data: string(evt.Data),
+		})
+		if err != nil {
+			return err
+		}
+		frame = append(line, '\n')
+	case RecordFormatSSE:
+		var buf bytes.Buffer
+		buf.WriteString(": " + recordedAtPrefix + receivedAt.Format(time.RFC3339Nano) + "\n")
+		if evt.Type != "" {
+			buf.WriteString("event: " + evt.Type + "\n")
+		}
+		for _, line := range bytes.Split(evt.Data, []byte("\n")) {
+			buf.WriteString("data: ")
+			buf.Write(line)
+			buf.WriteByte('\n')
+		}
+		buf.WriteByte('\n')
+		frame = buf.Bytes()
+	default:
+		return fmt.Errorf("unknown record format %d", r.format)
+	}
+
+	_, err := r.w.Write(frame)
+	return err
+}
+
+func (r *recordingDecoder) Event() Event {
+	return r.dec.Event()
+}
+
+func (r *recordingDecoder) Close() error {
+	return r.dec.Close()
+}
+
+func (r *recordingDecoder) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.dec.Err()
+}
+
+// WithRawRecorder copies the response body to w as the decoder reads it,
+// before any parsing, and writes a ": recorded-at <RFC 3339 time>" comment
+// ahead of each frame. The result is a RecordFormatSSE recording that keeps
+// ids, retry hints, comments and line endings exactly as the server sent
+// them. A failed write stops the stream and is reported by Err. The caller
+// owns w.
+func WithRawRecorder(w io.Writer) DecoderOption {
+	return func(c *decoderConfig) {
+		c.recorder = w
+	}
+}
+
+// rawRecorder tees a response body to w, inserting a recorded-at comment
+// before the first line of every frame. Frames are separated by blank lines,
+// and a frame starts at its first non-blank line.
+type rawRecorder struct {
+	rc  io.ReadCloser
+	w   io.Writer
+	now func() time.Time
+
+	frameStart bool // the next non-blank line starts a frame
+	lineBlank  bool // the current line has held only carriage returns so far
+	pendingCR  int  // carriage returns held back until the line is known
+	buf        bytes.Buffer
+}
+
+func newRawRecorder(rc io.ReadCloser, w io.Writer, now func() time.Time) *rawRecorder {
+	if now == nil {
+		now = time.Now
+	}
+	return &rawRecorder{rc: rc, w: w, now: now, frameStart: true, lineBlank: true}
+}
+
+func (r *rawRecorder) Read(p []byte) (int, error) {
+	n, err := r.rc.Read(p)
+	r.buf.Reset()
+	r.record(p[:n])
+	if err != nil {
+		// The stream is over, so nothing follows held carriage returns.
+		r.flushCR()
+	}
+	if r.buf.Len() > 0 {
+		if _, werr := r.w.Write(r.buf.Bytes()); werr != nil {
+			return 0, fmt.Errorf("ssestream: record event: %w", werr)
+		}
+	}
+	return n, err
+}
+
+func (r *rawRecorder) record(p []byte) {
+	var stamp string
+	for _, b := range p {
+		switch {
+		case b == '\n':
+			r.flushCR()
+			if r.lineBlank {
+				r.frameStart = true
+			}
+			r.lineBlank = true
+		case b == '\r' && r.lineBlank:
+			// A carriage return may belong to a CRLF blank line, so hold it
+			// until the line is known not to start a frame.
+			r.pendingCR++
+			continue
+		default:
+			if r.frameStart {
+				if stamp == "" {
+					stamp = ": " + recordedAtPrefix + r.now().UTC().Format(time.RFC3339Nano) + "\n"
+				}
+				r.buf.WriteString(stamp)
+				r.frameStart = false
+			}
+			r.flushCR()
+			r.lineBlank = false
+		}
+		r.buf.WriteByte(b)
+	}
+}
+
+func (r *rawRecorder) flushCR() {
+	for ; r.pendingCR > 0; r.pendingCR-- {
+		r.buf.WriteByte('\r')
+	}
+}
+
+func (r *rawRecorder) Close() error {
+	return r.rc.Close()
+}
+
+// ReplayOption configures a decoder returned by NewReplayDecoder.
+type ReplayOption func(*replayDecoder)
+
+// WithReplayFormat selects the recording layout to parse. The default is
+// RecordFormatJSONL.
+func WithReplayFormat(format RecordFormat) ReplayOption {
+	return func(d *replayDecoder) {
+		d.format = format
+	}
+}
+
+// WithOriginalPacing makes Next wait between events for the gap between their
+// recorded timestamps, reproducing the original timing. Without it, events
+// are replayed as fast as they are read.
+func WithOriginalPacing() ReplayOption {
+	return func(d *replayDecoder) {
+		d.paced = true
+	}
+}
+
+// NewReplayDecoder returns a Decoder that reads a recording produced by
+// NewRecorder or WithRawRecorder, so a captured session can be fed back into a Stream:
+//
+//	f, err := os.Open("events.jsonl")
+//	...
+//	stream := ssestream.NewStream[opencode.Event](ssestream.NewReplayDecoder(f), nil)
+//
+// Close interrupts any pending pacing wait and closes rc.
+func NewReplayDecoder(rc io.ReadCloser, opts ...ReplayOption) Decoder {
+	if rc == nil {
+		return &nilDecoder{}
+	}
+	d := &replayDecoder{rc: rc, done: make(chan struct{})}
+	for _, opt := range opts {
+		opt(d)
+	}
+	switch d.format {
+	case RecordFormatJSONL:
+		d.jsonl = json.NewDecoder(rc)
+	case RecordFormatSSE:
+		d.sse = &eventStreamDecoder{
+			rc:        rc,
+			reader:    bufio.NewReaderSize(rc, defaultSSEReaderSize),
+			onComment: d.onComment,
+		}
+	default:
+		d.err = fmt.Errorf("ssestream: unknown record format %d", d.format)
+	}
+	return d
+}
+
+type replayDecoder struct {
+	rc     io.ReadCloser
+	format RecordFormat
+	paced  bool
+	jsonl  *json.Decoder
+	sse    *eventStreamDecoder
+
+	// frameTime is the timestamp from the most recent recorded-at comment
+	// in an SSE recording.
+	frameTime time.Time
+	prevTime  time.Time
+	evt       Event
+	err       error
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (d *replayDecoder) onComment(comment []byte) {
+	value, ok := bytes.CutPrefix(comment, []byte(recordedAtPrefix))
+	if !ok {
+		return
+	}
+	if t, err := time.Parse(time.RFC3339Nano, string(value)); err == nil {
+		d.frameTime = t
+	}
+}
+
+func (d *replayDecoder) Next() bool {
+	if d.err != nil {
+		return false
+	}
+
+	rec, ok := d.read()
+	if !ok {
+		return false
+	}
+	if d.paced && !d.prevTime.IsZero() && !rec.Time.IsZero() {
+		if gap := rec.Time.Sub(d.prevTime); gap > 0 && !d.wait(gap) {
+			return false
+		}
+	}
+	if !rec.Time.IsZero() {
+		d.prevTime = rec.Time
+	}
+	d.evt = Event{Type: rec.Type, Data: []byte(rec.Data)}
+	return true
+}
+
+func (d *replayDecoder) read() (RecordedEvent, bool) {
+	if d.sse != nil {
+		d.frameTime = time.Time{}
+		if !d.sse.Next() {
+			d.err = d.sse.Err()
+			return RecordedEvent{}, false
+		}
+		evt := d.sse.Event()
+		return RecordedEvent{Time: d.frameTime, Type: evt.Type, Data: string(evt.Data)}, true
+	}
+
+	var rec RecordedEvent
+	if err := d.jsonl.Decode(&rec); err != nil {
+		if !errors.Is(err, io.EOF) {
+			d.err = fmt.Errorf("ssestream: read recording: %w", err)
+		}
+		return RecordedEvent{}, false
+	}
+	return rec, true
+}
+
+// wait sleeps for delay and reports false if the decoder was closed first.
+func (d *replayDecoder) wait(delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-d.done:
+		return false
+	}
+}
+
+func (d *replayDecoder) Event() Event {
+	return d.evt
+}
+
+func (d *replayDecoder) Close() error {
+	d.closeOnce.Do(func() { close(d.done) })
+	return d.rc.Close()
+}
+
+func (d *replayDecoder) Err() error {
+	return d.err
+}
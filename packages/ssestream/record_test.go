@@ -0,0 +1,256 @@
+package ssestream
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// fixedClock returns successive timestamps spaced step apart.
+func fixedClock(start time.Time, step time.Duration) func() time.Time {
+	next := start
+	return func() time.Time {
+		now := next
+		next = next.Add(step)
+		return now
+	}
+}
+
+func recordAll(t *testing.T, raw string, format RecordFormat) string {
+	t.Helper()
+	var buf bytes.Buffer
+	dec := NewRecorder(newSSEDecoder(raw), &buf, WithRecordFormat(format))
+	dec.(*recordingDecoder).now = fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), 50*time.Millisecond)
+	for dec.Next() {
+	}
+	if err := dec.Err(); err != nil {
+		t.Fatalf("unexpected recorder error: %v", err)
+	}
+	return buf.String()
+}
+
+func replayAll(t *testing.T, recording string, opts ...ReplayOption) []Event {
+	t.Helper()
+	dec := NewReplayDecoder(io.NopCloser(strings.NewReader(recording)), opts...)
+	defer func() { _ = dec.Close() }()
+	var events []Event
+	for dec.Next() {
+		events = append(events, dec.Event())
+	}
+	if err := dec.Err(); err != nil {
+		t.Fatalf("unexpected replay error: %v", err)
+	}
+	return events
+}
+
+const recordFixture = "event: message\ndata: {\"n\":1}\n\n: keep-alive\ndata: line1\ndata: line2\n\n"
+
+func TestRecorder_JSONL_WritesTimestampedLines(t *testing.T) {
+	got := recordAll(t, recordFixture, RecordFormatJSONL)
+	want := `{"time":"2025-01-02T03:04:05Z","event":"message","data":"{\"n\":1}"}` + "\n" +
+		`{"time":"2025-01-02T03:04:05.05Z","data":"line1\nline2"}` + "\n"
+	if got != want {
+		t.Fatalf("recording mismatch\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestRecorder_SSE_WritesTimestampedFrames(t *testing.T) {
+	got := recordAll(t, recordFixture, RecordFormatSSE)
+	want := ": recorded-at 2025-01-02T03:04:05Z\nevent: message\ndata: {\"n\":1}\n\n" +
+		": recorded-at 2025-01-02T03:04:05.05Z\ndata: line1\ndata: line2\n\n"
+	if got != want {
+		t.Fatalf("recording mismatch\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestRecorder_PassesEventsThrough(t *testing.T) {
+	var buf bytes.Buffer
+	type payload struct {
+		N int `json:"n"`
+	}
+	stream := NewStream[payload](NewRecorder(newSSEDecoder("data: {\"n\":7}\n\n"), &buf), nil)
+	defer func() { _ = stream.Close() }()
+
+	if !stream.Next() {
+		t.Fatalf("expected an event, err=%v", stream.Err())
+	}
+	if stream.Current().N != 7 {
+		t.Fatalf("expected N=7, got %d", stream.Current().N)
+	}
+	if buf.Len() == 0 {
+		t.Fatal("expected the event to be recorded")
+	}
+}
+
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestRecorder_WriteErrorStopsStream(t *testing.T) {
+	writeErr := errors.New("disk full")
+	dec := NewRecorder(newSSEDecoder("data: {}\n\n"), failingWriter{err: writeErr})
+
+	if dec.Next() {
+		t.Fatal("expected Next() to return false when recording fails")
+	}
+	if !errors.Is(dec.Err(), writeErr) {
+		t.Fatalf("expected %v, got %v", writeErr, dec.Err())
+	}
+}
+
+// rawRecordFixture uses fields and framing that decoding discards.
+const rawRecordFixture = "retry: 3000\n\nid: 1\nevent: message\ndata: {\"n\":1}\n\n" +
+	": keep-alive\r\n\r\n\nid: 2\r\ndata:line1\r\ndata: line2\r\n\r\ndata: tail"
+
+// rawRecordAll decodes body one byte per read through WithRawRecorder and
+// returns the recording.
+func rawRecordAll(t *testing.T, body string) (string, []Event) {
+	t.Helper()
+	var buf bytes.Buffer
+	clock := fixedClock(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), 50*time.Millisecond)
+	dec := NewDecoder(&http.Response{
+		Header: http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:   io.NopCloser(iotest.OneByteReader(strings.NewReader(body))),
+	}, WithRawRecorder(&buf), func(c *decoderConfig) { c.now = clock })
+	defer func() { _ = dec.Close() }()
+	var events []Event
+	for dec.Next() {
+		events = append(events, Event{Type: dec.Event().Type, Data: append([]byte(nil), dec.Event().Data...)})
+	}
+	if err := dec.Err(); err != nil {
+		t.Fatalf("unexpected decoder error: %v", err)
+	}
+	return buf.String(), events
+}
+
+func TestRawRecorder_KeepsStreamVerbatim(t *testing.T) {
+	got, live := rawRecordAll(t, rawRecordFixture)
+	want := ": recorded-at 2025-01-02T03:04:05Z\nretry: 3000\n\n" +
+		": recorded-at 2025-01-02T03:04:05.05Z\nid: 1\nevent: message\ndata: {\"n\":1}\n\n" +
+		": recorded-at 2025-01-02T03:04:05.1Z\n: keep-alive\r\n\r\n\n" +
+		": recorded-at 2025-01-02T03:04:05.15Z\nid: 2\r\ndata:line1\r\ndata: line2\r\n\r\n" +
+		": recorded-at 2025-01-02T03:04:05.2Z\ndata: tail"
+	if got != want {
+		t.Fatalf("recording mismatch\ngot:  %q\nwant: %q", got, want)
+	}
+
+	replayed := replayAll(t, got, WithReplayFormat(RecordFormatSSE))
+	if len(live) != 3 || len(replayed) != len(live) {
+		t.Fatalf("live=%d replayed=%d events", len(live), len(replayed))
+	}
+	for i := range live {
+		if live[i].Type != replayed[i].Type || string(live[i].Data) != string(replayed[i].Data) {
+			t.Errorf("event %d: live %+v, replayed %+v", i, live[i], replayed[i])
+		}
+	}
+}
+
+func TestRawRecorder_WriteErrorStopsStream(t *testing.T) {
+	writeErr := errors.New("disk full")
+	dec := NewDecoder(&http.Response{
+		Header: http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:   io.NopCloser(strings.NewReader("data: {}\n\n")),
+	}, WithRawRecorder(failingWriter{err: writeErr}))
+
+	if dec.Next() {
+		t.Fatal("expected Next() to return false when recording fails")
+	}
+	if !errors.Is(dec.Err(), writeErr) {
+		t.Fatalf("expected %v, got %v", writeErr, dec.Err())
+	}
+}
+
+func TestReplay_RoundTripsBothFormats(t *testing.T) {
+	for _, format := range []RecordFormat{RecordFormatJSONL, RecordFormatSSE} {
+		recording := recordAll(t, recordFixture, format)
+		events := replayAll(t, recording, WithReplayFormat(format))
+		if len(events) != 2 {
+			t.Fatalf("format %d: expected 2 events, got %d", format, len(events))
+		}
+		if events[0].Type != "message" || string(events[0].Data) != `{"n":1}` {
+			t.Errorf("format %d: unexpected first event %+v", format, events[0])
+		}
+		if events[1].Type != "" || string(events[1].Data) != "line1\nline2" {
+			t.Errorf("format %d: unexpected second event %+v", format, events[1])
+		}
+	}
+}
+
+func TestReplay_OriginalPacingWaitsBetweenEvents(t *testing.T) {
+	recording := `{"time":"2025-01-02T03:04:05Z","data":"{}"}` + "\n" +
+		`{"time":"2025-01-02T03:04:05.08Z","data":"{}"}` + "\n"
+
+	start := time.Now()
+	events := replayAll(t, recording, WithOriginalPacing())
+	elapsed := time.Since(start)
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if elapsed < 80*time.Millisecond {
+		t.Fatalf("expected replay to take at least 80ms, took %s", elapsed)
+	}
+}
+
+func TestReplay_InstantIgnoresTimestamps(t *testing.T) {
+	recording := `{"time":"2025-01-02T03:04:05Z","data":"{}"}` + "\n" +
+		`{"time":"2025-01-02T04:04:05Z","data":"{}"}` + "\n"
+
+	start := time.Now()
+	events := replayAll(t, recording)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected instant replay, took %s", elapsed)
+	}
+}
+
+func TestReplay_CloseInterruptsPacing(t *testing.T) {
+	recording := `{"time":"2025-01-02T03:04:05Z","data":"{}"}` + "\n" +
+		`{"time":"2025-01-02T04:04:05Z","data":"{}"}` + "\n"
+	dec := NewReplayDecoder(io.NopCloser(strings.NewReader(recording)), WithOriginalPacing())
+
+	if !dec.Next() {
+		t.Fatalf("expected first event, err=%v", dec.Err())
+	}
+	time.AfterFunc(20*time.Millisecond, func() { _ = dec.Close() })
+
+	done := make(chan bool, 1)
+	go func() { done <- dec.Next() }()
+	select {
+	case ok := <-done:
+		if ok {
+			t.Fatal("expected Next() to return false after Close")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not interrupt the pacing wait")
+	}
+}
+
+func TestReplay_MalformedJSONLReportsError(t *testing.T) {
+	dec := NewReplayDecoder(io.NopCloser(strings.NewReader("{not json\n")))
+	defer func() { _ = dec.Close() }()
+
+	if dec.Next() {
+		t.Fatal("expected Next() to return false for malformed recording")
+	}
+	if dec.Err() == nil || !strings.Contains(dec.Err().Error(), "read recording") {
+		t.Fatalf("expected read recording error, got %v", dec.Err())
+	}
+}
+
+func TestReplay_NilReader(t *testing.T) {
+	dec := NewReplayDecoder(nil)
+	if dec.Next() {
+		t.Fatal("expected Next() to return false")
+	}
+	if !errors.Is(dec.Err(), ErrNilDecoder) {
+		t.Fatalf("expected ErrNilDecoder, got %v", dec.Err())
+	}
+}
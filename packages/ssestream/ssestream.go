@@ -3,6 +3,7 @@ package ssestream
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,8 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -27,6 +30,30 @@ const (
 // indicating the response body was not available.
 var ErrNilDecoder = errors.New("ssestream: decoder is nil")
 
+// ErrStreamStalled matches a *StreamStalledError, reported when a decoder
+// created with WithIdleTimeout receives nothing from the server for longer
+// than the timeout. Reconnect logic can use errors.Is to detect a half-open
+// connection.
+var ErrStreamStalled = errors.New("ssestream: stream stalled")
+
+// StreamStalledError reports that a stream received no bytes, including SSE
+// comment keep-alives, within IdleTimeout.
+type StreamStalledError struct {
+	IdleTimeout time.Duration
+}
+
+func (e *StreamStalledError) Error() string {
+	return fmt.Sprintf("ssestream: no data received for %s", e.IdleTimeout)
+}
+
+func (e *StreamStalledError) Is(target error) bool {
+	return target == ErrStreamStalled
+}
+
+// ErrNilContext is delivered by Stream.Chan when it is called with a nil
+// context.
+var ErrNilContext = errors.New("ssestream: context is nil")
+
 // nilDecoder is returned by NewDecoder when the response or body is nil.
 // It always reports ErrNilDecoder, making the error discoverable without Stream.
 type nilDecoder struct{}
@@ -43,26 +70,97 @@ type Decoder interface {
 	Err() error
 }
 
-func NewDecoder(res *http.Response) Decoder {
+// DecoderOption configures a decoder returned by NewDecoder.
+type DecoderOption func(*decoderConfig)
+
+type decoderConfig struct {
+	idleTimeout time.Duration
+	recorder    io.Writer
+	now         func() time.Time
+}
+
+// WithIdleTimeout fails the stream with a *StreamStalledError when a read
+// from the response body waits longer than timeout for data. Any bytes count
+// as activity, so servers that send SSE comment keep-alives stay connected.
+// Time the consumer spends between calls to Next is not counted. A timeout
+// of zero or less disables the check.
+func WithIdleTimeout(timeout time.Duration) DecoderOption {
+	return func(c *decoderConfig) {
+		c.idleTimeout = timeout
+	}
+}
+
+func NewDecoder(res *http.Response, opts ...DecoderOption) Decoder {
 	if res == nil || res.Body == nil {
 		return &nilDecoder{}
 	}
 
+	var cfg decoderConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	body := res.Body
+	if cfg.idleTimeout > 0 {
+		body = newIdleTimeoutReader(body, cfg.idleTimeout)
+	}
+	if cfg.recorder != nil {
+		body = newRawRecorder(body, cfg.recorder, cfg.now)
+	}
+
 	var decoder Decoder
 	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("content-type"))
 	decoderTypesMu.RLock()
 	t, ok := decoderTypes[mediaType]
 	decoderTypesMu.RUnlock()
 	if ok {
-		decoder = t(res.Body)
+		decoder = t(body)
 	}
 	if decoder == nil {
-		reader := bufio.NewReaderSize(res.Body, defaultSSEReaderSize)
-		decoder = &eventStreamDecoder{rc: res.Body, reader: reader}
+		reader := bufio.NewReaderSize(body, defaultSSEReaderSize)
+		decoder = &eventStreamDecoder{rc: body, reader: reader}
 	}
 	return decoder
 }
 
+// idleTimeoutReader closes the underlying body when a single Read blocks for
+// longer than timeout, turning a half-open connection into an error.
+type idleTimeoutReader struct {
+	rc      io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	stalled atomic.Bool
+}
+
+func newIdleTimeoutReader(rc io.ReadCloser, timeout time.Duration) *idleTimeoutReader {
+	r := &idleTimeoutReader{rc: rc, timeout: timeout}
+	r.timer = time.AfterFunc(timeout, r.stall)
+	r.timer.Stop()
+	return r
+}
+
+func (r *idleTimeoutReader) stall() {
+	r.stalled.Store(true)
+	_ = r.rc.Close()
+}
+
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	if r.stalled.Load() {
+		return 0, &StreamStalledError{IdleTimeout: r.timeout}
+	}
+	r.timer.Reset(r.timeout)
+	n, err := r.rc.Read(p)
+	r.timer.Stop()
+	if err != nil && r.stalled.Load() {
+		return n, &StreamStalledError{IdleTimeout: r.timeout}
+	}
+	return n, err
+}
+
+func (r *idleTimeoutReader) Close() error {
+	r.timer.Stop()
+	return r.rc.Close()
+}
+
 var (
 	decoderTypes   = map[string](func(io.ReadCloser) Decoder){}
 	decoderTypesMu sync.RWMutex
@@ -98,6 +196,9 @@ type eventStreamDecoder struct {
 	err          error
 	maxDataBytes int // max accumulated data size per event; 0 uses maxSSEDataSize
 	maxLineBytes int // max bytes in a single SSE line; 0 uses maxSSELineSize
+	// onComment, when set, receives the text of each SSE comment line with
+	// the leading colon and optional space removed.
+	onComment func(comment []byte)
 }
 
 func (s *eventStreamDecoder) readLine(dst *bytes.Buffer) ([]byte, bool, error) {
@@ -194,7 +295,10 @@ func (s *eventStreamDecoder) Next() bool {
 
 		switch string(name) {
 		case "":
-			// SSE comment lines (starting with ":") are intentionally ignored.
+			// SSE comment lines (starting with ":") carry no event data.
+			if s.onComment != nil {
+				s.onComment(value)
+			}
 		case "event":
 			event = string(value)
 		case "data":
@@ -300,6 +404,63 @@ func (s *Stream[T]) Next() bool {
 	return false
 }
 
+// Chan drains the stream from a background goroutine so it can be consumed
+// with select alongside other channels:
+//
+//	values, errs := stream.Chan(ctx)
+//	for v := range values {
+//		// handle v
+//	}
+//	if err := <-errs; err != nil {
+//		...
+//	}
+//
+// The error channel is buffered and receives at most one value: the stream's
+// terminal error, or ctx.Err() when ctx is done first. Cancelling ctx also
+// closes the decoder so a read blocked on the network returns promptly. The
+// stream is closed before either channel is closed. Once Chan has been called
+// the stream is owned by the goroutine; callers must not call Next, Current
+// or Close on it concurrently.
+func (s *Stream[T]) Chan(ctx context.Context) (<-chan T, <-chan error) {
+	values := make(chan T)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(values)
+		defer func() { _ = s.Close() }()
+
+		if ctx == nil {
+			errs <- ErrNilContext
+			return
+		}
+		if decoder := s.decoder; decoder != nil {
+			stop := context.AfterFunc(ctx, func() { _ = decoder.Close() })
+			defer stop()
+		}
+
+		for s.Next() {
+			select {
+			case values <- s.Current():
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+		// A decoder closed by cancellation reports a read error; surface the
+		// cancellation cause instead.
+		if err := ctx.Err(); err != nil {
+			errs <- err
+			return
+		}
+		if err := s.Err(); err != nil {
+			errs <- err
+		}
+	}()
+
+	return values, errs
+}
+
 func (s *Stream[T]) Current() T {
 	return s.cur
 }
@@ -0,0 +1,33 @@
+//go:build go1.23
+
+package ssestream
+
+import "iter"
+
+// All returns an iterator over the stream's values for use with range:
+//
+//	for v, err := range stream.All() {
+//		if err != nil {
+//			...
+//		}
+//		// handle v
+//	}
+//
+// A terminal error is yielded once, paired with the zero value of T, after
+// which iteration ends. The stream is closed when iteration finishes,
+// including when the loop body breaks or returns early.
+func (s *Stream[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer func() { _ = s.Close() }()
+
+		for s.Next() {
+			if !yield(s.Current(), nil) {
+				return
+			}
+		}
+		if err := s.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
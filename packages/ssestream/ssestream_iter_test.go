@@ -0,0 +1,62 @@
+//go:build go1.23
+
+package ssestream
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStream_All_YieldsValues(t *testing.T) {
+	raw := "data: {\"n\":1}\n\ndata: {\"n\":2}\n\n"
+	dec := &closeTrackingDecoder{Decoder: newSSEDecoder(raw)}
+
+	type payload struct {
+		N int `json:"n"`
+	}
+	var got []int
+	for v, err := range NewStream[payload](dec, nil).All() {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, v.N)
+	}
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("expected [1 2], got %v", got)
+	}
+	if dec.closeCount() == 0 {
+		t.Fatal("expected decoder to be closed after iteration")
+	}
+}
+
+func TestStream_All_BreakClosesDecoder(t *testing.T) {
+	raw := "data: {}\n\ndata: {}\n\ndata: {}\n\n"
+	dec := &closeTrackingDecoder{Decoder: newSSEDecoder(raw)}
+
+	seen := 0
+	for _, err := range NewStream[interface{}](dec, nil).All() {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		seen++
+		break
+	}
+	if seen != 1 {
+		t.Fatalf("expected 1 value before break, got %d", seen)
+	}
+	if dec.closeCount() != 1 {
+		t.Fatalf("expected decoder closed once after break, got %d", dec.closeCount())
+	}
+}
+
+func TestStream_All_YieldsTerminalError(t *testing.T) {
+	readErr := errors.New("connection reset by peer")
+
+	var errs []error
+	for _, err := range NewStream[interface{}](&errorDecoder{err: readErr}, nil).All() {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], readErr) {
+		t.Fatalf("expected single %v, got %v", readErr, errs)
+	}
+}
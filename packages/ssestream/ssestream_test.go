@@ -2,6 +2,7 @@ package ssestream
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 type mockDecoder struct{}
@@ -717,3 +719,225 @@ func TestEventStreamDecoder_IncompleteEventNoBlankLine(t *testing.T) {
 		t.Errorf("expected data %q, got %q", `{"id":1}`, string(evt.Data))
 	}
 }
+
+// closeTrackingDecoder wraps a Decoder and records Close calls.
+type closeTrackingDecoder struct {
+	Decoder
+	mu     sync.Mutex
+	closed int
+}
+
+func (d *closeTrackingDecoder) Close() error {
+	d.mu.Lock()
+	d.closed++
+	d.mu.Unlock()
+	return d.Decoder.Close()
+}
+
+func (d *closeTrackingDecoder) closeCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+func TestStream_Chan_DeliversValuesAndClosesDecoder(t *testing.T) {
+	raw := "data: {\"n\":1}\n\ndata: {\"n\":2}\n\ndata: {\"n\":3}\n\n"
+	dec := &closeTrackingDecoder{Decoder: newSSEDecoder(raw)}
+
+	type payload struct {
+		N int `json:"n"`
+	}
+	stream := NewStream[payload](dec, nil)
+	values, errs := stream.Chan(context.Background())
+
+	var got []int
+	for v := range values {
+		got = append(got, v.N)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Fatalf("expected [1 2 3], got %v", got)
+	}
+	if dec.closeCount() == 0 {
+		t.Fatal("expected decoder to be closed after channel drained")
+	}
+}
+
+func TestStream_Chan_ReportsDecoderError(t *testing.T) {
+	readErr := errors.New("connection reset by peer")
+	stream := NewStream[interface{}](&errorDecoder{err: readErr}, nil)
+	values, errs := stream.Chan(context.Background())
+
+	for range values {
+		t.Fatal("expected no values")
+	}
+	if err := <-errs; !errors.Is(err, readErr) {
+		t.Fatalf("expected %v, got %v", readErr, err)
+	}
+}
+
+func TestStream_Chan_ConstructionErrorDelivered(t *testing.T) {
+	constructErr := errors.New("connect failed")
+	stream := NewStream[interface{}](nil, constructErr)
+	values, errs := stream.Chan(context.Background())
+
+	for range values {
+		t.Fatal("expected no values")
+	}
+	if err := <-errs; !errors.Is(err, constructErr) {
+		t.Fatalf("expected %v, got %v", constructErr, err)
+	}
+}
+
+func TestStream_Chan_NilContext(t *testing.T) {
+	dec := &closeTrackingDecoder{Decoder: newSSEDecoder("data: {}\n\n")}
+	stream := NewStream[interface{}](dec, nil)
+	//nolint:staticcheck // SA1012: exercising nil context handling
+	values, errs := stream.Chan(nil)
+
+	for range values {
+		t.Fatal("expected no values")
+	}
+	if err := <-errs; !errors.Is(err, ErrNilContext) {
+		t.Fatalf("expected ErrNilContext, got %v", err)
+	}
+	if dec.closeCount() == 0 {
+		t.Fatal("expected decoder to be closed")
+	}
+}
+
+func TestStream_Chan_CancelUnblocksPendingRead(t *testing.T) {
+	// The body never produces data; cancelling ctx must close it so the
+	// blocked read returns and the goroutine exits.
+	pr, pw := io.Pipe()
+	defer func() { _ = pw.Close() }()
+	dec := NewDecoder(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       pr,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := NewStream[interface{}](dec, nil)
+	values, errs := stream.Chan(ctx)
+	cancel()
+
+	select {
+	case _, ok := <-values:
+		if ok {
+			t.Fatal("expected values channel to close without delivering")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for values channel to close")
+	}
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestStream_Chan_CancelWhileConsumerIdle(t *testing.T) {
+	// A value is ready but nobody receives it; cancellation must still let
+	// the goroutine exit instead of blocking on the send forever.
+	dec := &closeTrackingDecoder{Decoder: newSSEDecoder("data: {}\n\ndata: {}\n\n")}
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := NewStream[interface{}](dec, nil)
+	_, errs := stream.Chan(ctx)
+	cancel()
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for error after cancel")
+	}
+	if dec.closeCount() == 0 {
+		t.Fatal("expected decoder to be closed after cancel")
+	}
+}
+
+func newPipeSSEDecoder(opts ...DecoderOption) (Decoder, *io.PipeWriter) {
+	pr, pw := io.Pipe()
+	dec := NewDecoder(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       pr,
+	}, opts...)
+	return dec, pw
+}
+
+func TestNewDecoder_IdleTimeout_ReportsStreamStalled(t *testing.T) {
+	dec, pw := newPipeSSEDecoder(WithIdleTimeout(50 * time.Millisecond))
+	defer func() { _ = pw.Close() }()
+	defer func() { _ = dec.Close() }()
+
+	go func() { _, _ = io.WriteString(pw, "data: {}\n\n") }()
+	if !dec.Next() {
+		t.Fatalf("expected first event, err=%v", dec.Err())
+	}
+
+	// Nothing else is written; the next read must time out.
+	if dec.Next() {
+		t.Fatal("expected Next() to return false after idle timeout")
+	}
+	if !errors.Is(dec.Err(), ErrStreamStalled) {
+		t.Fatalf("expected ErrStreamStalled, got %v", dec.Err())
+	}
+	var stalled *StreamStalledError
+	if !errors.As(dec.Err(), &stalled) || stalled.IdleTimeout != 50*time.Millisecond {
+		t.Fatalf("expected *StreamStalledError with 50ms timeout, got %v", dec.Err())
+	}
+}
+
+func TestNewDecoder_IdleTimeout_CommentKeepAlivesCountAsActivity(t *testing.T) {
+	dec, pw := newPipeSSEDecoder(WithIdleTimeout(80 * time.Millisecond))
+	defer func() { _ = dec.Close() }()
+
+	go func() {
+		for i := 0; i < 6; i++ {
+			time.Sleep(30 * time.Millisecond)
+			_, _ = io.WriteString(pw, ": keep-alive\n")
+		}
+		_, _ = io.WriteString(pw, "data: {}\n\n")
+		_ = pw.Close()
+	}()
+
+	// 180ms of keep-alives exceed the timeout in total but never leave the
+	// reader idle for 80ms, so the event must arrive.
+	if !dec.Next() {
+		t.Fatalf("expected event after keep-alives, err=%v", dec.Err())
+	}
+	if dec.Next() {
+		t.Fatal("expected end of stream")
+	}
+	if err := dec.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNewDecoder_IdleTimeout_SlowConsumerNotStalled(t *testing.T) {
+	// Events already buffered must not be lost because the consumer took
+	// longer than the timeout between calls to Next.
+	dec := NewDecoder(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       io.NopCloser(strings.NewReader("data: {}\n\ndata: {}\n\n")),
+	}, WithIdleTimeout(20*time.Millisecond))
+	defer func() { _ = dec.Close() }()
+
+	for i := 0; i < 2; i++ {
+		if !dec.Next() {
+			t.Fatalf("event %d: expected Next() to succeed, err=%v", i, dec.Err())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if dec.Next() {
+		t.Fatal("expected end of stream")
+	}
+	if err := dec.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
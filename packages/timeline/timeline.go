@@ -0,0 +1,316 @@
+// Package timeline lists the tool calls made in an opencode session and its
+// child sessions, with timing, status and output size, and aggregates them
+// per tool.
+//
+//	tl, err := timeline.Fetch(ctx, client, sessionID, nil)
+//	for _, s := range tl.Stats() {
+//		fmt.Printf("%s: %d calls, %.0f%% failed, mean %s\n", s.Tool, s.Calls, 100*s.FailureRate(), s.Mean)
+//	}
+//	err = timeline.WriteJSON(os.Stdout, tl)
+package timeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	opencode "github.com/dominicnunez/opencode-sdk-go"
+	"github.com/dominicnunez/opencode-sdk-go/internal/mstime"
+)
+
+// Call is one tool invocation.
+type Call struct {
+	SessionID string `json:"sessionID"`
+	MessageID string `json:"messageID"`
+	PartID    string `json:"partID"`
+	CallID    string `json:"callID"`
+	// Depth is 0 for calls in the root session, 1 in its children and so on.
+	Depth int    `json:"depth"`
+	Tool  string `json:"tool"`
+	// Agent is the agent whose message made the call.
+	Agent  string                       `json:"agent,omitempty"`
+	Status opencode.ToolPartStateStatus `json:"status"`
+	Title  string                       `json:"title,omitempty"`
+	Input  map[string]interface{}       `json:"input,omitempty"`
+	// OutputSize is the length of a completed call's output in bytes.
+	OutputSize int    `json:"outputSize"`
+	Error      string `json:"error,omitempty"`
+	// Started is nil while the call is pending, and Ended until it has
+	// completed or failed.
+	Started *time.Time `json:"started,omitempty"`
+	Ended   *time.Time `json:"ended,omitempty"`
+	// Duration is the time from start to end of a finished call.
+	Duration time.Duration `json:"-"`
+
+	// created orders calls that have not started.
+	created time.Time
+}
+
+// MarshalJSON encodes Duration as whole milliseconds in durationMs.
+func (c Call) MarshalJSON() ([]byte, error) {
+	type call Call
+	return json.Marshal(struct {
+		call
+		DurationMS int64 `json:"durationMs"`
+	}{call(c), c.Duration.Milliseconds()})
+}
+
+// Finished reports whether the call completed or failed.
+func (c Call) Finished() bool {
+	return c.Status == opencode.ToolPartStateStatusCompleted || c.Status == opencode.ToolPartStateStatusError
+}
+
+// Extract returns the tool calls in messages, as returned by
+// Session.Messages, in the order they appear. Parts whose state fails to
+// decode are reported with only their status.
+func Extract(messages []opencode.SessionMessagesResponse) []Call {
+	var calls []Call
+	for _, msg := range messages {
+		calls = appendCalls(calls, msg, 0)
+	}
+	return calls
+}
+
+func appendCalls(calls []Call, msg opencode.SessionMessagesResponse, depth int) []Call {
+	var agent string
+	var created time.Time
+	if assistant, err := msg.Info.AsAssistant(); err == nil {
+		agent = assistant.Mode
+		created = mstime.Time(assistant.Time.Created)
+	}
+	for _, part := range msg.Parts {
+		if part.Type != opencode.PartTypeTool {
+			continue
+		}
+		tool, err := part.AsTool()
+		if err != nil {
+			continue
+		}
+		c := Call{
+			SessionID: tool.SessionID,
+			MessageID: tool.MessageID,
+			PartID:    tool.ID,
+			CallID:    tool.CallID,
+			Depth:     depth,
+			Tool:      tool.Tool,
+			Agent:     agent,
+			Status:    tool.State.Status,
+			created:   created,
+		}
+		fillState(&c, tool.State)
+		calls = append(calls, c)
+	}
+	return calls
+}
+
+func fillState(c *Call, state opencode.ToolPartState) {
+	switch state.Status {
+	case opencode.ToolPartStateStatusRunning:
+		if s, err := state.AsRunning(); err == nil {
+			c.Title = s.Title
+			c.Input, _ = s.Input.(map[string]interface{})
+			c.Started = mstime.Ptr(s.Time.Start)
+		}
+	case opencode.ToolPartStateStatusCompleted:
+		if s, err := state.AsCompleted(); err == nil {
+			c.Title = s.Title
+			c.Input = s.Input
+			c.OutputSize = len(s.Output)
+			c.Started, c.Ended = mstime.Ptr(s.Time.Start), mstime.Ptr(s.Time.End)
+		}
+	case opencode.ToolPartStateStatusError:
+		if s, err := state.AsError(); err == nil {
+			c.Input = s.Input
+			c.Error = s.Error
+			c.Started, c.Ended = mstime.Ptr(s.Time.Start), mstime.Ptr(s.Time.End)
+		}
+	}
+	if c.Started != nil && c.Ended != nil && c.Ended.After(*c.Started) {
+		c.Duration = c.Ended.Sub(*c.Started)
+	}
+}
+
+// Timeline is the tool calls of a session tree in start order.
+type Timeline struct {
+	SessionID string `json:"sessionID"`
+	Calls     []Call `json:"calls"`
+}
+
+// New returns a timeline of calls, sorted by start time. Calls that have not
+// started are placed by the time their message was created.
+func New(sessionID string, calls []Call) *Timeline {
+	sorted := append([]Call(nil), calls...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ti, tj := sorted[i].sortTime(), sorted[j].sortTime()
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return sorted[i].PartID < sorted[j].PartID
+	})
+	return &Timeline{SessionID: sessionID, Calls: sorted}
+}
+
+func (c Call) sortTime() time.Time {
+	if c.Started != nil {
+		return *c.Started
+	}
+	return c.created
+}
+
+// Slowest returns up to n finished calls, longest first. n <= 0 returns all
+// of them.
+func (t *Timeline) Slowest(n int) []Call {
+	var finished []Call
+	for _, c := range t.Calls {
+		if c.Finished() {
+			finished = append(finished, c)
+		}
+	}
+	sort.SliceStable(finished, func(i, j int) bool {
+		return finished[i].Duration > finished[j].Duration
+	})
+	if n > 0 && len(finished) > n {
+		finished = finished[:n]
+	}
+	return finished
+}
+
+// ToolStats aggregates the calls of one tool. Durations cover finished
+// calls only.
+type ToolStats struct {
+	Tool       string `json:"tool"`
+	Calls      int    `json:"calls"`
+	Completed  int    `json:"completed"`
+	Failed     int    `json:"failed"`
+	OutputSize int    `json:"outputSize"`
+
+	Total time.Duration `json:"-"`
+	Mean  time.Duration `json:"-"`
+	Max   time.Duration `json:"-"`
+}
+
+// FailureRate is the share of finished calls that failed, or 0 if none have
+// finished.
+func (s ToolStats) FailureRate() float64 {
+	finished := s.Completed + s.Failed
+	if finished == 0 {
+		return 0
+	}
+	return float64(s.Failed) / float64(finished)
+}
+
+// MarshalJSON adds the failure rate and encodes durations as whole
+// milliseconds.
+func (s ToolStats) MarshalJSON() ([]byte, error) {
+	type stats ToolStats
+	return json.Marshal(struct {
+		stats
+		FailureRate float64 `json:"failureRate"`
+		TotalMS     int64   `json:"totalMs"`
+		MeanMS      int64   `json:"meanMs"`
+		MaxMS       int64   `json:"maxMs"`
+	}{stats(s), s.FailureRate(), s.Total.Milliseconds(), s.Mean.Milliseconds(), s.Max.Milliseconds()})
+}
+
+// Stats aggregates the calls by tool, most time-consuming first.
+func (t *Timeline) Stats() []ToolStats {
+	byTool := make(map[string]*ToolStats)
+	var order []string
+	for _, c := range t.Calls {
+		s := byTool[c.Tool]
+		if s == nil {
+			s = &ToolStats{Tool: c.Tool}
+			byTool[c.Tool] = s
+			order = append(order, c.Tool)
+		}
+		s.Calls++
+		s.OutputSize += c.OutputSize
+		switch c.Status {
+		case opencode.ToolPartStateStatusCompleted:
+			s.Completed++
+		case opencode.ToolPartStateStatusError:
+			s.Failed++
+		}
+		if c.Finished() {
+			s.Total += c.Duration
+			s.Max = max(s.Max, c.Duration)
+		}
+	}
+
+	stats := make([]ToolStats, 0, len(order))
+	for _, tool := range order {
+		s := byTool[tool]
+		if finished := s.Completed + s.Failed; finished > 0 {
+			s.Mean = s.Total / time.Duration(finished)
+		}
+		stats = append(stats, *s)
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Total != stats[j].Total {
+			return stats[i].Total > stats[j].Total
+		}
+		return stats[i].Tool < stats[j].Tool
+	})
+	return stats
+}
+
+// WriteJSON writes the timeline with its per-tool stats as indented JSON.
+func WriteJSON(w io.Writer, t *Timeline) error {
+	calls := t.Calls
+	if calls == nil {
+		calls = []Call{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		SessionID string      `json:"sessionID"`
+		Calls     []Call      `json:"calls"`
+		Stats     []ToolStats `json:"stats"`
+	}{t.SessionID, calls, t.Stats()})
+}
+
+// Options configures Fetch.
+type Options struct {
+	Directory *string
+	// MaxDepth limits how many levels of child sessions are visited. Zero
+	// visits the whole tree.
+	MaxDepth int
+}
+
+// Fetch builds the timeline of a session and every session below it, such as
+// the subagent sessions it spawned.
+func Fetch(ctx context.Context, client *opencode.Client, sessionID string, opts *Options) (*Timeline, error) {
+	if client == nil {
+		return nil, fmt.Errorf("timeline: client is required")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	tree, err := client.Session.Tree(ctx, sessionID, &opencode.SessionTreeParams{
+		Directory: opts.Directory,
+		MaxDepth:  opts.MaxDepth,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("timeline: %w", err)
+	}
+
+	var calls []Call
+	err = tree.Walk(func(node *opencode.SessionTreeNode) error {
+		messages := client.Session.MessagesStreaming(ctx, node.Session.ID, &opencode.SessionMessagesParams{Directory: opts.Directory})
+		defer func() { _ = messages.Close() }()
+		for messages.Next() {
+			calls = appendCalls(calls, messages.Current(), node.Depth)
+		}
+		if err := messages.Err(); err != nil {
+			return fmt.Errorf("timeline: messages for session %s: %w", node.Session.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return New(sessionID, calls), nil
+}
@@ -0,0 +1,206 @@
+package timeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	opencode "github.com/dominicnunez/opencode-sdk-go"
+)
+
+const rootMessages = `[
+	{"info":{"id":"msg_1","role":"user","sessionID":"ses_root","time":{"created":1000}},"parts":[]},
+	{"info":{"id":"msg_2","role":"assistant","sessionID":"ses_root","mode":"build","time":{"created":2000}},"parts":[
+		{"id":"prt_1","messageID":"msg_2","sessionID":"ses_root","type":"tool","callID":"call_1","tool":"read",
+			"state":{"status":"completed","input":{"filePath":"main.go"},"output":"package main\n","title":"main.go","metadata":{},"time":{"start":2100,"end":2220}}},
+		{"id":"prt_2","messageID":"msg_2","sessionID":"ses_root","type":"tool","callID":"call_2","tool":"bash",
+			"state":{"status":"error","input":{"command":"make"},"error":"exit status 2","time":{"start":2300,"end":4300}}},
+		{"id":"prt_3","messageID":"msg_2","sessionID":"ses_root","type":"text","text":"retrying"},
+		{"id":"prt_4","messageID":"msg_2","sessionID":"ses_root","type":"tool","callID":"call_3","tool":"bash",
+			"state":{"status":"completed","input":{"command":"make"},"output":"ok","title":"make","metadata":{},"time":{"start":4400,"end":5400}}},
+		{"id":"prt_5","messageID":"msg_2","sessionID":"ses_root","type":"tool","callID":"call_4","tool":"bash",
+			"state":{"status":"running","input":{"command":"make test"},"title":"make test","time":{"start":9000}}},
+		{"id":"prt_6","messageID":"msg_2","sessionID":"ses_root","type":"tool","callID":"call_5","tool":"edit",
+			"state":{"status":"pending"}}
+	]}
+]`
+
+const childMessages = `[
+	{"info":{"id":"msg_9","role":"assistant","sessionID":"ses_child","mode":"general","time":{"created":3000}},"parts":[
+		{"id":"prt_9","messageID":"msg_9","sessionID":"ses_child","type":"tool","callID":"call_9","tool":"grep",
+			"state":{"status":"completed","input":{"pattern":"TODO"},"output":"a.go:1\nb.go:2\n","title":"TODO","metadata":{},"time":{"start":3100,"end":3150}}}
+	]}
+]`
+
+func loadMessages(t *testing.T, raw string) []opencode.SessionMessagesResponse {
+	t.Helper()
+	var msgs []opencode.SessionMessagesResponse
+	if err := json.Unmarshal([]byte(raw), &msgs); err != nil {
+		t.Fatalf("unmarshal messages: %v", err)
+	}
+	return msgs
+}
+
+func TestExtract(t *testing.T) {
+	calls := Extract(loadMessages(t, rootMessages))
+	if len(calls) != 5 {
+		t.Fatalf("got %d calls, want 5", len(calls))
+	}
+
+	read := calls[0]
+	if read.Tool != "read" || read.Agent != "build" || read.Status != opencode.ToolPartStateStatusCompleted {
+		t.Errorf("unexpected read call %+v", read)
+	}
+	if read.Duration != 120*time.Millisecond || read.OutputSize != len("package main\n") || read.Input["filePath"] != "main.go" {
+		t.Errorf("unexpected read details %+v", read)
+	}
+
+	failed := calls[1]
+	if failed.Error != "exit status 2" || failed.Duration != 2*time.Second || !failed.Finished() {
+		t.Errorf("unexpected failed call %+v", failed)
+	}
+
+	running := calls[3]
+	if running.Started == nil || running.Ended != nil || running.Duration != 0 || running.Finished() {
+		t.Errorf("unexpected running call %+v", running)
+	}
+	if running.Input["command"] != "make test" {
+		t.Errorf("running input = %v", running.Input)
+	}
+
+	if pending := calls[4]; pending.Started != nil || pending.Status != opencode.ToolPartStateStatusPending {
+		t.Errorf("unexpected pending call %+v", pending)
+	}
+}
+
+func TestTimeline_Stats(t *testing.T) {
+	tl := New("ses_root", append(Extract(loadMessages(t, rootMessages)), Extract(loadMessages(t, childMessages))...))
+
+	var order []string
+	for _, c := range tl.Calls {
+		order = append(order, c.PartID)
+	}
+	// The pending call sorts by its message's creation time.
+	if got, want := order, []string{"prt_6", "prt_1", "prt_2", "prt_9", "prt_4", "prt_5"}; !equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+
+	slowest := tl.Slowest(2)
+	if len(slowest) != 2 || slowest[0].PartID != "prt_2" || slowest[1].PartID != "prt_4" {
+		t.Errorf("Slowest(2) = %+v", slowest)
+	}
+	if all := tl.Slowest(0); len(all) != 4 {
+		t.Errorf("Slowest(0) returned %d calls, want 4 finished", len(all))
+	}
+
+	stats := tl.Stats()
+	if len(stats) != 4 || stats[0].Tool != "bash" {
+		t.Fatalf("Stats = %+v", stats)
+	}
+	bash := stats[0]
+	if bash.Calls != 3 || bash.Completed != 1 || bash.Failed != 1 || bash.FailureRate() != 0.5 {
+		t.Errorf("bash stats = %+v", bash)
+	}
+	if bash.Total != 3*time.Second || bash.Mean != 1500*time.Millisecond || bash.Max != 2*time.Second {
+		t.Errorf("bash durations = %+v", bash)
+	}
+	if edit := stats[3]; edit.Tool != "edit" || edit.FailureRate() != 0 {
+		t.Errorf("edit stats = %+v", edit)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	tl := New("ses_root", Extract(loadMessages(t, rootMessages)))
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, tl); err != nil {
+		t.Fatal(err)
+	}
+
+	var out struct {
+		SessionID string `json:"sessionID"`
+		Calls     []struct {
+			PartID     string     `json:"partID"`
+			DurationMS int64      `json:"durationMs"`
+			Started    *time.Time `json:"started"`
+		} `json:"calls"`
+		Stats []struct {
+			Tool        string  `json:"tool"`
+			FailureRate float64 `json:"failureRate"`
+			TotalMS     int64   `json:"totalMs"`
+		} `json:"stats"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if out.SessionID != "ses_root" || len(out.Calls) != 5 {
+		t.Fatalf("unexpected export %+v", out)
+	}
+	if c := out.Calls[2]; c.PartID != "prt_2" || c.DurationMS != 2000 || c.Started == nil {
+		t.Errorf("unexpected exported call %+v", c)
+	}
+	if s := out.Stats[0]; s.Tool != "bash" || s.FailureRate != 0.5 || s.TotalMS != 3000 {
+		t.Errorf("unexpected exported stats %+v", s)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/session/ses_root":
+			_, _ = w.Write([]byte(`{"id":"ses_root","title":"root"}`))
+		case "/session/ses_root/children":
+			_, _ = w.Write([]byte(`[{"id":"ses_child","title":"child","parentID":"ses_root"}]`))
+		case "/session/ses_child/children":
+			_, _ = w.Write([]byte(`[]`))
+		case "/session/ses_root/message":
+			_, _ = w.Write([]byte(rootMessages))
+		case "/session/ses_child/message":
+			_, _ = w.Write([]byte(childMessages))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client, err := opencode.NewClient(opencode.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	tl, err := Fetch(ctx, client, "ses_root", nil)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(tl.Calls) != 6 {
+		t.Fatalf("got %d calls, want 6", len(tl.Calls))
+	}
+	for _, c := range tl.Calls {
+		if c.PartID == "prt_9" && (c.Depth != 1 || c.SessionID != "ses_child" || c.Agent != "general") {
+			t.Errorf("unexpected child call %+v", c)
+		}
+	}
+
+	if _, err := Fetch(ctx, client, "ses_missing", nil); err == nil {
+		t.Error("expected error for unknown session")
+	}
+	if _, err := Fetch(ctx, nil, "ses_root", nil); err == nil {
+		t.Error("expected error for nil client")
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
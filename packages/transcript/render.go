@@ -0,0 +1,261 @@
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// WriteJSON writes t as indented JSON using the schema described by the
+// Transcript types.
+func WriteJSON(w io.Writer, t *Transcript) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
+
+// WriteMarkdown renders t as Markdown. Child sessions follow their parent
+// with headings one level deeper.
+func WriteMarkdown(w io.Writer, t *Transcript) error {
+	bw := bufio.NewWriter(w)
+	writeMarkdownSession(bw, &t.Session, 1)
+	return bw.Flush()
+}
+
+func writeMarkdownSession(w *bufio.Writer, s *Session, level int) {
+	h := strings.Repeat("#", min(level, 6))
+	sub := strings.Repeat("#", min(level+1, 6))
+
+	fmt.Fprintf(w, "%s %s\n\n", h, sessionTitle(s))
+	fmt.Fprintf(w, "- Session: `%s`\n", s.ID)
+	if s.Directory != "" {
+		fmt.Fprintf(w, "- Directory: `%s`\n", s.Directory)
+	}
+	if !s.Created.IsZero() {
+		fmt.Fprintf(w, "- Created: %s\n", formatTime(s.Created))
+	}
+	fmt.Fprintf(w, "- Usage: %s\n\n", formatUsage(s.Usage.Cost, s.Usage.Tokens))
+
+	for i := range s.Messages {
+		m := &s.Messages[i]
+		fmt.Fprintf(w, "%s %s\n\n", sub, messageHeading(m))
+		for j := range m.Parts {
+			writeMarkdownPart(w, &m.Parts[j])
+		}
+		if m.Error != nil {
+			fmt.Fprintf(w, "**Error:** %s\n\n", formatError(m.Error))
+		}
+	}
+
+	for _, child := range s.Children {
+		writeMarkdownSession(w, child, level+1)
+	}
+}
+
+func writeMarkdownPart(w *bufio.Writer, p *Part) {
+	switch {
+	case p.Type == "text":
+		if p.Text != "" {
+			fmt.Fprintf(w, "%s\n\n", p.Text)
+		}
+	case p.Type == "reasoning":
+		if p.Text == "" {
+			return
+		}
+		w.WriteString("> **Reasoning**\n>\n")
+		for _, line := range strings.Split(p.Text, "\n") {
+			fmt.Fprintf(w, "> %s\n", line)
+		}
+		w.WriteString("\n")
+	case p.Tool != nil:
+		t := p.Tool
+		fmt.Fprintf(w, "**Tool `%s`**", t.Tool)
+		if t.Title != "" {
+			fmt.Fprintf(w, " %s", t.Title)
+		}
+		fmt.Fprintf(w, " (%s)\n\n", t.Status)
+		if len(t.Input) > 0 {
+			writeFenced(w, "json", indentJSON(t.Input))
+		}
+		if t.Output != "" {
+			writeFenced(w, "text", t.Output)
+		}
+		if t.Error != "" {
+			fmt.Fprintf(w, "**Tool error:** %s\n\n", t.Error)
+		}
+		for _, a := range t.Attachments {
+			fmt.Fprintf(w, "- Attachment: %s\n", formatFile(a))
+		}
+		if len(t.Attachments) > 0 {
+			w.WriteString("\n")
+		}
+	case p.File != nil:
+		fmt.Fprintf(w, "**File:** %s\n\n", formatFile(*p.File))
+	case p.Patch != nil:
+		fmt.Fprintf(w, "**Patch** `%s`\n\n", p.Patch.Hash)
+		for _, f := range p.Patch.Files {
+			fmt.Fprintf(w, "- `%s`\n", f)
+		}
+		w.WriteString("\n")
+	case p.Step != nil:
+		reason := ""
+		if p.Step.Reason != "" {
+			reason = " (" + p.Step.Reason + ")"
+		}
+		fmt.Fprintf(w, "_Step finished%s: %s_\n\n", reason, formatUsage(p.Step.Cost, p.Step.Tokens))
+	case p.Agent != "":
+		fmt.Fprintf(w, "**Agent:** @%s\n\n", p.Agent)
+	case p.Retry != nil:
+		fmt.Fprintf(w, "_Retried after %s_\n\n", formatError(p.Retry))
+	}
+}
+
+// writeFenced writes a fenced code block whose fence is longer than any
+// backtick run in body.
+func writeFenced(w *bufio.Writer, lang, body string) {
+	fence := "```"
+	for strings.Contains(body, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(w, "%s%s\n%s\n%s\n\n", fence, lang, strings.TrimRight(body, "\n"), fence)
+}
+
+// WriteHTML renders t as a single HTML document with inline styles and no
+// external resources.
+func WriteHTML(w io.Writer, t *Transcript) error {
+	return htmlTemplate.Execute(w, t)
+}
+
+var htmlTemplate = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"title":   sessionTitle,
+	"heading": messageHeading,
+	"time":    formatTime,
+	"usage":   formatUsage,
+	"error":   formatError,
+	"json":    indentJSON,
+	"file":    formatFile,
+	"inline":  isInlineImage,
+	"safeURL": func(s string) template.URL { return template.URL(s) }, //nolint:gosec // only applied to data:image URLs, see isInlineImage
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{title .Session}}</title>
+<style>
+body{font-family:system-ui,sans-serif;max-width:60rem;margin:2rem auto;padding:0 1rem;color:#1f2328;line-height:1.5}
+.meta{color:#57606a;font-size:.9rem}
+.message{border-left:4px solid #d0d7de;padding:.25rem 1rem;margin:1.5rem 0}
+.message.user{border-color:#0969da}
+.message.assistant{border-color:#8250df}
+.text{white-space:pre-wrap}
+.reasoning{white-space:pre-wrap;color:#57606a;font-style:italic}
+pre{background:#f6f8fa;padding:.75rem;overflow:auto;border-radius:6px}
+.tool{border:1px solid #d0d7de;border-radius:6px;padding:.5rem .75rem;margin:.5rem 0}
+.error{color:#cf222e}
+.step{color:#57606a;font-size:.85rem}
+.children{margin-left:1.5rem}
+img{max-width:100%}
+</style>
+</head>
+<body>
+{{template "session" .Session}}
+</body>
+</html>
+{{define "session"}}<section class="session">
+<h1>{{title .}}</h1>
+<p class="meta">Session <code>{{.ID}}</code>{{if .Directory}} &middot; <code>{{.Directory}}</code>{{end}}{{if not .Created.IsZero}} &middot; {{time .Created}}{{end}}<br>{{usage .Usage.Cost .Usage.Tokens}}</p>
+{{range .Messages}}<article class="message {{.Role}}">
+<h2>{{heading .}}</h2>
+{{range .Parts}}{{template "part" .}}{{end}}{{if .Error}}<p class="error">{{error .Error}}</p>
+{{end}}</article>
+{{end}}{{if .Children}}<div class="children">
+{{range .Children}}{{template "session" .}}{{end}}</div>
+{{end}}</section>
+{{end}}
+{{define "part"}}{{if eq .Type "text"}}{{if .Text}}<div class="text">{{.Text}}</div>
+{{end}}{{else if eq .Type "reasoning"}}{{if .Text}}<details><summary>Reasoning</summary><div class="reasoning">{{.Text}}</div></details>
+{{end}}{{else if .Tool}}<div class="tool"><strong>{{.Tool.Tool}}</strong>{{if .Tool.Title}} {{.Tool.Title}}{{end}} <span class="meta">({{.Tool.Status}})</span>
+{{if .Tool.Input}}<details><summary>Input</summary><pre>{{json .Tool.Input}}</pre></details>
+{{end}}{{if .Tool.Output}}<details open><summary>Output</summary><pre>{{.Tool.Output}}</pre></details>
+{{end}}{{if .Tool.Error}}<p class="error">{{.Tool.Error}}</p>
+{{end}}{{range .Tool.Attachments}}{{template "file" .}}{{end}}</div>
+{{else if .File}}{{template "file" .File}}{{else if .Patch}}<p>Patch <code>{{.Patch.Hash}}</code></p><ul>{{range .Patch.Files}}<li><code>{{.}}</code></li>{{end}}</ul>
+{{else if .Step}}<p class="step">Step finished{{if .Step.Reason}} ({{.Step.Reason}}){{end}}: {{usage .Step.Cost .Step.Tokens}}</p>
+{{else if .Agent}}<p>Agent: @{{.Agent}}</p>
+{{else if .Retry}}<p class="step">Retried after {{error .Retry}}</p>
+{{end}}{{end}}
+{{define "file"}}{{if inline .}}<figure><img src="{{safeURL .URL}}" alt="{{.Filename}}"><figcaption>{{.Filename}}</figcaption></figure>
+{{else}}<p>File: {{file .}}</p>
+{{end}}{{end}}`))
+
+func sessionTitle(s *Session) string {
+	if s.Title != "" {
+		return s.Title
+	}
+	return "Session " + s.ID
+}
+
+func messageHeading(m *Message) string {
+	var b strings.Builder
+	switch m.Role {
+	case "user":
+		b.WriteString("User")
+	case "assistant":
+		b.WriteString("Assistant")
+		if m.ProviderID != "" || m.ModelID != "" {
+			fmt.Fprintf(&b, " (%s/%s)", m.ProviderID, m.ModelID)
+		}
+	default:
+		b.WriteString(m.Role)
+	}
+	if !m.Created.IsZero() {
+		b.WriteString(" · ")
+		b.WriteString(formatTime(m.Created))
+	}
+	return b.String()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatUsage(cost float64, t Tokens) string {
+	return fmt.Sprintf("$%.4f · %d input, %d output, %d reasoning, %d cache read, %d cache write tokens",
+		cost, t.Input, t.Output, t.Reasoning, t.CacheRead, t.CacheWrite)
+}
+
+func formatError(e *Error) string {
+	if e.Message == "" {
+		return e.Name
+	}
+	return e.Name + ": " + e.Message
+}
+
+// formatFile describes a file without its URL when the URL is an inline
+// data: URL, which can be megabytes long.
+func formatFile(f File) string {
+	name := f.Filename
+	if name == "" {
+		name = "(unnamed)"
+	}
+	if strings.HasPrefix(f.URL, "data:") {
+		return fmt.Sprintf("%s (%s, inline)", name, f.Mime)
+	}
+	return fmt.Sprintf("%s (%s) %s", name, f.Mime, f.URL)
+}
+
+func isInlineImage(f File) bool {
+	return strings.HasPrefix(f.Mime, "image/") && strings.HasPrefix(f.URL, "data:image/")
+}
+
+func indentJSON(v any) string {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
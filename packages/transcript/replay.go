@@ -0,0 +1,328 @@
+package transcript
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	opencode "github.com/dominicnunez/opencode-sdk-go"
+)
+
+// Load reads a transcript written by WriteJSON, or a raw JSON array of
+// messages as returned by SessionService.Messages. A raw array yields a
+// transcript whose session has only an ID, taken from the first message.
+func Load(r io.Reader) (*Transcript, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("transcript: read: %w", err)
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, errors.New("transcript: input is empty")
+	}
+
+	if trimmed[0] == '[' {
+		var msgs []opencode.SessionMessagesResponse
+		if err := json.Unmarshal(trimmed, &msgs); err != nil {
+			return nil, fmt.Errorf("transcript: decode messages: %w", err)
+		}
+		var info opencode.Session
+		if len(msgs) > 0 {
+			info.ID = msgs[0].Info.SessionID
+		}
+		return &Transcript{Version: SchemaVersion, Session: *Build(info, msgs)}, nil
+	}
+
+	var t Transcript
+	if err := json.Unmarshal(trimmed, &t); err != nil {
+		return nil, fmt.Errorf("transcript: decode: %w", err)
+	}
+	if t.Version < 1 || t.Version > SchemaVersion {
+		return nil, fmt.Errorf("transcript: unsupported schema version %d", t.Version)
+	}
+	return &t, nil
+}
+
+// ReplayOptions configures Replay.
+type ReplayOptions struct {
+	Directory *string
+	// Title names the new session. It defaults to "Replay: " followed by
+	// the original title.
+	Title string
+	// Model and Agent override the ones the server would pick for every
+	// replayed turn.
+	Model *opencode.SessionPromptParamsModel
+	Agent *string
+	// LengthTolerance is the relative change in assistant text length that
+	// counts as a divergence. It defaults to 0.5: a reply more than 1.5
+	// times as long as the original, or shorter than two thirds of it, is
+	// reported.
+	LengthTolerance float64
+	// OnTurn, if set, is called after each turn completes.
+	OnTurn func(TurnResult)
+}
+
+type DivergenceKind string
+
+const (
+	// DivergenceKindTools reports tools called by only one of the runs.
+	DivergenceKindTools DivergenceKind = "tools"
+	// DivergenceKindOutputLength reports a reply whose text length moved by
+	// more than LengthTolerance.
+	DivergenceKindOutputLength DivergenceKind = "output_length"
+)
+
+func (r DivergenceKind) IsKnown() bool {
+	switch r {
+	case DivergenceKindTools, DivergenceKindOutputLength:
+		return true
+	}
+	return false
+}
+
+type Divergence struct {
+	Kind   DivergenceKind `json:"kind"`
+	Detail string         `json:"detail"`
+}
+
+// TurnSummary describes the assistant's response to one user turn.
+type TurnSummary struct {
+	// Tools lists the tools called, sorted, with repeats.
+	Tools []string `json:"tools"`
+	// OutputLength is the number of characters of assistant text.
+	OutputLength int `json:"outputLength"`
+}
+
+type TurnResult struct {
+	// Index is the turn's position among the transcript's user messages.
+	Index             int          `json:"index"`
+	OriginalMessageID string       `json:"originalMessageID"`
+	ReplayMessageID   string       `json:"replayMessageID"`
+	Original          TurnSummary  `json:"original"`
+	Replayed          TurnSummary  `json:"replayed"`
+	Divergences       []Divergence `json:"divergences,omitempty"`
+}
+
+type ReplayResult struct {
+	SessionID string       `json:"sessionID"`
+	Turns     []TurnResult `json:"turns"`
+}
+
+// Replay creates a new session and sends each user turn of t to it in order
+// through SessionService.Prompt, then compares every reply with the
+// original. Only t's top-level session is replayed. User turns with nothing
+// to send, such as those made only of synthetic text, are skipped. On error
+// the result so far is returned alongside it.
+func Replay(ctx context.Context, client *opencode.Client, t *Transcript, opts *ReplayOptions) (*ReplayResult, error) {
+	if client == nil {
+		return nil, errors.New("transcript: client is required")
+	}
+	if t == nil {
+		return nil, errors.New("transcript: transcript is required")
+	}
+	if opts == nil {
+		opts = &ReplayOptions{}
+	}
+	tolerance := opts.LengthTolerance
+	if tolerance <= 0 {
+		tolerance = 0.5
+	}
+	title := opts.Title
+	if title == "" {
+		title = "Replay: " + sessionTitle(&t.Session)
+	}
+
+	session, err := client.Session.Create(ctx, &opencode.SessionCreateParams{Directory: opts.Directory, Title: opencode.Ptr(title)})
+	if err != nil {
+		return nil, fmt.Errorf("transcript: create session: %w", err)
+	}
+	result := &ReplayResult{SessionID: session.ID}
+
+	for index, turn := range userTurns(t.Session.Messages) {
+		parts := promptParts(turn.user)
+		if len(parts) == 0 {
+			continue
+		}
+		resp, err := client.Session.Prompt(ctx, session.ID, &opencode.SessionPromptParams{
+			Parts:     parts,
+			Directory: opts.Directory,
+			Agent:     opts.Agent,
+			Model:     opts.Model,
+		})
+		if err != nil {
+			return result, fmt.Errorf("transcript: replay turn %d: %w", index, err)
+		}
+		replayed, err := replayedSummary(ctx, client, session.ID, resp, opts.Directory)
+		if err != nil {
+			return result, fmt.Errorf("transcript: replay turn %d: %w", index, err)
+		}
+
+		tr := TurnResult{
+			Index:             index,
+			OriginalMessageID: turn.user.ID,
+			ReplayMessageID:   resp.Info.ParentID,
+			Original:          summarize(turn.replies),
+			Replayed:          replayed,
+		}
+		tr.Divergences = compareTurns(tr.Original, tr.Replayed, tolerance)
+		result.Turns = append(result.Turns, tr)
+		if opts.OnTurn != nil {
+			opts.OnTurn(tr)
+		}
+	}
+	return result, nil
+}
+
+type turn struct {
+	user    *Message
+	replies []Message
+}
+
+// userTurns groups messages into user messages and the assistant messages
+// that follow each.
+func userTurns(messages []Message) []turn {
+	var turns []turn
+	for i := range messages {
+		m := &messages[i]
+		switch {
+		case m.Role == "user":
+			turns = append(turns, turn{user: m})
+		case len(turns) > 0:
+			last := &turns[len(turns)-1]
+			last.replies = append(last.replies, *m)
+		}
+	}
+	return turns
+}
+
+// promptParts converts a user message back into prompt input.
+func promptParts(m *Message) []opencode.SessionPromptParamsPartUnion {
+	var parts []opencode.SessionPromptParamsPartUnion
+	for _, p := range m.Parts {
+		switch {
+		case p.Type == "text" && !p.Synthetic && p.Text != "":
+			parts = append(parts, opencode.TextPartInputParam{Text: p.Text, Type: opencode.TextPartInputTypeText})
+		case p.File != nil:
+			file := opencode.FilePartInputParam{Mime: p.File.Mime, Type: opencode.FilePartInputTypeFile, URL: p.File.URL}
+			if p.File.Filename != "" {
+				file.Filename = opencode.Ptr(p.File.Filename)
+			}
+			parts = append(parts, file)
+		case p.Agent != "":
+			parts = append(parts, opencode.AgentPartInputParam{Name: p.Agent, Type: opencode.AgentPartInputTypeAgent})
+		}
+	}
+	return parts
+}
+
+// replayedSummary summarizes every assistant message the prompt produced.
+// A multi-step reply spans several messages but Prompt returns only the
+// last, so the others are read back from the session.
+func replayedSummary(ctx context.Context, client *opencode.Client, sessionID string, resp *opencode.SessionPromptResponse, directory *string) (TurnSummary, error) {
+	msgs, err := client.Session.Messages(ctx, sessionID, &opencode.SessionMessagesParams{Directory: directory})
+	if err != nil {
+		return TurnSummary{}, err
+	}
+	var replies []Message
+	for _, msg := range msgs {
+		assistant, err := msg.Info.AsAssistant()
+		if err != nil || assistant.ParentID != resp.Info.ParentID {
+			continue
+		}
+		m := buildMessage(msg.Info)
+		for _, part := range msg.Parts {
+			m.Parts = append(m.Parts, buildPart(part))
+		}
+		replies = append(replies, m)
+	}
+	if len(replies) == 0 {
+		m := Message{Role: "assistant"}
+		for _, part := range resp.Parts {
+			m.Parts = append(m.Parts, buildPart(part))
+		}
+		replies = append(replies, m)
+	}
+	return summarize(replies), nil
+}
+
+func summarize(replies []Message) TurnSummary {
+	s := TurnSummary{Tools: []string{}}
+	for _, m := range replies {
+		for _, p := range m.Parts {
+			switch {
+			case p.Tool != nil:
+				s.Tools = append(s.Tools, p.Tool.Tool)
+			case p.Type == "text" && !p.Synthetic:
+				s.OutputLength += len([]rune(p.Text))
+			}
+		}
+	}
+	sort.Strings(s.Tools)
+	return s
+}
+
+func compareTurns(original, replayed TurnSummary, tolerance float64) []Divergence {
+	var divergences []Divergence
+
+	missing, extra := toolDifference(original.Tools, replayed.Tools)
+	if len(missing) > 0 || len(extra) > 0 {
+		var detail []string
+		if len(missing) > 0 {
+			detail = append(detail, "not called: "+strings.Join(missing, ", "))
+		}
+		if len(extra) > 0 {
+			detail = append(detail, "newly called: "+strings.Join(extra, ", "))
+		}
+		divergences = append(divergences, Divergence{Kind: DivergenceKindTools, Detail: strings.Join(detail, "; ")})
+	}
+
+	if lengthDiverges(original.OutputLength, replayed.OutputLength, tolerance) {
+		divergences = append(divergences, Divergence{
+			Kind:   DivergenceKindOutputLength,
+			Detail: fmt.Sprintf("%d characters originally, %d replayed", original.OutputLength, replayed.OutputLength),
+		})
+	}
+	return divergences
+}
+
+// toolDifference returns the multiset differences of two sorted tool lists.
+func toolDifference(original, replayed []string) (missing, extra []string) {
+	counts := map[string]int{}
+	for _, t := range original {
+		counts[t]++
+	}
+	for _, t := range replayed {
+		counts[t]--
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for n := counts[name]; n > 0; n-- {
+			missing = append(missing, name)
+		}
+		for n := counts[name]; n < 0; n++ {
+			extra = append(extra, name)
+		}
+	}
+	return missing, extra
+}
+
+func lengthDiverges(original, replayed int, tolerance float64) bool {
+	if original == replayed {
+		return false
+	}
+	if original == 0 || replayed == 0 {
+		return true
+	}
+	ratio := float64(replayed) / float64(original)
+	return math.Abs(math.Log(ratio)) > math.Log1p(tolerance)
+}
@@ -0,0 +1,158 @@
+package transcript
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	opencode "github.com/dominicnunez/opencode-sdk-go"
+)
+
+func TestLoad_ExportedJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, fixture(t)); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Session.ID != "ses_1" || len(loaded.Session.Messages) != 2 {
+		t.Fatalf("unexpected transcript %+v", loaded.Session)
+	}
+}
+
+func TestLoad_RawMessages(t *testing.T) {
+	loaded, err := Load(strings.NewReader(messagesFixture))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Session.ID != "ses_1" || loaded.Session.Messages[1].Parts[1].Tool == nil {
+		t.Fatalf("unexpected transcript %+v", loaded.Session)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	for name, input := range map[string]string{
+		"empty":   "  ",
+		"version": `{"version":99,"session":{}}`,
+		"garbage": `{"version":`,
+	} {
+		if _, err := Load(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	var mu sync.Mutex
+	var prompts []map[string]any
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/session", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["title"] != "Replay: Fix build" {
+			t.Errorf("unexpected title %v", body["title"])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"ses_new","title":"Replay: Fix build","time":{"created":1,"updated":1}}`))
+	})
+	mux.HandleFunc("/session/ses_new/message", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			data, _ := io.ReadAll(r.Body)
+			var body map[string]any
+			_ = json.Unmarshal(data, &body)
+			mu.Lock()
+			prompts = append(prompts, body)
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{"info":{"id":"msg_a2","role":"assistant","sessionID":"ses_new","parentID":"msg_u1","time":{"created":1}},"parts":[]}`))
+			return
+		}
+		// The reply took two steps: a tool call, then a short answer.
+		_, _ = w.Write([]byte(`[
+			{"info":{"id":"msg_u1","role":"user","sessionID":"ses_new"},"parts":[]},
+			{"info":{"id":"msg_a1","role":"assistant","sessionID":"ses_new","parentID":"msg_u1","time":{"created":1}},"parts":[
+				{"id":"p1","messageID":"msg_a1","sessionID":"ses_new","type":"tool","tool":"grep","callID":"c1","state":{"status":"completed","input":{},"output":"","title":"","metadata":{},"time":{"start":1,"end":2}}}
+			]},
+			{"info":{"id":"msg_a2","role":"assistant","sessionID":"ses_new","parentID":"msg_u1","time":{"created":1}},"parts":[
+				{"id":"p2","messageID":"msg_a2","sessionID":"ses_new","type":"text","text":"A considerably longer explanation of the fix."}
+			]}
+		]`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := opencode.NewClient(opencode.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var seen []int
+	result, err := Replay(context.Background(), client, fixture(t), &ReplayOptions{
+		Model:  &opencode.SessionPromptParamsModel{ProviderID: "other", ModelID: "m2"},
+		OnTurn: func(tr TurnResult) { seen = append(seen, tr.Index) },
+	})
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if result.SessionID != "ses_new" || len(result.Turns) != 1 || len(seen) != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	if len(prompts) != 1 {
+		t.Fatalf("expected one prompt, got %d", len(prompts))
+	}
+	parts, _ := prompts[0]["parts"].([]any)
+	if len(parts) != 2 {
+		t.Fatalf("expected text and file parts to be replayed, got %v", prompts[0]["parts"])
+	}
+	if model, _ := prompts[0]["model"].(map[string]any); model["modelID"] != "m2" {
+		t.Errorf("expected model override, got %v", prompts[0]["model"])
+	}
+
+	turn := result.Turns[0]
+	if turn.OriginalMessageID != "msg_1" || turn.ReplayMessageID != "msg_u1" {
+		t.Errorf("unexpected message IDs %+v", turn)
+	}
+	if strings.Join(turn.Original.Tools, ",") != "bash,read" || strings.Join(turn.Replayed.Tools, ",") != "grep" {
+		t.Errorf("unexpected tool summaries %+v / %+v", turn.Original, turn.Replayed)
+	}
+
+	kinds := map[DivergenceKind]string{}
+	for _, d := range turn.Divergences {
+		kinds[d.Kind] = d.Detail
+	}
+	if kinds[DivergenceKindTools] != "not called: bash, read; newly called: grep" {
+		t.Errorf("unexpected tool divergence %q", kinds[DivergenceKindTools])
+	}
+	if kinds[DivergenceKindOutputLength] != "6 characters originally, 45 replayed" {
+		t.Errorf("unexpected length divergence %q", kinds[DivergenceKindOutputLength])
+	}
+}
+
+func TestLengthDiverges(t *testing.T) {
+	tests := []struct {
+		original, replayed int
+		want               bool
+	}{
+		{100, 100, false},
+		{100, 140, false},
+		{100, 160, true},
+		{100, 70, false},
+		{100, 60, true},
+		{0, 5, true},
+	}
+	for _, tt := range tests {
+		if got := lengthDiverges(tt.original, tt.replayed, 0.5); got != tt.want {
+			t.Errorf("lengthDiverges(%d, %d) = %v, want %v", tt.original, tt.replayed, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+// Package transcript exports opencode sessions as Markdown, self-contained
+// HTML or JSON for archiving and review.
+//
+//	t, err := transcript.Fetch(ctx, client, sessionID, &transcript.FetchOptions{IncludeChildren: true})
+//	...
+//	err = transcript.WriteMarkdown(os.Stdout, t)
+package transcript
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	opencode "github.com/dominicnunez/opencode-sdk-go"
+	"github.com/dominicnunez/opencode-sdk-go/internal/mstime"
+)
+
+// SchemaVersion is the version of the JSON layout written by WriteJSON. It
+// changes only when a field is removed or changes meaning.
+const SchemaVersion = 1
+
+// Transcript is an exported session tree.
+type Transcript struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exportedAt"`
+	Session    Session   `json:"session"`
+}
+
+// Session is one session with its messages and, optionally, its child
+// sessions.
+type Session struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Directory string     `json:"directory,omitempty"`
+	ParentID  string     `json:"parentID,omitempty"`
+	Created   time.Time  `json:"created"`
+	Updated   time.Time  `json:"updated"`
+	Usage     Usage      `json:"usage"`
+	Messages  []Message  `json:"messages"`
+	Children  []*Session `json:"children,omitempty"`
+}
+
+// Usage totals the cost and tokens of a session's assistant messages,
+// excluding child sessions.
+type Usage struct {
+	Cost   float64 `json:"cost"`
+	Tokens Tokens  `json:"tokens"`
+}
+
+type Tokens struct {
+	Input      int64 `json:"input"`
+	Output     int64 `json:"output"`
+	Reasoning  int64 `json:"reasoning"`
+	CacheRead  int64 `json:"cacheRead"`
+	CacheWrite int64 `json:"cacheWrite"`
+}
+
+// Message is one user or assistant message. Model, cost and token fields
+// are only set for assistant messages.
+type Message struct {
+	ID         string     `json:"id"`
+	Role       string     `json:"role"`
+	Created    time.Time  `json:"created"`
+	Completed  *time.Time `json:"completed,omitempty"`
+	Mode       string     `json:"mode,omitempty"`
+	ProviderID string     `json:"providerID,omitempty"`
+	ModelID    string     `json:"modelID,omitempty"`
+	Cost       float64    `json:"cost,omitempty"`
+	Tokens     *Tokens    `json:"tokens,omitempty"`
+	Error      *Error     `json:"error,omitempty"`
+	Parts      []Part     `json:"parts"`
+}
+
+// Part is one message part. Type is the opencode part type, and only the
+// field matching it is set.
+type Part struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Text      string    `json:"text,omitempty"`
+	Synthetic bool      `json:"synthetic,omitempty"`
+	Tool      *ToolCall `json:"tool,omitempty"`
+	File      *File     `json:"file,omitempty"`
+	Patch     *Patch    `json:"patch,omitempty"`
+	Step      *Step     `json:"step,omitempty"`
+	Agent     string    `json:"agent,omitempty"`
+	Retry     *Error    `json:"retry,omitempty"`
+}
+
+type ToolCall struct {
+	CallID  string         `json:"callID"`
+	Tool    string         `json:"tool"`
+	Status  string         `json:"status"`
+	Title   string         `json:"title,omitempty"`
+	Input   map[string]any `json:"input,omitempty"`
+	Output  string         `json:"output,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Started *time.Time     `json:"started,omitempty"`
+	Ended   *time.Time     `json:"ended,omitempty"`
+	// Attachments are files the tool produced.
+	Attachments []File `json:"attachments,omitempty"`
+}
+
+type File struct {
+	Filename string `json:"filename,omitempty"`
+	Mime     string `json:"mime"`
+	URL      string `json:"url"`
+}
+
+type Patch struct {
+	Hash  string   `json:"hash"`
+	Files []string `json:"files"`
+}
+
+// Step is the summary the server records when a model call finishes.
+type Step struct {
+	Reason string  `json:"reason,omitempty"`
+	Cost   float64 `json:"cost"`
+	Tokens Tokens  `json:"tokens"`
+}
+
+type Error struct {
+	Name    string `json:"name"`
+	Message string `json:"message,omitempty"`
+}
+
+// FetchOptions configures Fetch.
+type FetchOptions struct {
+	Directory *string
+	// IncludeChildren also exports sessions spawned from this one, such as
+	// subagent runs, recursively.
+	IncludeChildren bool
+}
+
+// Fetch loads a session and its messages and builds a Transcript.
+func Fetch(ctx context.Context, client *opencode.Client, sessionID string, opts *FetchOptions) (*Transcript, error) {
+	if client == nil {
+		return nil, fmt.Errorf("transcript: client is required")
+	}
+	if opts == nil {
+		opts = &FetchOptions{}
+	}
+	session, err := fetchSession(ctx, client, sessionID, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Transcript{Version: SchemaVersion, ExportedAt: time.Now().UTC(), Session: *session}, nil
+}
+
+func fetchSession(ctx context.Context, client *opencode.Client, sessionID string, opts *FetchOptions) (*Session, error) {
+	info, err := client.Session.Get(ctx, sessionID, &opencode.SessionGetParams{Directory: opts.Directory})
+	if err != nil {
+		return nil, fmt.Errorf("transcript: get session %s: %w", sessionID, err)
+	}
+	messages, err := client.Session.Messages(ctx, sessionID, &opencode.SessionMessagesParams{Directory: opts.Directory})
+	if err != nil {
+		return nil, fmt.Errorf("transcript: messages for session %s: %w", sessionID, err)
+	}
+	session := Build(*info, messages)
+
+	if opts.IncludeChildren {
+		children, err := client.Session.Children(ctx, sessionID, &opencode.SessionChildrenParams{Directory: opts.Directory})
+		if err != nil {
+			return nil, fmt.Errorf("transcript: children of session %s: %w", sessionID, err)
+		}
+		for _, child := range children {
+			c, err := fetchSession(ctx, client, child.ID, opts)
+			if err != nil {
+				return nil, err
+			}
+			session.Children = append(session.Children, c)
+		}
+	}
+	return session, nil
+}
+
+// Build converts a session and its messages, as returned by
+// SessionService.Get and SessionService.Messages, without fetching anything.
+// Parts that fail to decode are kept with only their ID and type.
+func Build(info opencode.Session, messages []opencode.SessionMessagesResponse) *Session {
+	session := &Session{
+		ID:        info.ID,
+		Title:     info.Title,
+		Directory: info.Directory,
+		Created:   mstime.Time(info.Time.Created),
+		Updated:   mstime.Time(info.Time.Updated),
+		Messages:  make([]Message, 0, len(messages)),
+	}
+	if info.ParentID != nil {
+		session.ParentID = *info.ParentID
+	}
+	for _, msg := range messages {
+		m := buildMessage(msg.Info)
+		for _, part := range msg.Parts {
+			m.Parts = append(m.Parts, buildPart(part))
+		}
+		if m.Tokens != nil {
+			session.Usage.Cost += m.Cost
+			session.Usage.Tokens.add(*m.Tokens)
+		}
+		session.Messages = append(session.Messages, m)
+	}
+	return session
+}
+
+func buildMessage(info opencode.Message) Message {
+	m := Message{ID: info.ID, Role: string(info.Role), Parts: []Part{}}
+	switch info.Role {
+	case opencode.MessageRoleUser:
+		if user, err := info.AsUser(); err == nil {
+			m.Created = mstime.Time(user.Time.Created)
+		}
+	case opencode.MessageRoleAssistant:
+		assistant, err := info.AsAssistant()
+		if err != nil {
+			break
+		}
+		m.Created = mstime.Time(assistant.Time.Created)
+		if assistant.Time.Completed > 0 {
+			completed := mstime.Time(assistant.Time.Completed)
+			m.Completed = &completed
+		}
+		m.Mode = assistant.Mode
+		m.ProviderID = assistant.ProviderID
+		m.ModelID = assistant.ModelID
+		m.Cost = assistant.Cost
+		m.Tokens = &Tokens{
+			Input:      assistant.Tokens.Input,
+			Output:     assistant.Tokens.Output,
+			Reasoning:  assistant.Tokens.Reasoning,
+			CacheRead:  assistant.Tokens.Cache.Read,
+			CacheWrite: assistant.Tokens.Cache.Write,
+		}
+		if assistant.Error.Name != "" {
+			m.Error = unionError(string(assistant.Error.Name), assistant.Error)
+		}
+	}
+	return m
+}
+
+func buildPart(part opencode.Part) Part {
+	p := Part{ID: part.ID, Type: string(part.Type)}
+	switch part.Type {
+	case opencode.PartTypeText:
+		if text, err := part.AsText(); err == nil {
+			p.Text = text.Text
+			p.Synthetic = text.Synthetic
+		}
+	case opencode.PartTypeReasoning:
+		if reasoning, err := part.AsReasoning(); err == nil {
+			p.Text = reasoning.Text
+		}
+	case opencode.PartTypeTool:
+		if tool, err := part.AsTool(); err == nil {
+			p.Tool = buildToolCall(tool)
+		}
+	case opencode.PartTypeFile:
+		if file, err := part.AsFile(); err == nil {
+			p.File = &File{Filename: file.Filename, Mime: file.Mime, URL: file.URL}
+		}
+	case opencode.PartTypePatch:
+		if patch, err := part.AsPatch(); err == nil {
+			p.Patch = &Patch{Hash: patch.Hash, Files: patch.Files}
+		}
+	case opencode.PartTypeStepFinish:
+		if step, err := part.AsStepFinish(); err == nil {
+			p.Step = &Step{
+				Reason: step.Reason,
+				Cost:   step.Cost,
+				Tokens: Tokens{
+					Input:      step.Tokens.Input,
+					Output:     step.Tokens.Output,
+					Reasoning:  step.Tokens.Reasoning,
+					CacheRead:  step.Tokens.Cache.Read,
+					CacheWrite: step.Tokens.Cache.Write,
+				},
+			}
+		}
+	case opencode.PartTypeAgent:
+		if agent, err := part.AsAgent(); err == nil {
+			p.Agent = agent.Name
+		}
+	case opencode.PartTypeRetry:
+		if retry, err := part.AsRetry(); err == nil {
+			p.Retry = &Error{Name: string(retry.Error.Name), Message: retry.Error.Data.Message}
+		}
+	}
+	return p
+}
+
+func buildToolCall(tool *opencode.ToolPart) *ToolCall {
+	call := &ToolCall{CallID: tool.CallID, Tool: tool.Tool, Status: string(tool.State.Status)}
+	switch tool.State.Status {
+	case opencode.ToolPartStateStatusRunning:
+		if state, err := tool.State.AsRunning(); err == nil {
+			call.Title = state.Title
+			if input, ok := state.Input.(map[string]any); ok {
+				call.Input = input
+			}
+			call.Started = mstime.Ptr(state.Time.Start)
+		}
+	case opencode.ToolPartStateStatusCompleted:
+		if state, err := tool.State.AsCompleted(); err == nil {
+			call.Title = state.Title
+			call.Input = state.Input
+			call.Output = state.Output
+			call.Started = mstime.Ptr(state.Time.Start)
+			call.Ended = mstime.Ptr(state.Time.End)
+			for _, a := range state.Attachments {
+				call.Attachments = append(call.Attachments, File{Filename: a.Filename, Mime: a.Mime, URL: a.URL})
+			}
+		}
+	case opencode.ToolPartStateStatusError:
+		if state, err := tool.State.AsError(); err == nil {
+			call.Input = state.Input
+			call.Error = state.Error
+			call.Started = mstime.Ptr(state.Time.Start)
+			call.Ended = mstime.Ptr(state.Time.End)
+		}
+	}
+	return call
+}
+
+// unionError extracts the message from an error union's data payload.
+func unionError(name string, v json.Marshaler) *Error {
+	e := &Error{Name: name}
+	raw, err := v.MarshalJSON()
+	if err != nil {
+		return e
+	}
+	var payload struct {
+		Data struct {
+			Message string `json:"message"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(raw, &payload) == nil {
+		e.Message = payload.Data.Message
+	}
+	return e
+}
+
+func (t *Tokens) add(o Tokens) {
+	t.Input += o.Input
+	t.Output += o.Output
+	t.Reasoning += o.Reasoning
+	t.CacheRead += o.CacheRead
+	t.CacheWrite += o.CacheWrite
+}
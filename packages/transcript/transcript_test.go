@@ -0,0 +1,197 @@
+package transcript
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	opencode "github.com/dominicnunez/opencode-sdk-go"
+)
+
+const sessionFixture = `{"id":"ses_1","title":"Fix build","directory":"/src","time":{"created":1700000000000,"updated":1700000005000}}`
+
+const messagesFixture = `[
+	{"info":{"id":"msg_1","role":"user","sessionID":"ses_1","time":{"created":1700000000000}},"parts":[
+		{"id":"prt_1","messageID":"msg_1","sessionID":"ses_1","type":"text","text":"Why does <script> fail?"},
+		{"id":"prt_2","messageID":"msg_1","sessionID":"ses_1","type":"file","mime":"image/png","filename":"shot.png","url":"data:image/png;base64,iVBORw0KGgo="}
+	]},
+	{"info":{"id":"msg_2","role":"assistant","sessionID":"ses_1","providerID":"acme","modelID":"m1","mode":"build","cost":0.25,
+		"time":{"created":1700000001000,"completed":1700000004000},
+		"tokens":{"input":100,"output":40,"reasoning":5,"cache":{"read":10,"write":2}},
+		"error":{"name":"MessageOutputLengthError","data":{"message":"too long"}}},"parts":[
+		{"id":"prt_3","messageID":"msg_2","sessionID":"ses_1","type":"reasoning","text":"look at go.mod","time":{"start":1}},
+		{"id":"prt_4","messageID":"msg_2","sessionID":"ses_1","type":"tool","tool":"bash","callID":"call_1","state":{"status":"completed","input":{"command":"go build ./..."},"output":"ok ` + "```" + ` done","title":"go build","metadata":{},"time":{"start":1700000002000,"end":1700000003000}}},
+		{"id":"prt_5","messageID":"msg_2","sessionID":"ses_1","type":"tool","tool":"read","callID":"call_2","state":{"status":"error","input":{"path":"x"},"error":"no such file","time":{"start":1,"end":2}}},
+		{"id":"prt_6","messageID":"msg_2","sessionID":"ses_1","type":"patch","hash":"abc123","files":["go.mod"]},
+		{"id":"prt_7","messageID":"msg_2","sessionID":"ses_1","type":"text","text":"Fixed."},
+		{"id":"prt_8","messageID":"msg_2","sessionID":"ses_1","type":"step-finish","reason":"stop","cost":0.25,"tokens":{"input":100,"output":40,"reasoning":5,"cache":{"read":10,"write":2}}}
+	]}
+]`
+
+func fixture(t *testing.T) *Transcript {
+	t.Helper()
+	var info opencode.Session
+	if err := json.Unmarshal([]byte(sessionFixture), &info); err != nil {
+		t.Fatalf("unmarshal session: %v", err)
+	}
+	var msgs []opencode.SessionMessagesResponse
+	if err := json.Unmarshal([]byte(messagesFixture), &msgs); err != nil {
+		t.Fatalf("unmarshal messages: %v", err)
+	}
+	return &Transcript{Version: SchemaVersion, Session: *Build(info, msgs)}
+}
+
+func TestBuild(t *testing.T) {
+	s := fixture(t).Session
+
+	if len(s.Messages) != 2 || len(s.Messages[1].Parts) != 6 {
+		t.Fatalf("unexpected shape: %+v", s)
+	}
+	if s.Usage.Cost != 0.25 || s.Usage.Tokens.Input != 100 || s.Usage.Tokens.CacheRead != 10 {
+		t.Errorf("unexpected usage %+v", s.Usage)
+	}
+
+	assistant := s.Messages[1]
+	if assistant.Error == nil || assistant.Error.Name != "MessageOutputLengthError" || assistant.Error.Message != "too long" {
+		t.Errorf("unexpected error %+v", assistant.Error)
+	}
+	if assistant.Completed == nil || assistant.Completed.Unix() != 1700000004 {
+		t.Errorf("unexpected completion time %v", assistant.Completed)
+	}
+
+	tool := assistant.Parts[1].Tool
+	if tool == nil || tool.Status != "completed" || tool.Input["command"] != "go build ./..." || tool.Ended == nil {
+		t.Fatalf("unexpected tool call %+v", tool)
+	}
+	if failed := assistant.Parts[2].Tool; failed == nil || failed.Error != "no such file" {
+		t.Fatalf("unexpected failed tool call %+v", failed)
+	}
+	if patch := assistant.Parts[3].Patch; patch == nil || patch.Hash != "abc123" {
+		t.Fatalf("unexpected patch %+v", patch)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, fixture(t)); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# Fix build\n",
+		"## User · 2023-11-14T22:13:20Z",
+		"## Assistant (acme/m1)",
+		"> **Reasoning**\n>\n> look at go.mod",
+		"**Tool `bash`** go build (completed)",
+		"\"command\": \"go build ./...\"",
+		"````text\nok ``` done\n````",
+		"**Tool error:** no such file",
+		"shot.png (image/png, inline)",
+		"**Patch** `abc123`",
+		"_Step finished (stop): $0.2500",
+		"**Error:** MessageOutputLengthError: too long",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown missing %q\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "iVBORw0KGgo") {
+		t.Error("markdown should not embed data URLs")
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, fixture(t)); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "<script>") {
+		t.Error("expected user text to be escaped")
+	}
+	for _, want := range []string{
+		"<title>Fix build</title>",
+		"Why does &lt;script&gt; fail?",
+		`<img src="data:image/png;base64,iVBORw0KGgo="`,
+		"<strong>bash</strong> go build",
+		`<p class="error">no such file</p>`,
+		"MessageOutputLengthError: too long",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html missing %q", want)
+		}
+	}
+}
+
+func TestWriteJSON_Schema(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, fixture(t)); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var decoded Transcript
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if decoded.Version != SchemaVersion || decoded.Session.Messages[1].Parts[1].Tool.CallID != "call_1" {
+		t.Fatalf("unexpected round trip %+v", decoded)
+	}
+	for _, key := range []string{`"version": 1`, `"callID": "call_1"`, `"cacheRead": 10`, `"type": "step-finish"`} {
+		if !strings.Contains(buf.String(), key) {
+			t.Errorf("json missing %s", key)
+		}
+	}
+}
+
+func TestFetch_IncludesChildren(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/session/ses_1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(sessionFixture))
+	})
+	mux.HandleFunc("/session/ses_1/message", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(messagesFixture))
+	})
+	mux.HandleFunc("/session/ses_1/children", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"ses_2","title":"subtask","parentID":"ses_1","time":{"created":1,"updated":1}}]`))
+	})
+	mux.HandleFunc("/session/ses_2", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"ses_2","title":"subtask","parentID":"ses_1","time":{"created":1,"updated":1}}`))
+	})
+	mux.HandleFunc("/session/ses_2/message", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("/session/ses_2/children", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := opencode.NewClient(opencode.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	tr, err := Fetch(context.Background(), client, "ses_1", &FetchOptions{IncludeChildren: true})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(tr.Session.Children) != 1 || tr.Session.Children[0].ParentID != "ses_1" {
+		t.Fatalf("expected one child session, got %+v", tr.Session.Children)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, tr); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\n## subtask\n") {
+		t.Errorf("expected child session under a level-2 heading\n%s", buf.String())
+	}
+}
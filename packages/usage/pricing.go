@@ -0,0 +1,96 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+
+	opencode "github.com/dominicnunez/opencode-sdk-go"
+)
+
+// Price is a model's price in USD per million tokens, as published in the
+// provider configuration. Reasoning tokens are billed at the output price.
+type Price struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheRead  float64 `json:"cacheRead"`
+	CacheWrite float64 `json:"cacheWrite"`
+}
+
+// Cost returns the price of t.
+func (p Price) Cost(t Tokens) float64 {
+	return (float64(t.Input)*p.Input +
+		float64(t.Output+t.Reasoning)*p.Output +
+		float64(t.CacheRead)*p.CacheRead +
+		float64(t.CacheWrite)*p.CacheWrite) / 1_000_000
+}
+
+// Pricing maps models to prices. The zero Pricing is empty and ready to use.
+// A nil *Pricing knows no prices and can be read but not Set.
+type Pricing struct {
+	prices map[Model]Price
+}
+
+// NewPricing returns the prices of every model in providers, as returned by
+// Config.Providers.
+func NewPricing(providers []opencode.ConfigProvider) *Pricing {
+	p := &Pricing{prices: make(map[Model]Price)}
+	for _, provider := range providers {
+		for id, model := range provider.Models {
+			if model.ID != "" {
+				id = model.ID
+			}
+			p.prices[Model{ProviderID: provider.ID, ModelID: id}] = Price{
+				Input:      model.Cost.Input,
+				Output:     model.Cost.Output,
+				CacheRead:  model.Cost.CacheRead,
+				CacheWrite: model.Cost.CacheWrite,
+			}
+		}
+	}
+	return p
+}
+
+// FetchPricing loads model prices from Config.Providers.
+func FetchPricing(ctx context.Context, client *opencode.Client, directory *string) (*Pricing, error) {
+	if client == nil {
+		return nil, fmt.Errorf("usage: client is required")
+	}
+	resp, err := client.Config.Providers(ctx, &opencode.ConfigProviderListParams{Directory: directory})
+	if err != nil {
+		return nil, fmt.Errorf("usage: providers: %w", err)
+	}
+	return NewPricing(resp.Providers), nil
+}
+
+// Set records the price of a model, replacing any configured price. Use it
+// for models whose provider configuration carries no cost. Set panics on a
+// nil *Pricing; start from NewPricing or a zero Pricing instead.
+func (p *Pricing) Set(model Model, price Price) {
+	if p.prices == nil {
+		p.prices = make(map[Model]Price)
+	}
+	p.prices[model] = price
+}
+
+// Price returns the price of a model and whether one is known. A model whose
+// configured prices are all zero counts as unknown.
+func (p *Pricing) Price(model Model) (Price, bool) {
+	if p == nil {
+		return Price{}, false
+	}
+	price, ok := p.prices[model]
+	if !ok || price == (Price{}) {
+		return Price{}, false
+	}
+	return price, true
+}
+
+// Cost returns the price of t on model and whether the model's price is
+// known.
+func (p *Pricing) Cost(model Model, t Tokens) (float64, bool) {
+	price, ok := p.Price(model)
+	if !ok {
+		return 0, false
+	}
+	return price.Cost(t), true
+}
@@ -0,0 +1,335 @@
+// Package usage totals the tokens and cost of opencode sessions per message,
+// per session and per session tree, with breakdowns by model and agent.
+//
+//	pricing, err := usage.FetchPricing(ctx, client, nil)
+//	tree, err := usage.FetchTree(ctx, client, sessionID, &usage.Options{Pricing: pricing})
+//	fmt.Println(tree.Total.Cost, tree.Total.Tokens.Total())
+package usage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	opencode "github.com/dominicnunez/opencode-sdk-go"
+)
+
+// Tokens counts tokens by kind.
+type Tokens struct {
+	Input      int64 `json:"input"`
+	Output     int64 `json:"output"`
+	Reasoning  int64 `json:"reasoning"`
+	CacheRead  int64 `json:"cacheRead"`
+	CacheWrite int64 `json:"cacheWrite"`
+}
+
+// Total returns the sum of all token kinds.
+func (t Tokens) Total() int64 {
+	return t.Input + t.Output + t.Reasoning + t.CacheRead + t.CacheWrite
+}
+
+// Add returns the element-wise sum of t and o.
+func (t Tokens) Add(o Tokens) Tokens {
+	return Tokens{
+		Input:      t.Input + o.Input,
+		Output:     t.Output + o.Output,
+		Reasoning:  t.Reasoning + o.Reasoning,
+		CacheRead:  t.CacheRead + o.CacheRead,
+		CacheWrite: t.CacheWrite + o.CacheWrite,
+	}
+}
+
+func (t Tokens) zero() bool {
+	return t == Tokens{}
+}
+
+// Usage is a token and cost total.
+type Usage struct {
+	Tokens Tokens `json:"tokens"`
+	// Cost is in USD. It includes EstimatedCost.
+	Cost float64 `json:"cost"`
+	// EstimatedCost is the part of Cost recomputed from model pricing for
+	// messages the server reported no cost for.
+	EstimatedCost float64 `json:"estimatedCost,omitempty"`
+	// Messages is the number of assistant messages counted.
+	Messages int `json:"messages"`
+}
+
+// Add returns the sum of u and o.
+func (u Usage) Add(o Usage) Usage {
+	return Usage{
+		Tokens:        u.Tokens.Add(o.Tokens),
+		Cost:          u.Cost + o.Cost,
+		EstimatedCost: u.EstimatedCost + o.EstimatedCost,
+		Messages:      u.Messages + o.Messages,
+	}
+}
+
+// Model identifies a model by provider and model ID.
+type Model struct {
+	ProviderID string `json:"providerID"`
+	ModelID    string `json:"modelID"`
+}
+
+func (m Model) String() string {
+	return m.ProviderID + "/" + m.ModelID
+}
+
+// MarshalText encodes m as "provider/model" so it can key a JSON object.
+func (m Model) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText decodes "provider/model". Model IDs may themselves contain
+// slashes; the provider ID ends at the first one.
+func (m *Model) UnmarshalText(text []byte) error {
+	provider, model, ok := strings.Cut(string(text), "/")
+	if !ok {
+		return fmt.Errorf("usage: invalid model %q, expected provider/model", text)
+	}
+	m.ProviderID, m.ModelID = provider, model
+	return nil
+}
+
+// MessageUsage is the usage of one assistant message.
+type MessageUsage struct {
+	MessageID string `json:"messageID"`
+	SessionID string `json:"sessionID"`
+	Model     Model  `json:"model"`
+	// Agent is the agent that produced the message.
+	Agent string `json:"agent"`
+	Usage
+}
+
+// ForMessage computes the usage of an assistant message. The message totals
+// are used when the server has filled them in; otherwise the step-finish
+// parts are summed, which covers messages still in progress. If the cost is
+// still zero and pricing knows the model, the cost is estimated from the
+// tokens. pricing may be nil.
+func ForMessage(msg *opencode.AssistantMessage, parts []opencode.Part, pricing *Pricing) MessageUsage {
+	mu := MessageUsage{
+		MessageID: msg.ID,
+		SessionID: msg.SessionID,
+		Model:     Model{ProviderID: msg.ProviderID, ModelID: msg.ModelID},
+		Agent:     msg.Mode,
+	}
+	mu.Messages = 1
+	mu.Tokens = Tokens{
+		Input:      msg.Tokens.Input,
+		Output:     msg.Tokens.Output,
+		Reasoning:  msg.Tokens.Reasoning,
+		CacheRead:  msg.Tokens.Cache.Read,
+		CacheWrite: msg.Tokens.Cache.Write,
+	}
+	mu.Cost = msg.Cost
+
+	if mu.Tokens.zero() || mu.Cost == 0 {
+		var stepTokens Tokens
+		var stepCost float64
+		for _, part := range parts {
+			if part.Type != opencode.PartTypeStepFinish {
+				continue
+			}
+			step, err := part.AsStepFinish()
+			if err != nil {
+				continue
+			}
+			stepTokens = stepTokens.Add(Tokens{
+				Input:      step.Tokens.Input,
+				Output:     step.Tokens.Output,
+				Reasoning:  step.Tokens.Reasoning,
+				CacheRead:  step.Tokens.Cache.Read,
+				CacheWrite: step.Tokens.Cache.Write,
+			})
+			stepCost += step.Cost
+		}
+		if mu.Tokens.zero() {
+			mu.Tokens = stepTokens
+		}
+		if mu.Cost == 0 {
+			mu.Cost = stepCost
+		}
+	}
+
+	if mu.Cost == 0 && !mu.Tokens.zero() {
+		if cost, ok := pricing.Cost(mu.Model, mu.Tokens); ok {
+			mu.Cost = cost
+			mu.EstimatedCost = cost
+		}
+	}
+	return mu
+}
+
+// Report is a usage total with breakdowns by model and by agent.
+type Report struct {
+	Total   Usage            `json:"total"`
+	ByModel map[Model]Usage  `json:"byModel"`
+	ByAgent map[string]Usage `json:"byAgent"`
+}
+
+// Add counts one message into the report.
+func (r *Report) Add(mu MessageUsage) {
+	if r.ByModel == nil {
+		r.ByModel = make(map[Model]Usage)
+	}
+	if r.ByAgent == nil {
+		r.ByAgent = make(map[string]Usage)
+	}
+	r.Total = r.Total.Add(mu.Usage)
+	r.ByModel[mu.Model] = r.ByModel[mu.Model].Add(mu.Usage)
+	r.ByAgent[mu.Agent] = r.ByAgent[mu.Agent].Add(mu.Usage)
+}
+
+// Merge adds every total in o to r.
+func (r *Report) Merge(o Report) {
+	if r.ByModel == nil {
+		r.ByModel = make(map[Model]Usage)
+	}
+	if r.ByAgent == nil {
+		r.ByAgent = make(map[string]Usage)
+	}
+	r.Total = r.Total.Add(o.Total)
+	for model, u := range o.ByModel {
+		r.ByModel[model] = r.ByModel[model].Add(u)
+	}
+	for agent, u := range o.ByAgent {
+		r.ByAgent[agent] = r.ByAgent[agent].Add(u)
+	}
+}
+
+// Models returns the models in the report, most expensive first.
+func (r *Report) Models() []Model {
+	models := make([]Model, 0, len(r.ByModel))
+	for model := range r.ByModel {
+		models = append(models, model)
+	}
+	sort.Slice(models, func(i, j int) bool {
+		ci, cj := r.ByModel[models[i]].Cost, r.ByModel[models[j]].Cost
+		if ci != cj {
+			return ci > cj
+		}
+		return models[i].String() < models[j].String()
+	})
+	return models
+}
+
+// Agents returns the agents in the report, most expensive first.
+func (r *Report) Agents() []string {
+	agents := make([]string, 0, len(r.ByAgent))
+	for agent := range r.ByAgent {
+		agents = append(agents, agent)
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		ci, cj := r.ByAgent[agents[i]].Cost, r.ByAgent[agents[j]].Cost
+		if ci != cj {
+			return ci > cj
+		}
+		return agents[i] < agents[j]
+	})
+	return agents
+}
+
+// SessionUsage is the usage of one session, excluding its child sessions.
+type SessionUsage struct {
+	SessionID string         `json:"sessionID"`
+	Messages  []MessageUsage `json:"messages"`
+	Report
+}
+
+// ForSession computes the usage of a session from its messages as returned
+// by Session.Messages. User messages are skipped. pricing may be nil.
+func ForSession(sessionID string, messages []opencode.SessionMessagesResponse, pricing *Pricing) *SessionUsage {
+	su := &SessionUsage{SessionID: sessionID}
+	for _, msg := range messages {
+		su.addMessage(msg, pricing)
+	}
+	return su
+}
+
+func (su *SessionUsage) addMessage(msg opencode.SessionMessagesResponse, pricing *Pricing) {
+	assistant, err := msg.Info.AsAssistant()
+	if err != nil {
+		return
+	}
+	mu := ForMessage(assistant, msg.Parts, pricing)
+	su.Messages = append(su.Messages, mu)
+	su.Add(mu)
+}
+
+// TreeUsage is the usage of a session and all of its descendants. Report
+// totals the whole subtree; Session holds the session's own usage.
+type TreeUsage struct {
+	Session  *SessionUsage `json:"session"`
+	Children []*TreeUsage  `json:"children,omitempty"`
+	Report
+}
+
+// Options configures FetchSession and FetchTree.
+type Options struct {
+	Directory *string
+	// Pricing is used to estimate the cost of messages the server reported
+	// none for. Without it such messages count as free.
+	Pricing *Pricing
+	// MaxDepth limits how many levels of child sessions FetchTree visits.
+	// Zero visits the whole tree.
+	MaxDepth int
+}
+
+// FetchSession fetches the messages of a session and computes its usage.
+func FetchSession(ctx context.Context, client *opencode.Client, sessionID string, opts *Options) (*SessionUsage, error) {
+	if client == nil {
+		return nil, fmt.Errorf("usage: client is required")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	messages := client.Session.MessagesStreaming(ctx, sessionID, &opencode.SessionMessagesParams{Directory: opts.Directory})
+	defer func() { _ = messages.Close() }()
+
+	su := &SessionUsage{SessionID: sessionID}
+	for messages.Next() {
+		su.addMessage(messages.Current(), opts.Pricing)
+	}
+	if err := messages.Err(); err != nil {
+		return nil, fmt.Errorf("usage: messages for session %s: %w", sessionID, err)
+	}
+	return su, nil
+}
+
+// FetchTree computes the usage of a session and every session below it, such
+// as the subagent sessions it spawned.
+func FetchTree(ctx context.Context, client *opencode.Client, sessionID string, opts *Options) (*TreeUsage, error) {
+	if client == nil {
+		return nil, fmt.Errorf("usage: client is required")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	tree, err := client.Session.Tree(ctx, sessionID, &opencode.SessionTreeParams{
+		Directory: opts.Directory,
+		MaxDepth:  opts.MaxDepth,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("usage: %w", err)
+	}
+	return fetchTree(ctx, client, tree, opts)
+}
+
+func fetchTree(ctx context.Context, client *opencode.Client, node *opencode.SessionTreeNode, opts *Options) (*TreeUsage, error) {
+	su, err := FetchSession(ctx, client, node.Session.ID, opts)
+	if err != nil {
+		return nil, err
+	}
+	tu := &TreeUsage{Session: su}
+	tu.Merge(su.Report)
+	for _, child := range node.Children {
+		cu, err := fetchTree(ctx, client, child, opts)
+		if err != nil {
+			return nil, err
+		}
+		tu.Children = append(tu.Children, cu)
+		tu.Merge(cu.Report)
+	}
+	return tu, nil
+}
@@ -0,0 +1,201 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	opencode "github.com/dominicnunez/opencode-sdk-go"
+)
+
+const rootMessages = `[
+	{"info":{"id":"msg_1","role":"user","sessionID":"ses_root","time":{"created":1}},"parts":[]},
+	{"info":{"id":"msg_2","role":"assistant","sessionID":"ses_root","providerID":"anthropic","modelID":"claude","mode":"build","cost":0.5,
+		"time":{"created":2,"completed":3},"tokens":{"input":1000,"output":200,"reasoning":50,"cache":{"read":400,"write":100}}},"parts":[]},
+	{"info":{"id":"msg_3","role":"assistant","sessionID":"ses_root","providerID":"local","modelID":"llama","mode":"plan","cost":0,
+		"time":{"created":4},"tokens":{"input":0,"output":0,"reasoning":0,"cache":{"read":0,"write":0}}},"parts":[
+		{"id":"prt_1","messageID":"msg_3","sessionID":"ses_root","type":"step-finish","reason":"tool-calls","cost":0,"tokens":{"input":300,"output":100,"reasoning":0,"cache":{"read":0,"write":0}}},
+		{"id":"prt_2","messageID":"msg_3","sessionID":"ses_root","type":"step-finish","reason":"stop","cost":0,"tokens":{"input":700,"output":100,"reasoning":0,"cache":{"read":1000,"write":0}}}
+	]}
+]`
+
+const childMessages = `[
+	{"info":{"id":"msg_9","role":"assistant","sessionID":"ses_child","providerID":"anthropic","modelID":"claude","mode":"general","cost":0.25,
+		"time":{"created":5,"completed":6},"tokens":{"input":500,"output":100,"reasoning":0,"cache":{"read":0,"write":0}}},"parts":[]}
+]`
+
+const providersFixture = `{"default":{},"providers":[
+	{"id":"local","name":"Local","env":[],"models":{"llama":{"id":"llama","name":"Llama","cost":{"input":1,"output":4,"cache_read":0.5}}}},
+	{"id":"anthropic","name":"Anthropic","env":[],"models":{"claude":{"id":"claude","name":"Claude","cost":{"input":3,"output":15}}}}
+]}`
+
+func loadMessages(t *testing.T, raw string) []opencode.SessionMessagesResponse {
+	t.Helper()
+	var msgs []opencode.SessionMessagesResponse
+	if err := json.Unmarshal([]byte(raw), &msgs); err != nil {
+		t.Fatalf("unmarshal messages: %v", err)
+	}
+	return msgs
+}
+
+func loadPricing(t *testing.T) *Pricing {
+	t.Helper()
+	var resp opencode.ConfigProviderListResponse
+	if err := json.Unmarshal([]byte(providersFixture), &resp); err != nil {
+		t.Fatalf("unmarshal providers: %v", err)
+	}
+	return NewPricing(resp.Providers)
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestForSession(t *testing.T) {
+	su := ForSession("ses_root", loadMessages(t, rootMessages), loadPricing(t))
+
+	if len(su.Messages) != 2 || su.Total.Messages != 2 {
+		t.Fatalf("expected 2 assistant messages, got %+v", su.Messages)
+	}
+
+	reported := su.Messages[0]
+	if reported.Cost != 0.5 || reported.EstimatedCost != 0 || reported.Tokens.CacheWrite != 100 || reported.Agent != "build" {
+		t.Errorf("unexpected reported usage %+v", reported)
+	}
+
+	// Tokens come from the step-finish parts and the cost from llama's
+	// price: (1000*1 + 200*4 + 1000*0.5) / 1e6.
+	estimated := su.Messages[1]
+	if estimated.Tokens != (Tokens{Input: 1000, Output: 200, CacheRead: 1000}) {
+		t.Errorf("unexpected step tokens %+v", estimated.Tokens)
+	}
+	if !approx(estimated.Cost, 0.0023) || estimated.Cost != estimated.EstimatedCost {
+		t.Errorf("unexpected estimated cost %v / %v", estimated.Cost, estimated.EstimatedCost)
+	}
+
+	if !approx(su.Total.Cost, 0.5023) || !approx(su.Total.EstimatedCost, 0.0023) {
+		t.Errorf("unexpected totals %+v", su.Total)
+	}
+	if su.Total.Tokens.Total() != 1000+200+50+400+100+2200 {
+		t.Errorf("unexpected token total %d", su.Total.Tokens.Total())
+	}
+	if got := su.ByAgent["plan"]; got.Messages != 1 || !approx(got.Cost, 0.0023) {
+		t.Errorf("unexpected plan breakdown %+v", got)
+	}
+	if models := su.Models(); len(models) != 2 || models[0].String() != "anthropic/claude" {
+		t.Errorf("expected anthropic/claude to lead, got %v", models)
+	}
+}
+
+func TestForSession_WithoutPricing(t *testing.T) {
+	su := ForSession("ses_root", loadMessages(t, rootMessages), nil)
+	if su.Total.Cost != 0.5 || su.Total.EstimatedCost != 0 {
+		t.Errorf("expected only reported cost without pricing, got %+v", su.Total)
+	}
+}
+
+func TestPricing(t *testing.T) {
+	p := loadPricing(t)
+	price, ok := p.Price(Model{ProviderID: "anthropic", ModelID: "claude"})
+	if !ok || price.Output != 15 {
+		t.Fatalf("unexpected price %+v (%v)", price, ok)
+	}
+	// Reasoning is billed as output.
+	if got := price.Cost(Tokens{Input: 1_000_000, Reasoning: 1_000_000}); !approx(got, 18) {
+		t.Errorf("expected 18, got %v", got)
+	}
+	if _, ok := p.Price(Model{ProviderID: "x", ModelID: "y"}); ok {
+		t.Error("expected unknown model")
+	}
+
+	p.Set(Model{ProviderID: "x", ModelID: "y"}, Price{Input: 2})
+	if cost, ok := p.Cost(Model{ProviderID: "x", ModelID: "y"}, Tokens{Input: 500_000}); !ok || !approx(cost, 1) {
+		t.Errorf("expected custom price, got %v (%v)", cost, ok)
+	}
+	var none *Pricing
+	if _, ok := none.Cost(Model{}, Tokens{Input: 1}); ok {
+		t.Error("expected nil pricing to know nothing")
+	}
+
+	var zero Pricing
+	zero.Set(Model{ProviderID: "x", ModelID: "y"}, Price{Output: 4})
+	if price, ok := zero.Price(Model{ProviderID: "x", ModelID: "y"}); !ok || price.Output != 4 {
+		t.Errorf("expected zero Pricing to accept Set, got %+v (%v)", price, ok)
+	}
+}
+
+func TestReport_JSON(t *testing.T) {
+	su := ForSession("ses_root", loadMessages(t, rootMessages), nil)
+	data, err := json.Marshal(su.Report)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var back Report
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if back.ByModel[Model{ProviderID: "anthropic", ModelID: "claude"}].Cost != 0.5 {
+		t.Errorf("model key did not round-trip: %s", data)
+	}
+}
+
+func TestFetchTree(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/config/providers":
+			_, _ = w.Write([]byte(providersFixture))
+		case "/session/ses_root":
+			_, _ = w.Write([]byte(`{"id":"ses_root","title":"root"}`))
+		case "/session/ses_root/children":
+			_, _ = w.Write([]byte(`[{"id":"ses_child","title":"child","parentID":"ses_root"}]`))
+		case "/session/ses_child/children":
+			_, _ = w.Write([]byte(`[]`))
+		case "/session/ses_root/message":
+			_, _ = w.Write([]byte(rootMessages))
+		case "/session/ses_child/message":
+			_, _ = w.Write([]byte(childMessages))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client, err := opencode.NewClient(opencode.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	pricing, err := FetchPricing(ctx, client, nil)
+	if err != nil {
+		t.Fatalf("FetchPricing: %v", err)
+	}
+	tree, err := FetchTree(ctx, client, "ses_root", &Options{Pricing: pricing})
+	if err != nil {
+		t.Fatalf("FetchTree: %v", err)
+	}
+
+	if !approx(tree.Session.Total.Cost, 0.5023) {
+		t.Errorf("unexpected own cost %v", tree.Session.Total.Cost)
+	}
+	if len(tree.Children) != 1 || tree.Children[0].Session.SessionID != "ses_child" {
+		t.Fatalf("unexpected children %+v", tree.Children)
+	}
+	if !approx(tree.Total.Cost, 0.7523) || tree.Total.Messages != 3 {
+		t.Errorf("unexpected tree total %+v", tree.Total)
+	}
+	if got := tree.ByModel[Model{ProviderID: "anthropic", ModelID: "claude"}]; got.Messages != 2 || !approx(got.Cost, 0.75) {
+		t.Errorf("unexpected model breakdown %+v", got)
+	}
+	if agents := tree.Agents(); strings.Join(agents, ",") != "build,general,plan" {
+		t.Errorf("unexpected agent order %v", agents)
+	}
+
+	if _, err := FetchTree(ctx, client, "ses_missing", nil); err == nil {
+		t.Error("expected error for unknown session")
+	}
+}
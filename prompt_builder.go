@@ -0,0 +1,287 @@
+package opencode
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PromptBuilder assembles SessionPromptParams one part at a time. Methods
+// record invalid input instead of failing immediately; Build reports every
+// problem at once.
+//
+//	params, err := opencode.NewPromptBuilder().
+//		Text("Explain this function").
+//		Symbol(sym).
+//		Model("anthropic", "claude-sonnet-4").
+//		DisableTool("bash").
+//		Build()
+//	...
+//	resp, err := client.Session.Prompt(ctx, sessionID, params)
+type PromptBuilder struct {
+	params SessionPromptParams
+	// files maps the index of each part added by File to its path, which
+	// Build resolves against the final Directory.
+	files map[int]string
+	errs  []error
+}
+
+// NewPromptBuilder returns an empty builder.
+func NewPromptBuilder() *PromptBuilder {
+	return &PromptBuilder{}
+}
+
+// Text appends a text part.
+func (b *PromptBuilder) Text(text string) *PromptBuilder {
+	if strings.TrimSpace(text) == "" {
+		b.errs = append(b.errs, errors.New("text part is empty"))
+		return b
+	}
+	b.params.Parts = append(b.params.Parts, TextPartInputParam{Text: text, Type: TextPartInputTypeText})
+	return b
+}
+
+// File appends a reference to a file in the workspace, which the server
+// reads and attaches. A relative path is resolved when Build is called:
+// against Directory when set, wherever it appears in the chain, and against
+// the current working directory otherwise. The MIME type is derived from the
+// extension, defaulting to text/plain.
+func (b *PromptBuilder) File(filePath string) *PromptBuilder {
+	if strings.TrimSpace(filePath) == "" {
+		b.errs = append(b.errs, errors.New("file path is empty"))
+		return b
+	}
+
+	if b.files == nil {
+		b.files = make(map[int]string)
+	}
+	b.files[len(b.params.Parts)] = filePath
+	var source FilePartSourceUnionParam = FileSourceParam{
+		Path: filePath,
+		Text: FilePartSourceTextParam{Value: "@" + filePath},
+		Type: FileSourceTypeFile,
+	}
+	b.params.Parts = append(b.params.Parts, FilePartInputParam{
+		Mime:     mimeTypeForPath(filePath),
+		Type:     FilePartInputTypeFile,
+		Filename: Ptr(filepath.Base(filePath)),
+		Source:   &source,
+	})
+	return b
+}
+
+// Symbol appends a reference to a symbol returned by FindService.Symbols.
+// The server attaches the lines the symbol spans.
+func (b *PromptBuilder) Symbol(sym Symbol) *PromptBuilder {
+	if sym.Name == "" {
+		b.errs = append(b.errs, requiredFieldError("Symbol.Name"))
+		return b
+	}
+	u, err := url.Parse(sym.Location.Uri)
+	if err != nil || u.Scheme != "file" || u.Path == "" {
+		b.errs = append(b.errs, fmt.Errorf("symbol %s: location %q is not a file URI", sym.Name, sym.Location.Uri))
+		return b
+	}
+
+	r := sym.Location.Range
+	var source FilePartSourceUnionParam = SymbolSourceParam{
+		Kind: int64(sym.Kind),
+		Name: sym.Name,
+		Path: u.Path,
+		Range: SymbolSourceRangeParam{
+			Start: SymbolSourceRangeStartParam{Line: r.Start.Line, Character: r.Start.Character},
+			End:   SymbolSourceRangeEndParam{Line: r.End.Line, Character: r.End.Character},
+		},
+		Text: FilePartSourceTextParam{Value: "@" + sym.Name},
+		Type: SymbolSourceTypeSymbol,
+	}
+	query := url.Values{}
+	query.Set("start", strconv.FormatInt(r.Start.Line, 10))
+	query.Set("end", strconv.FormatInt(r.End.Line, 10))
+	b.params.Parts = append(b.params.Parts, FilePartInputParam{
+		Mime:     "text/plain",
+		Type:     FilePartInputTypeFile,
+		URL:      fileURL(u.Path, query.Encode()),
+		Filename: Ptr(sym.Name),
+		Source:   &source,
+	})
+	return b
+}
+
+// Attach appends a local file's content inline, as NewFileAttachment does.
+// Use it for images and other files the server cannot read from the
+// workspace.
+func (b *PromptBuilder) Attach(filePath string, opts ...AttachmentOption) *PromptBuilder {
+	part, err := NewFileAttachment(filePath, opts...)
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	b.params.Parts = append(b.params.Parts, part)
+	return b
+}
+
+// Agent appends a mention of a subagent, asking the assistant to delegate
+// to it. Use UseAgent to choose the agent that handles the prompt itself.
+func (b *PromptBuilder) Agent(name string) *PromptBuilder {
+	if strings.TrimSpace(name) == "" {
+		b.errs = append(b.errs, errors.New("agent name is empty"))
+		return b
+	}
+	b.params.Parts = append(b.params.Parts, AgentPartInputParam{Name: name, Type: AgentPartInputTypeAgent})
+	return b
+}
+
+// Part appends a part built by hand, for inputs the other methods do not
+// cover.
+func (b *PromptBuilder) Part(part SessionPromptParamsPartUnion) *PromptBuilder {
+	if part == nil {
+		b.errs = append(b.errs, errors.New("part is nil"))
+		return b
+	}
+	b.params.Parts = append(b.params.Parts, part)
+	return b
+}
+
+// UseAgent selects the agent that handles the prompt.
+func (b *PromptBuilder) UseAgent(name string) *PromptBuilder {
+	b.params.Agent = Ptr(name)
+	return b
+}
+
+// Model selects the provider and model for the reply.
+func (b *PromptBuilder) Model(providerID, modelID string) *PromptBuilder {
+	if providerID == "" || modelID == "" {
+		b.errs = append(b.errs, fmt.Errorf("model requires both provider and model IDs, got %q and %q", providerID, modelID))
+		return b
+	}
+	b.params.Model = &SessionPromptParamsModel{ProviderID: providerID, ModelID: modelID}
+	return b
+}
+
+// System overrides the system prompt for this message.
+func (b *PromptBuilder) System(prompt string) *PromptBuilder {
+	b.params.System = Ptr(prompt)
+	return b
+}
+
+// EnableTool allows a tool for this message.
+func (b *PromptBuilder) EnableTool(name string) *PromptBuilder {
+	return b.setTool(name, true)
+}
+
+// DisableTool forbids a tool for this message.
+func (b *PromptBuilder) DisableTool(name string) *PromptBuilder {
+	return b.setTool(name, false)
+}
+
+func (b *PromptBuilder) setTool(name string, enabled bool) *PromptBuilder {
+	if name == "" {
+		b.errs = append(b.errs, errors.New("tool name is empty"))
+		return b
+	}
+	if b.params.Tools == nil {
+		b.params.Tools = &map[string]bool{}
+	}
+	(*b.params.Tools)[name] = enabled
+	return b
+}
+
+// MessageID sets the ID of the user message the prompt creates.
+func (b *PromptBuilder) MessageID(id string) *PromptBuilder {
+	b.params.MessageID = Ptr(id)
+	return b
+}
+
+// NoReply stores the message without asking the assistant to respond.
+func (b *PromptBuilder) NoReply() *PromptBuilder {
+	b.params.NoReply = Ptr(true)
+	return b
+}
+
+// Directory scopes the request to a workspace. It is also the base for
+// relative paths passed to File.
+func (b *PromptBuilder) Directory(dir string) *PromptBuilder {
+	b.params.Directory = Ptr(dir)
+	return b
+}
+
+// Build returns the assembled params, or every validation error recorded so
+// far joined together.
+func (b *PromptBuilder) Build() (*SessionPromptParams, error) {
+	errs := append([]error(nil), b.errs...)
+	if len(b.params.Parts) == 0 {
+		errs = append(errs, missingRequiredParameterError("parts"))
+	}
+
+	params := b.params
+	params.Parts = append([]SessionPromptParamsPartUnion(nil), b.params.Parts...)
+	for i, part := range params.Parts {
+		filePath, ok := b.files[i]
+		if !ok {
+			continue
+		}
+		abs, err := b.absPath(filePath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("file %s: %w", filePath, err))
+			continue
+		}
+		file := part.(FilePartInputParam)
+		file.URL = fileURL(abs, "")
+		params.Parts[i] = file
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("prompt builder: %w", err)
+	}
+	if b.params.Tools != nil {
+		tools := make(map[string]bool, len(*b.params.Tools))
+		for name, enabled := range *b.params.Tools {
+			tools[name] = enabled
+		}
+		params.Tools = &tools
+	}
+	return &params, nil
+}
+
+func (b *PromptBuilder) absPath(p string) (string, error) {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p), nil
+	}
+	if b.params.Directory != nil && *b.params.Directory != "" {
+		return filepath.Join(*b.params.Directory, p), nil
+	}
+	return filepath.Abs(p)
+}
+
+// fileURL returns a file:// URL for an absolute path.
+func fileURL(absPath, rawQuery string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(absPath), RawQuery: rawQuery}
+	return u.String()
+}
+
+// mimeTypeForPath guesses a MIME type from a file extension, defaulting to
+// text/plain.
+func mimeTypeForPath(p string) string {
+	if mediaType := mimeTypeByExtension(p); mediaType != "" {
+		return mediaType
+	}
+	return "text/plain"
+}
+
+// mimeTypeByExtension returns the MIME type registered for a file's
+// extension, without parameters such as charset, or "" if none is.
+func mimeTypeByExtension(p string) string {
+	mediaType := mime.TypeByExtension(path.Ext(filepath.ToSlash(p)))
+	if mediaType == "" {
+		return ""
+	}
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		return parsed
+	}
+	return mediaType
+}
@@ -0,0 +1,146 @@
+package opencode
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPromptBuilder_BuildsParts(t *testing.T) {
+	sym := Symbol{
+		Name: "Run",
+		Kind: SymbolKindFunction,
+		Location: SymbolLocation{
+			Uri: "file:///src/app/main.go",
+			Range: SymbolLocationRange{
+				Start: SymbolPosition{Line: 10, Character: 0},
+				End:   SymbolPosition{Line: 24, Character: 1},
+			},
+		},
+	}
+	params, err := NewPromptBuilder().
+		Directory("/src/app").
+		Text("Explain").
+		File("config.json").
+		Symbol(sym).
+		Agent("reviewer").
+		UseAgent("build").
+		Model("anthropic", "claude").
+		System("be brief").
+		DisableTool("bash").
+		EnableTool("read").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if len(params.Parts) != 4 {
+		t.Fatalf("expected 4 parts, got %d", len(params.Parts))
+	}
+	if text, ok := params.Parts[0].(TextPartInputParam); !ok || text.Text != "Explain" {
+		t.Errorf("expected text part, got %#v", params.Parts[0])
+	}
+
+	file, ok := params.Parts[1].(FilePartInputParam)
+	if !ok {
+		t.Fatalf("expected file part, got %#v", params.Parts[1])
+	}
+	if file.URL != "file:///src/app/config.json" || file.Mime != "application/json" || *file.Filename != "config.json" {
+		t.Errorf("unexpected file part %+v", file)
+	}
+	if src, ok := (*file.Source).(FileSourceParam); !ok || src.Path != "config.json" || src.Text.Value != "@config.json" {
+		t.Errorf("unexpected file source %#v", *file.Source)
+	}
+
+	symbol, ok := params.Parts[2].(FilePartInputParam)
+	if !ok {
+		t.Fatalf("expected symbol file part, got %#v", params.Parts[2])
+	}
+	if symbol.URL != "file:///src/app/main.go?end=24&start=10" {
+		t.Errorf("unexpected symbol URL %s", symbol.URL)
+	}
+	src, ok := (*symbol.Source).(SymbolSourceParam)
+	if !ok || src.Path != "/src/app/main.go" || src.Kind != int64(SymbolKindFunction) || src.Range.End.Line != 24 {
+		t.Errorf("unexpected symbol source %#v", *symbol.Source)
+	}
+
+	if agent, ok := params.Parts[3].(AgentPartInputParam); !ok || agent.Name != "reviewer" {
+		t.Errorf("expected agent part, got %#v", params.Parts[3])
+	}
+	if *params.Agent != "build" || params.Model.ProviderID != "anthropic" || params.Model.ModelID != "claude" || *params.System != "be brief" {
+		t.Errorf("unexpected options %+v", params)
+	}
+	if tools := *params.Tools; tools["bash"] || !tools["read"] {
+		t.Errorf("unexpected tools %v", tools)
+	}
+}
+
+func TestPromptBuilder_FileResolvesAgainstFinalDirectory(t *testing.T) {
+	b := NewPromptBuilder().File("a.go").Directory("/src/app")
+	params, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if file := params.Parts[0].(FilePartInputParam); file.URL != "file:///src/app/a.go" {
+		t.Errorf("expected path resolved against Directory, got %s", file.URL)
+	}
+
+	// Each Build resolves against the Directory current at that time.
+	params, err = b.Directory("/src/lib").Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if file := params.Parts[0].(FilePartInputParam); file.URL != "file:///src/lib/a.go" {
+		t.Errorf("expected path resolved against the new Directory, got %s", file.URL)
+	}
+}
+
+func TestPromptBuilder_MarshalsToPromptBody(t *testing.T) {
+	params, err := NewPromptBuilder().Text("hi").File("/tmp/a.png").Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	for _, want := range []string{`"type":"text"`, `"mime":"image/png"`, `"url":"file:///tmp/a.png"`, `"source":{"path":"/tmp/a.png"`} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("expected body to contain %s, got %s", want, body)
+		}
+	}
+}
+
+func TestPromptBuilder_CollectsErrors(t *testing.T) {
+	_, err := NewPromptBuilder().
+		Text(" ").
+		File("").
+		Symbol(Symbol{Name: "x", Location: SymbolLocation{Uri: "https://example.com/x"}}).
+		Model("anthropic", "").
+		DisableTool("").
+		Build()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"text part is empty", "file path is empty", "not a file URI", "model requires", "tool name is empty", "missing required parts parameter"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %q, got %v", want, err)
+		}
+	}
+	if !errors.Is(err, ErrMissingRequiredParameter) {
+		t.Errorf("expected ErrMissingRequiredParameter in %v", err)
+	}
+}
+
+func TestPromptBuilder_BuildReturnsIndependentCopies(t *testing.T) {
+	b := NewPromptBuilder().Text("one").DisableTool("bash")
+	first, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	b.Text("two").EnableTool("bash")
+	if len(first.Parts) != 1 || (*first.Tools)["bash"] {
+		t.Fatalf("expected earlier params to be unaffected, got %+v", first)
+	}
+}
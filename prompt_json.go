@@ -0,0 +1,260 @@
+package opencode
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DefaultPromptJSONRetries is the number of corrective follow-ups PromptJSON
+// sends when PromptJSONParams.MaxRetries is zero.
+const DefaultPromptJSONRetries = 2
+
+// maxEmbeddedJSONAttempts bounds how many candidate offsets extractJSON tries
+// to decode in prose, keeping the scan linear in the length of the reply.
+const maxEmbeddedJSONAttempts = 64
+
+// PromptJSONParams configures PromptJSON. The fields shared with
+// SessionPromptParams are passed through on every prompt.
+type PromptJSONParams[T any] struct {
+	Directory *string
+	Agent     *string
+	Model     *SessionPromptParamsModel
+	// System is sent ahead of the instructions describing the expected JSON.
+	System *string
+	Tools  *map[string]bool
+	// MaxRetries is the number of corrective follow-ups sent after a reply
+	// that is not valid. Zero uses DefaultPromptJSONRetries; a negative value
+	// sends none.
+	MaxRetries int
+	// Validate checks a decoded value after the schema and the Validate
+	// method of T or *T, if either has one. Its error is sent back to the
+	// assistant.
+	Validate func(v *T) error
+}
+
+// PromptJSONResult is the value decoded by PromptJSON.
+type PromptJSONResult[T any] struct {
+	Value T
+	// Response is the reply Value was decoded from.
+	Response *SessionPromptResponse
+	// Attempts is the number of prompts sent, counting the first.
+	Attempts int
+}
+
+// PromptJSON prompts the session id and decodes the answer into a T. The
+// system prompt asks for a single JSON value matching a JSON Schema derived
+// from T: struct fields follow their json tags, are required unless they are
+// pointers or tagged omitempty, and may be described with a `description`
+// tag.
+//
+// The JSON is taken from the text parts after the reply's last tool call,
+// either on its own or in a fenced code block. It must have every required
+// property, decode into T without unknown fields, and pass the Validate()
+// error method of T or *T and params.Validate when set. Otherwise PromptJSON
+// tells the assistant what was wrong and asks again, up to
+// params.MaxRetries times, before returning a *StructuredOutputError.
+//
+//	type Review struct {
+//		Approve  bool     `json:"approve"`
+//		Problems []string `json:"problems" description:"one sentence each"`
+//	}
+//	result, err := opencode.PromptJSON[Review](ctx, client.Session, id, "Review the staged diff", nil)
+//
+// A reply whose assistant message reports an error is returned as a
+// *SessionFailedError without retrying.
+func PromptJSON[T any](ctx context.Context, session *SessionService, id, prompt string, params *PromptJSONParams[T]) (*PromptJSONResult[T], error) {
+	if ctx == nil {
+		return nil, ErrContextRequired
+	}
+	if session == nil {
+		return nil, requiredFieldError("session")
+	}
+	if strings.TrimSpace(id) == "" {
+		return nil, missingRequiredParameterError("id")
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return nil, missingRequiredParameterError("prompt")
+	}
+	if params == nil {
+		params = &PromptJSONParams[T]{}
+	}
+	retries := params.MaxRetries
+	if retries == 0 {
+		retries = DefaultPromptJSONRetries
+	}
+
+	schema := schemaFor(reflect.TypeOf((*T)(nil)).Elem())
+	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode schema: %w", err)
+	}
+	system := "Respond with a single JSON value that conforms to the JSON Schema below, and nothing else.\n\n" + string(schemaJSON)
+	if params.System != nil && *params.System != "" {
+		system = *params.System + "\n\n" + system
+	}
+
+	text := prompt
+	for attempt := 1; ; attempt++ {
+		resp, err := session.Prompt(ctx, id, &SessionPromptParams{
+			Parts:     []SessionPromptParamsPartUnion{TextPartInputParam{Text: text, Type: TextPartInputTypeText}},
+			Directory: params.Directory,
+			Agent:     params.Agent,
+			Model:     params.Model,
+			System:    &system,
+			Tools:     params.Tools,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if resp.Info.Error.Name != "" {
+			return nil, assistantFailed(id, resp.Info.Error)
+		}
+
+		answer := finalText(resp.Parts)
+		value, err := decodeStructured(answer, schema, params.Validate)
+		if err == nil {
+			return &PromptJSONResult[T]{Value: value, Response: resp, Attempts: attempt}, nil
+		}
+		if attempt > retries {
+			return nil, &StructuredOutputError{SessionID: id, Attempts: attempt, Text: answer, Err: err}
+		}
+		text = fmt.Sprintf("Your previous answer could not be used: %v. Reply again with only the corrected JSON value, matching the schema in the system prompt.", err)
+	}
+}
+
+// assistantFailed reports the error recorded on an assistant message.
+func assistantFailed(sessionID string, msgErr AssistantMessageError) *SessionFailedError {
+	failed := &SessionFailedError{SessionID: sessionID}
+	// The assistant and session error unions share their variants.
+	if data, err := msgErr.MarshalJSON(); err == nil {
+		_ = failed.Err.UnmarshalJSON(data)
+	}
+	return failed
+}
+
+// finalText joins the text parts that follow the last tool call in parts.
+func finalText(parts []Part) string {
+	var texts []string
+	for _, part := range parts {
+		switch part.Type {
+		case PartTypeTool:
+			texts = texts[:0]
+		case PartTypeText:
+			if p, err := part.AsText(); err == nil && !p.Synthetic {
+				texts = append(texts, p.Text)
+			}
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func decodeStructured[T any](text string, schema *jsonSchema, validate func(*T) error) (T, error) {
+	var value T
+	raw, err := extractJSON(text, schema.Type)
+	if err != nil {
+		return value, err
+	}
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return value, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if err := schema.validate(generic, ""); err != nil {
+		return value, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&value); err != nil {
+		return value, fmt.Errorf("does not match the schema: %w", err)
+	}
+	if v, ok := validator(&value); ok {
+		if err := v.Validate(); err != nil {
+			return value, err
+		}
+	}
+	if validate != nil {
+		if err := validate(&value); err != nil {
+			return value, err
+		}
+	}
+	return value, nil
+}
+
+// validator returns the Validate method of *value, or of value itself when T
+// is a non-nil pointer type such as *Review.
+func validator[T any](value *T) (interface{ Validate() error }, bool) {
+	if v, ok := any(*value).(interface{ Validate() error }); ok {
+		if rv := reflect.ValueOf(v); rv.Kind() != reflect.Pointer || !rv.IsNil() {
+			return v, true
+		}
+	}
+	v, ok := any(value).(interface{ Validate() error })
+	return v, ok
+}
+
+// extractJSON finds the JSON value in a reply: the whole text, the last
+// fenced code block holding valid JSON, or the first JSON value embedded in
+// prose. For object and array schemas only values of that kind are
+// considered. At most maxEmbeddedJSONAttempts offsets are tried in prose.
+func extractJSON(text, schemaType string) (json.RawMessage, error) {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return nil, fmt.Errorf("reply contains no text")
+	}
+	if json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed), nil
+	}
+
+	blocks := fencedBlocks(trimmed)
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if block := strings.TrimSpace(blocks[i]); json.Valid([]byte(block)) {
+			return json.RawMessage(block), nil
+		}
+	}
+
+	starts := "{["
+	switch schemaType {
+	case "object":
+		starts = "{"
+	case "array":
+		starts = "["
+	}
+	for i, attempts := 0, 0; i < len(trimmed) && attempts < maxEmbeddedJSONAttempts; i++ {
+		if strings.IndexByte(starts, trimmed[i]) < 0 {
+			continue
+		}
+		attempts++
+		var raw json.RawMessage
+		if err := json.NewDecoder(strings.NewReader(trimmed[i:])).Decode(&raw); err == nil {
+			return raw, nil
+		}
+	}
+	return nil, fmt.Errorf("reply contains no JSON value")
+}
+
+// fencedBlocks returns the contents of the ``` code blocks in text.
+func fencedBlocks(text string) []string {
+	var blocks []string
+	for {
+		start := strings.Index(text, "```")
+		if start < 0 {
+			return blocks
+		}
+		rest := text[start+3:]
+		// Skip the info string, such as "json".
+		nl := strings.IndexByte(rest, '\n')
+		if nl < 0 {
+			return blocks
+		}
+		rest = rest[nl+1:]
+		end := strings.Index(rest, "```")
+		if end < 0 {
+			return blocks
+		}
+		blocks = append(blocks, rest[:end])
+		text = rest[end+3:]
+	}
+}
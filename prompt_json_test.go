@@ -0,0 +1,260 @@
+package opencode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type review struct {
+	Approve  bool     `json:"approve"`
+	Problems []string `json:"problems" description:"one sentence each"`
+	Score    *int     `json:"score,omitempty"`
+}
+
+func (r *review) Validate() error {
+	if !r.Approve && len(r.Problems) == 0 {
+		return errors.New("a rejected review must list problems")
+	}
+	return nil
+}
+
+// promptJSONServer answers each prompt with the next reply and records the
+// requests it received.
+type promptJSONServer struct {
+	mu      sync.Mutex
+	replies []string
+	prompts []string
+	systems []string
+}
+
+func (s *promptJSONServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Parts  []TextPartInputParam `json:"parts"`
+		System string               `json:"system"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prompts = append(s.prompts, body.Parts[0].Text)
+	s.systems = append(s.systems, body.System)
+
+	reply := s.replies[0]
+	if len(s.replies) > 1 {
+		s.replies = s.replies[1:]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(reply))
+}
+
+func (s *promptJSONServer) texts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.prompts...)
+}
+
+func newPromptJSONServer(t *testing.T, replies ...string) (*Client, *promptJSONServer) {
+	t.Helper()
+	fake := &promptJSONServer{replies: replies}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	client, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client, fake
+}
+
+// textReply is a prompt response whose final text part is text, after a
+// tool call whose output mentions unrelated JSON.
+func textReply(text string) string {
+	data, _ := json.Marshal(text)
+	return fmt.Sprintf(`{"info":{"id":"msg_2","role":"assistant","sessionID":"ses_1"},"parts":[
+		{"id":"p1","type":"text","text":"Let me look. {\"draft\":true}"},
+		{"id":"p2","type":"tool","tool":"read","state":{"status":"completed","input":{},"output":"{}"}},
+		{"id":"p3","type":"text","text":%s}
+	]}`, data)
+}
+
+func TestPromptJSON(t *testing.T) {
+	client, fake := newPromptJSONServer(t, textReply("Here is my review:\n```json\n{\"approve\": false, \"problems\": [\"tests fail\"]}\n```"))
+
+	result, err := PromptJSON[review](context.Background(), client.Session, "ses_1", "Review the diff", &PromptJSONParams[review]{
+		System: Ptr("Be strict."),
+	})
+	if err != nil {
+		t.Fatalf("PromptJSON: %v", err)
+	}
+	if result.Value.Approve || len(result.Value.Problems) != 1 || result.Attempts != 1 {
+		t.Errorf("result = %+v", result)
+	}
+	if result.Response.Info.ID != "msg_2" {
+		t.Errorf("Response = %+v", result.Response.Info)
+	}
+
+	system := fake.systems[0]
+	if !strings.HasPrefix(system, "Be strict.\n\n") {
+		t.Errorf("system prompt does not start with the caller's: %q", system)
+	}
+	for _, want := range []string{`"approve": {`, `"description": "one sentence each"`, `"required": [`, `"additionalProperties": false`} {
+		if !strings.Contains(system, want) {
+			t.Errorf("system prompt is missing %s:\n%s", want, system)
+		}
+	}
+}
+
+func TestPromptJSON_Retries(t *testing.T) {
+	client, fake := newPromptJSONServer(t,
+		textReply("I cannot decide yet."),
+		textReply(`{"approve": false, "problems": []}`),
+		textReply(`{"approve": true, "problems": [], "verdict": "ship it"}`),
+		textReply(`{"approve": true}`),
+		textReply(`{"approve": true, "problems": [], "score": 9}`),
+	)
+
+	result, err := PromptJSON[review](context.Background(), client.Session, "ses_1", "Review the diff", &PromptJSONParams[review]{
+		MaxRetries: 4,
+	})
+	if err != nil {
+		t.Fatalf("PromptJSON: %v", err)
+	}
+	if !result.Value.Approve || result.Value.Score == nil || *result.Value.Score != 9 || result.Attempts != 5 {
+		t.Errorf("result = %+v", result)
+	}
+
+	texts := fake.texts()
+	if len(texts) != 5 || texts[0] != "Review the diff" {
+		t.Fatalf("prompts = %q", texts)
+	}
+	for i, want := range []string{
+		"reply contains no JSON value",
+		"a rejected review must list problems",
+		`unknown field "verdict"`,
+		`missing required property "problems"`,
+	} {
+		if !strings.Contains(texts[i+1], want) {
+			t.Errorf("follow-up %d = %q, want it to mention %q", i+1, texts[i+1], want)
+		}
+	}
+}
+
+func TestPromptJSON_GivesUp(t *testing.T) {
+	client, fake := newPromptJSONServer(t, textReply(`{"approve": "yes"}`))
+
+	_, err := PromptJSON[review](context.Background(), client.Session, "ses_1", "Review the diff", &PromptJSONParams[review]{
+		Validate: func(*review) error { return nil },
+	})
+	if !errors.Is(err, ErrInvalidStructuredOutput) {
+		t.Fatalf("err = %v, want ErrInvalidStructuredOutput", err)
+	}
+	var outputErr *StructuredOutputError
+	if !errors.As(err, &outputErr) || outputErr.Attempts != 1+DefaultPromptJSONRetries || outputErr.Text != `{"approve": "yes"}` {
+		t.Errorf("StructuredOutputError = %+v", outputErr)
+	}
+	if len(fake.texts()) != 1+DefaultPromptJSONRetries {
+		t.Errorf("sent %d prompts", len(fake.texts()))
+	}
+
+	// A negative MaxRetries sends no follow-ups, and params.Validate runs
+	// after T's Validate.
+	client, fake = newPromptJSONServer(t, textReply(`{"approve": true, "problems": []}`))
+	_, err = PromptJSON[review](context.Background(), client.Session, "ses_1", "Review the diff", &PromptJSONParams[review]{
+		MaxRetries: -1,
+		Validate:   func(*review) error { return errors.New("score is required") },
+	})
+	if !errors.As(err, &outputErr) || outputErr.Attempts != 1 || outputErr.Err.Error() != "score is required" {
+		t.Errorf("err = %v", err)
+	}
+	if len(fake.texts()) != 1 {
+		t.Errorf("sent %d prompts", len(fake.texts()))
+	}
+}
+
+func TestPromptJSON_AssistantError(t *testing.T) {
+	client, fake := newPromptJSONServer(t, `{"info":{"id":"msg_2","role":"assistant","sessionID":"ses_1",
+		"error":{"name":"ProviderAuthError","data":{"providerID":"anthropic","message":"bad key"}}},"parts":[]}`)
+
+	_, err := PromptJSON[[]string](context.Background(), client.Session, "ses_1", "List the files", nil)
+	var failed *SessionFailedError
+	if !errors.As(err, &failed) || failed.Err.Name != SessionErrorNameProviderAuthError {
+		t.Fatalf("err = %v, want *SessionFailedError", err)
+	}
+	if len(fake.texts()) != 1 {
+		t.Errorf("sent %d prompts, want no retries", len(fake.texts()))
+	}
+}
+
+type pointerReview struct {
+	Approve bool `json:"approve"`
+}
+
+func (r *pointerReview) Validate() error {
+	if !r.Approve {
+		return errors.New("only approvals are accepted")
+	}
+	return nil
+}
+
+func TestPromptJSON_PointerValidate(t *testing.T) {
+	client, fake := newPromptJSONServer(t, textReply(`{"approve": false}`), textReply(`{"approve": true}`))
+
+	result, err := PromptJSON[*pointerReview](context.Background(), client.Session, "ses_1", "Review the diff", nil)
+	if err != nil {
+		t.Fatalf("PromptJSON: %v", err)
+	}
+	if !result.Value.Approve || result.Attempts != 2 {
+		t.Errorf("result = %+v", result)
+	}
+	if texts := fake.texts(); len(texts) != 2 || !strings.Contains(texts[1], "only approvals are accepted") {
+		t.Errorf("prompts = %q", texts)
+	}
+}
+
+func TestPromptJSON_Validation(t *testing.T) {
+	client, _ := newPromptJSONServer(t, textReply("{}"))
+	ctx := context.Background()
+
+	if _, err := PromptJSON[review](ctx, nil, "ses_1", "hi", nil); !errors.Is(err, ErrRequiredField) {
+		t.Errorf("nil session err = %v", err)
+	}
+	if _, err := PromptJSON[review](ctx, client.Session, " ", "hi", nil); !errors.Is(err, ErrMissingRequiredParameter) {
+		t.Errorf("empty id err = %v", err)
+	}
+	if _, err := PromptJSON[review](ctx, client.Session, "ses_1", "", nil); !errors.Is(err, ErrMissingRequiredParameter) {
+		t.Errorf("empty prompt err = %v", err)
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		text, schemaType, want string
+	}{
+		{`{"a":1}`, "object", `{"a":1}`},
+		{"```\n[1, 2]\n```", "array", "[1, 2]"},
+		{"```json\nnot json\n```\n```json\n{\"a\":1}\n```", "object", `{"a":1}`},
+		{`The answer is {"a": {"b": [1]}} as requested.`, "object", `{"a": {"b": [1]}}`},
+		{`Files [a] and ["b.go"]`, "array", `["b.go"]`},
+		{`I counted [3] of them: {"n": 3}`, "object", `{"n": 3}`},
+		{`42`, "integer", `42`},
+	}
+	for _, tt := range tests {
+		got, err := extractJSON(tt.text, tt.schemaType)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("extractJSON(%q) = %s, %v; want %s", tt.text, got, err, tt.want)
+		}
+	}
+	if _, err := extractJSON("no json here", "object"); err == nil {
+		t.Error("expected error for text without JSON")
+	}
+	// Unbalanced brackets are tried a bounded number of times rather than
+	// decoded from every offset.
+	if _, err := extractJSON("x "+strings.Repeat("[", 200_000), "array"); err == nil {
+		t.Error("expected error for unbalanced brackets")
+	}
+}
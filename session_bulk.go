@@ -0,0 +1,295 @@
+package opencode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dominicnunez/opencode-sdk-go/internal/mstime"
+)
+
+// DefaultSessionBulkConcurrency is the number of sessions a bulk operation
+// acts on in parallel when SessionBulkParams.Concurrency is zero.
+const DefaultSessionBulkConcurrency = 4
+
+// SessionFilter selects sessions for a bulk operation. All set conditions
+// must hold for a session to match. The zero filter matches nothing unless All
+// is set, so a forgotten filter cannot delete every session.
+type SessionFilter struct {
+	// All must be set to match every session when no other condition is.
+	All bool
+	// UpdatedBefore and UpdatedAfter bound SessionTime.Updated. Zero
+	// values leave that side open.
+	UpdatedBefore time.Time
+	UpdatedAfter  time.Time
+	// Title matches against the session title.
+	Title *regexp.Regexp
+	// ParentID keeps only direct children of this session.
+	ParentID string
+	// RootsOnly keeps only top-level sessions; ChildrenOnly keeps only
+	// sessions with a parent, such as subagent sessions.
+	RootsOnly    bool
+	ChildrenOnly bool
+	// Directory keeps only sessions whose working directory is this path
+	// or below it.
+	Directory string
+	// SharedOnly keeps only sessions with a share link.
+	SharedOnly bool
+}
+
+func (f SessionFilter) empty() bool {
+	return f.UpdatedBefore.IsZero() && f.UpdatedAfter.IsZero() && f.Title == nil &&
+		f.ParentID == "" && !f.RootsOnly && !f.ChildrenOnly && f.Directory == "" && !f.SharedOnly
+}
+
+func (f SessionFilter) validate() error {
+	if f.RootsOnly && f.ChildrenOnly {
+		return errors.New("session filter: RootsOnly and ChildrenOnly are mutually exclusive")
+	}
+	if f.RootsOnly && f.ParentID != "" {
+		return errors.New("session filter: RootsOnly and ParentID are mutually exclusive")
+	}
+	if !f.UpdatedBefore.IsZero() && !f.UpdatedAfter.IsZero() && !f.UpdatedAfter.Before(f.UpdatedBefore) {
+		return errors.New("session filter: UpdatedAfter must be before UpdatedBefore")
+	}
+	return nil
+}
+
+// Match reports whether session s satisfies the filter.
+func (f SessionFilter) Match(s Session) bool {
+	if f.empty() {
+		return f.All
+	}
+	updated := mstime.Time(s.Time.Updated)
+	if !f.UpdatedBefore.IsZero() && !updated.Before(f.UpdatedBefore) {
+		return false
+	}
+	if !f.UpdatedAfter.IsZero() && !updated.After(f.UpdatedAfter) {
+		return false
+	}
+	if f.Title != nil && !f.Title.MatchString(s.Title) {
+		return false
+	}
+	hasParent := s.ParentID != nil && *s.ParentID != ""
+	if f.RootsOnly && hasParent {
+		return false
+	}
+	if f.ChildrenOnly && !hasParent {
+		return false
+	}
+	if f.ParentID != "" && (!hasParent || *s.ParentID != f.ParentID) {
+		return false
+	}
+	if f.Directory != "" && !withinDirectory(s.Directory, f.Directory) {
+		return false
+	}
+	if f.SharedOnly && (s.Share == nil || s.Share.URL == "") {
+		return false
+	}
+	return true
+}
+
+func withinDirectory(path, dir string) bool {
+	if path == "" {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// SessionBulkParams configures a bulk session operation.
+type SessionBulkParams struct {
+	// Directory scopes the session listing and each per-session request.
+	Directory *string
+	Filter    SessionFilter
+	// Concurrency bounds the number of per-session requests in flight.
+	// Zero uses DefaultSessionBulkConcurrency.
+	Concurrency int
+	// DryRun reports the sessions that match without acting on them.
+	DryRun bool
+}
+
+// SessionBulkAction names the operation a bulk call performs.
+type SessionBulkAction string
+
+const (
+	SessionBulkActionDelete    SessionBulkAction = "delete"
+	SessionBulkActionAbort     SessionBulkAction = "abort"
+	SessionBulkActionUnshare   SessionBulkAction = "unshare"
+	SessionBulkActionSummarize SessionBulkAction = "summarize"
+)
+
+func (r SessionBulkAction) IsKnown() bool {
+	switch r {
+	case SessionBulkActionDelete, SessionBulkActionAbort, SessionBulkActionUnshare, SessionBulkActionSummarize:
+		return true
+	}
+	return false
+}
+
+// SessionBulkItem is the outcome for one matched session.
+type SessionBulkItem struct {
+	// Session is the session as listed before the action ran.
+	Session Session
+	// Err is the per-session failure, or nil on success and in dry runs.
+	Err error
+}
+
+// SessionBulkResult reports a bulk operation session by session, in the order
+// Session.List returned them.
+type SessionBulkResult struct {
+	Action SessionBulkAction
+	DryRun bool
+	Items  []SessionBulkItem
+}
+
+// Succeeded returns the items whose action completed, or every item in a dry
+// run.
+func (r *SessionBulkResult) Succeeded() []SessionBulkItem {
+	var items []SessionBulkItem
+	for _, item := range r.Items {
+		if item.Err == nil {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Failed returns the items whose action returned an error.
+func (r *SessionBulkResult) Failed() []SessionBulkItem {
+	var items []SessionBulkItem
+	for _, item := range r.Items {
+		if item.Err != nil {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Err joins the per-session errors, or returns nil if none failed.
+func (r *SessionBulkResult) Err() error {
+	var errs []error
+	for _, item := range r.Items {
+		if item.Err != nil {
+			errs = append(errs, fmt.Errorf("%s session %s: %w", r.Action, item.Session.ID, item.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// BulkDelete deletes every session matching params.Filter. A session that is
+// already gone, for example because deleting its parent removed it, counts as
+// deleted.
+//
+// Like every bulk operation, it returns an error without a result if the
+// sessions cannot be listed or params are invalid. Once the actions run, the
+// result is always returned and the error is the result's Err, so partial
+// failures are visible both ways.
+func (s *SessionService) BulkDelete(ctx context.Context, params *SessionBulkParams) (*SessionBulkResult, error) {
+	return s.bulk(ctx, SessionBulkActionDelete, params, nil, func(ctx context.Context, id string, dir *string) error {
+		_, err := s.Delete(ctx, id, &SessionDeleteParams{Directory: dir})
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	})
+}
+
+// BulkAbort aborts any running work in every session matching params.Filter.
+func (s *SessionService) BulkAbort(ctx context.Context, params *SessionBulkParams) (*SessionBulkResult, error) {
+	return s.bulk(ctx, SessionBulkActionAbort, params, nil, func(ctx context.Context, id string, dir *string) error {
+		_, err := s.Abort(ctx, id, &SessionAbortParams{Directory: dir})
+		return err
+	})
+}
+
+// BulkUnshare removes the share link of every session matching
+// params.Filter. Sessions that are not shared never match.
+func (s *SessionService) BulkUnshare(ctx context.Context, params *SessionBulkParams) (*SessionBulkResult, error) {
+	shared := func(session Session) bool { return session.Share != nil && session.Share.URL != "" }
+	return s.bulk(ctx, SessionBulkActionUnshare, params, shared, func(ctx context.Context, id string, dir *string) error {
+		_, err := s.Unshare(ctx, id, &SessionUnshareParams{Directory: dir})
+		return err
+	})
+}
+
+// BulkSummarize summarizes every session matching params.Filter with the
+// given model.
+func (s *SessionService) BulkSummarize(ctx context.Context, providerID, modelID string, params *SessionBulkParams) (*SessionBulkResult, error) {
+	if strings.TrimSpace(providerID) == "" {
+		return nil, missingRequiredParameterError("providerID")
+	}
+	if strings.TrimSpace(modelID) == "" {
+		return nil, missingRequiredParameterError("modelID")
+	}
+	return s.bulk(ctx, SessionBulkActionSummarize, params, nil, func(ctx context.Context, id string, dir *string) error {
+		_, err := s.Summarize(ctx, id, &SessionSummarizeParams{ProviderID: providerID, ModelID: modelID, Directory: dir})
+		return err
+	})
+}
+
+func (s *SessionService) bulk(
+	ctx context.Context,
+	action SessionBulkAction,
+	params *SessionBulkParams,
+	eligible func(Session) bool,
+	act func(ctx context.Context, id string, dir *string) error,
+) (*SessionBulkResult, error) {
+	if params == nil {
+		return nil, ErrParamsRequired
+	}
+	if err := params.Filter.validate(); err != nil {
+		return nil, err
+	}
+	if params.Concurrency < 0 {
+		return nil, errors.New("session bulk: concurrency must not be negative")
+	}
+	concurrency := params.Concurrency
+	if concurrency == 0 {
+		concurrency = DefaultSessionBulkConcurrency
+	}
+
+	sessions, err := s.List(ctx, &SessionListParams{Directory: params.Directory})
+	if err != nil {
+		return nil, fmt.Errorf("%s sessions: %w", action, err)
+	}
+
+	result := &SessionBulkResult{Action: action, DryRun: params.DryRun}
+	for _, session := range sessions {
+		if params.Filter.Match(session) && (eligible == nil || eligible(session)) {
+			result.Items = append(result.Items, SessionBulkItem{Session: session})
+		}
+	}
+	if params.DryRun {
+		return result, nil
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range result.Items {
+		item := &result.Items[i]
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			item.Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			item.Err = act(ctx, item.Session.ID, params.Directory)
+		}()
+	}
+	wg.Wait()
+	return result, result.Err()
+}
@@ -0,0 +1,228 @@
+package opencode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var bulkNow = time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+func bulkSession(id, title string, age time.Duration) Session {
+	return Session{
+		ID:        id,
+		Title:     title,
+		Directory: "/work/app",
+		Time:      SessionTime{Updated: float64(bulkNow.Add(-age).UnixMilli())},
+	}
+}
+
+type bulkServer struct {
+	sessions []Session
+	fail     map[string]int
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (s *bulkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Path == "/session" {
+		_ = json.NewEncoder(w).Encode(s.sessions)
+		return
+	}
+	s.mu.Lock()
+	s.calls = append(s.calls, r.Method+" "+r.URL.Path)
+	s.mu.Unlock()
+
+	id := strings.Split(strings.TrimPrefix(r.URL.Path, "/session/"), "/")[0]
+	if status := s.fail[id]; status != 0 {
+		http.Error(w, `{"message":"failed"}`, status)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/share") {
+		_, _ = w.Write([]byte(`{"id":"` + id + `"}`))
+		return
+	}
+	_, _ = w.Write([]byte(`true`))
+}
+
+func (s *bulkServer) sortedCalls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	calls := append([]string(nil), s.calls...)
+	sort.Strings(calls)
+	return calls
+}
+
+func bulkFixture() *bulkServer {
+	old := bulkSession("ses_old", "Old refactor", 90*24*time.Hour)
+	child := bulkSession("ses_child", "Subagent: explore", 60*24*time.Hour)
+	child.ParentID = Ptr("ses_old")
+	shared := bulkSession("ses_shared", "Shared demo", 40*24*time.Hour)
+	shared.Share = &SessionShare{URL: "https://opncd.ai/s/abc"}
+	fresh := bulkSession("ses_new", "New work", time.Hour)
+	other := bulkSession("ses_other", "Other repo", 100*24*time.Hour)
+	other.Directory = "/work/other"
+	return &bulkServer{sessions: []Session{old, child, shared, fresh, other}, fail: map[string]int{}}
+}
+
+func bulkIDs(items []SessionBulkItem) string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.Session.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestSessionFilter_Match(t *testing.T) {
+	sessions := bulkFixture().sessions
+	tests := []struct {
+		name   string
+		filter SessionFilter
+		want   string
+	}{
+		{"zero matches nothing", SessionFilter{}, ""},
+		{"all", SessionFilter{All: true}, "ses_old,ses_child,ses_shared,ses_new,ses_other"},
+		{"older than 30 days", SessionFilter{UpdatedBefore: bulkNow.Add(-30 * 24 * time.Hour)}, "ses_old,ses_child,ses_shared,ses_other"},
+		{"updated window", SessionFilter{UpdatedAfter: bulkNow.Add(-70 * 24 * time.Hour), UpdatedBefore: bulkNow.Add(-24 * time.Hour)}, "ses_child,ses_shared"},
+		{"title", SessionFilter{Title: regexp.MustCompile(`^Subagent:`)}, "ses_child"},
+		{"roots", SessionFilter{RootsOnly: true, UpdatedBefore: bulkNow}, "ses_old,ses_shared,ses_new,ses_other"},
+		{"children", SessionFilter{ChildrenOnly: true}, "ses_child"},
+		{"parent", SessionFilter{ParentID: "ses_old"}, "ses_child"},
+		{"directory", SessionFilter{Directory: "/work/other/"}, "ses_other"},
+		{"directory prefix is not a parent", SessionFilter{Directory: "/work/oth"}, ""},
+		{"shared", SessionFilter{SharedOnly: true}, "ses_shared"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []string
+			for _, s := range sessions {
+				if tt.filter.Match(s) {
+					ids = append(ids, s.ID)
+				}
+			}
+			if got := strings.Join(ids, ","); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionBulkDelete(t *testing.T) {
+	srv := bulkFixture()
+	srv.fail["ses_child"] = http.StatusNotFound
+	srv.fail["ses_shared"] = http.StatusInternalServerError
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	client, err := NewClient(WithBaseURL(server.URL), WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	params := &SessionBulkParams{
+		Filter:      SessionFilter{UpdatedBefore: bulkNow.Add(-30 * 24 * time.Hour), Directory: "/work/app"},
+		Concurrency: 2,
+	}
+	result, err := client.Session.BulkDelete(context.Background(), params)
+	if result == nil {
+		t.Fatalf("expected a result, got error %v", err)
+	}
+	if !errors.Is(err, ErrInternal) || !strings.Contains(err.Error(), "delete session ses_shared") {
+		t.Errorf("expected joined failure for ses_shared, got %v", err)
+	}
+	if got := bulkIDs(result.Items); got != "ses_old,ses_child,ses_shared" {
+		t.Errorf("unexpected items %s", got)
+	}
+	if got := bulkIDs(result.Succeeded()); got != "ses_old,ses_child" {
+		t.Errorf("expected already-deleted child to count as success, got %s", got)
+	}
+	if got := bulkIDs(result.Failed()); got != "ses_shared" {
+		t.Errorf("unexpected failures %s", got)
+	}
+	want := "DELETE /session/ses_child,DELETE /session/ses_old,DELETE /session/ses_shared"
+	if got := strings.Join(srv.sortedCalls(), ","); got != want {
+		t.Errorf("unexpected calls %s", got)
+	}
+}
+
+func TestSessionBulk_DryRun(t *testing.T) {
+	srv := bulkFixture()
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	client, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	result, err := client.Session.BulkDelete(context.Background(), &SessionBulkParams{
+		Filter: SessionFilter{All: true},
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("BulkDelete: %v", err)
+	}
+	if !result.DryRun || len(result.Items) != 5 {
+		t.Errorf("expected 5 dry-run items, got %+v", result)
+	}
+	if len(srv.sortedCalls()) != 0 {
+		t.Errorf("dry run made calls: %v", srv.sortedCalls())
+	}
+}
+
+func TestSessionBulk_OtherActions(t *testing.T) {
+	srv := bulkFixture()
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	client, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	result, err := client.Session.BulkUnshare(ctx, &SessionBulkParams{Filter: SessionFilter{All: true}})
+	if err != nil {
+		t.Fatalf("BulkUnshare: %v", err)
+	}
+	if got := bulkIDs(result.Items); got != "ses_shared" {
+		t.Errorf("expected only shared sessions, got %s", got)
+	}
+
+	if _, err := client.Session.BulkAbort(ctx, &SessionBulkParams{Filter: SessionFilter{ChildrenOnly: true}}); err != nil {
+		t.Fatalf("BulkAbort: %v", err)
+	}
+	if _, err := client.Session.BulkSummarize(ctx, "anthropic", "claude", &SessionBulkParams{Filter: SessionFilter{Title: regexp.MustCompile("New")}}); err != nil {
+		t.Fatalf("BulkSummarize: %v", err)
+	}
+
+	want := "DELETE /session/ses_shared/share,POST /session/ses_child/abort,POST /session/ses_new/summarize"
+	if got := strings.Join(srv.sortedCalls(), ","); got != want {
+		t.Errorf("unexpected calls %s", got)
+	}
+}
+
+func TestSessionBulk_InvalidParams(t *testing.T) {
+	client, err := NewClient(WithBaseURL("http://localhost"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := client.Session.BulkDelete(ctx, nil); !errors.Is(err, ErrParamsRequired) {
+		t.Errorf("expected ErrParamsRequired, got %v", err)
+	}
+	if _, err := client.Session.BulkDelete(ctx, &SessionBulkParams{Filter: SessionFilter{RootsOnly: true, ChildrenOnly: true}}); err == nil {
+		t.Error("expected conflicting filter error")
+	}
+	if _, err := client.Session.BulkSummarize(ctx, "", "m", &SessionBulkParams{}); !errors.Is(err, &MissingRequiredParameterError{Parameter: "providerID"}) {
+		t.Errorf("expected missing providerID, got %v", err)
+	}
+}
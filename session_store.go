@@ -0,0 +1,411 @@
+package opencode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SessionStoreParams configures a SessionStore.
+type SessionStoreParams struct {
+	// Directory scopes the store to one workspace. Nil uses the server's
+	// default directory.
+	Directory *string
+	// StreamOptions are passed to EventService.ListStreaming by Run.
+	StreamOptions []EventStreamOption
+}
+
+type SessionStoreChangeKind string
+
+const (
+	SessionStoreChangeSessionUpdated SessionStoreChangeKind = "session.updated"
+	SessionStoreChangeSessionDeleted SessionStoreChangeKind = "session.deleted"
+	SessionStoreChangeMessageUpdated SessionStoreChangeKind = "message.updated"
+	SessionStoreChangeMessageRemoved SessionStoreChangeKind = "message.removed"
+	SessionStoreChangePartUpdated    SessionStoreChangeKind = "part.updated"
+	SessionStoreChangePartRemoved    SessionStoreChangeKind = "part.removed"
+	// SessionStoreChangeSynced reports that the whole store was reloaded
+	// from the server, so any cached view should be rebuilt.
+	SessionStoreChangeSynced SessionStoreChangeKind = "synced"
+)
+
+func (r SessionStoreChangeKind) IsKnown() bool {
+	switch r {
+	case SessionStoreChangeSessionUpdated, SessionStoreChangeSessionDeleted, SessionStoreChangeMessageUpdated, SessionStoreChangeMessageRemoved, SessionStoreChangePartUpdated, SessionStoreChangePartRemoved, SessionStoreChangeSynced:
+		return true
+	}
+	return false
+}
+
+// SessionStoreChange describes one mutation applied to a SessionStore. IDs
+// that do not apply to the change kind are empty.
+type SessionStoreChange struct {
+	Kind      SessionStoreChangeKind
+	SessionID string
+	MessageID string
+	PartID    string
+}
+
+// SessionStore is an in-memory model of a server's sessions, messages and
+// parts. It is loaded with Sync and kept current by applying events, either
+// from its own stream via Run or from any other source via Apply. All methods
+// are safe for concurrent use.
+//
+//	store := opencode.NewSessionStore(client, nil)
+//	store.OnChange(func(c opencode.SessionStoreChange) { redraw(c.SessionID) })
+//	go func() { _ = store.Run(ctx) }()
+type SessionStore struct {
+	client *Client
+	params SessionStoreParams
+	// backoff returns the delay before reconnect attempt n (0-based).
+	backoff func(attempt int) time.Duration
+
+	mu       sync.RWMutex
+	sessions map[string]Session
+	messages map[string]map[string]*storedMessage
+
+	listenersMu    sync.Mutex
+	listeners      map[int]func(SessionStoreChange)
+	nextListenerID int
+}
+
+type storedMessage struct {
+	info  Message
+	parts []Part
+}
+
+// NewSessionStore returns an empty store. Call Sync or Run to populate it.
+func NewSessionStore(client *Client, params *SessionStoreParams) *SessionStore {
+	if params == nil {
+		params = &SessionStoreParams{}
+	}
+	return &SessionStore{
+		client:    client,
+		params:    *params,
+		backoff:   retryBackoffDelay,
+		sessions:  map[string]Session{},
+		messages:  map[string]map[string]*storedMessage{},
+		listeners: map[int]func(SessionStoreChange){},
+	}
+}
+
+// Sync replaces the store's contents with the server's current sessions and
+// their messages. On error the previous contents are kept.
+func (s *SessionStore) Sync(ctx context.Context) error {
+	if s.client == nil {
+		return requiredFieldError("client")
+	}
+	sessions, err := s.client.Session.List(ctx, &SessionListParams{Directory: s.params.Directory})
+	if err != nil {
+		return fmt.Errorf("sync sessions: %w", err)
+	}
+
+	sessionMap := make(map[string]Session, len(sessions))
+	messageMap := make(map[string]map[string]*storedMessage, len(sessions))
+	for _, session := range sessions {
+		msgs, err := s.client.Session.Messages(ctx, session.ID, &SessionMessagesParams{Directory: s.params.Directory})
+		if err != nil {
+			return fmt.Errorf("sync messages for session %s: %w", session.ID, err)
+		}
+		byID := make(map[string]*storedMessage, len(msgs))
+		for _, msg := range msgs {
+			byID[msg.Info.ID] = &storedMessage{info: msg.Info, parts: append([]Part(nil), msg.Parts...)}
+		}
+		sessionMap[session.ID] = session
+		messageMap[session.ID] = byID
+	}
+
+	s.mu.Lock()
+	s.sessions = sessionMap
+	s.messages = messageMap
+	s.mu.Unlock()
+
+	s.notify(SessionStoreChange{Kind: SessionStoreChangeSynced})
+	return nil
+}
+
+// Run opens an event stream, syncs the store and then applies events until
+// ctx is done. When the stream drops it reconnects with jittered exponential
+// backoff and syncs again, so changes missed while disconnected are picked
+// up. The stream is opened before each sync so no event falls between the
+// two. Run returns ctx's error on cancellation, or the error when the server
+// rejects the stream or sync with a non-retryable status.
+func (s *SessionStore) Run(ctx context.Context) error {
+	if ctx == nil {
+		return ErrContextRequired
+	}
+	if s.client == nil {
+		return requiredFieldError("client")
+	}
+
+	attempt := 0
+	for {
+		synced, err := s.runOnce(ctx)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && !apiErr.IsRetryable() {
+			return err
+		}
+
+		if synced {
+			attempt = 0
+		}
+		timer := time.NewTimer(s.backoff(attempt))
+		attempt++
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// runOnce performs one connect-sync-apply cycle and reports whether the sync
+// succeeded.
+func (s *SessionStore) runOnce(ctx context.Context) (bool, error) {
+	stream := s.client.Event.ListStreaming(ctx, &EventListParams{Directory: s.params.Directory}, s.params.StreamOptions...)
+	defer func() { _ = stream.Close() }()
+
+	if err := stream.Err(); err != nil {
+		return false, err
+	}
+	if err := s.Sync(ctx); err != nil {
+		return false, err
+	}
+	for stream.Next() {
+		s.Apply(stream.Current())
+	}
+	if err := stream.Err(); err != nil {
+		return true, err
+	}
+	return true, errEventStreamEnded
+}
+
+// Apply updates the store from one event and reports whether it changed
+// anything. Events unrelated to sessions, messages or parts are ignored, as
+// are events that fail to decode.
+func (s *SessionStore) Apply(event Event) bool {
+	var change SessionStoreChange
+	s.mu.Lock()
+	switch event.Type {
+	case EventTypeSessionCreated, EventTypeSessionUpdated:
+		var info Session
+		if event.Type == EventTypeSessionCreated {
+			evt, err := event.AsSessionCreated()
+			if err != nil {
+				s.mu.Unlock()
+				return false
+			}
+			info = evt.Data.Info
+		} else {
+			evt, err := event.AsSessionUpdated()
+			if err != nil {
+				s.mu.Unlock()
+				return false
+			}
+			info = evt.Data.Info
+		}
+		s.sessions[info.ID] = info
+		change = SessionStoreChange{Kind: SessionStoreChangeSessionUpdated, SessionID: info.ID}
+
+	case EventTypeSessionDeleted:
+		evt, err := event.AsSessionDeleted()
+		if err != nil {
+			s.mu.Unlock()
+			return false
+		}
+		id := evt.Data.Info.ID
+		delete(s.sessions, id)
+		delete(s.messages, id)
+		change = SessionStoreChange{Kind: SessionStoreChangeSessionDeleted, SessionID: id}
+
+	case EventTypeMessageUpdated:
+		evt, err := event.AsMessageUpdated()
+		if err != nil {
+			s.mu.Unlock()
+			return false
+		}
+		info := evt.Data.Info
+		msg := s.messageLocked(info.SessionID, info.ID)
+		msg.info = info
+		change = SessionStoreChange{Kind: SessionStoreChangeMessageUpdated, SessionID: info.SessionID, MessageID: info.ID}
+
+	case EventTypeMessageRemoved:
+		evt, err := event.AsMessageRemoved()
+		if err != nil {
+			s.mu.Unlock()
+			return false
+		}
+		if byID, ok := s.messages[evt.Data.SessionID]; ok {
+			delete(byID, evt.Data.MessageID)
+		}
+		change = SessionStoreChange{Kind: SessionStoreChangeMessageRemoved, SessionID: evt.Data.SessionID, MessageID: evt.Data.MessageID}
+
+	case EventTypeMessagePartUpdated:
+		evt, err := event.AsMessagePartUpdated()
+		if err != nil {
+			s.mu.Unlock()
+			return false
+		}
+		part := evt.Data.Part
+		msg := s.messageLocked(part.SessionID, part.MessageID)
+		replaced := false
+		for i := range msg.parts {
+			if msg.parts[i].ID == part.ID {
+				msg.parts[i] = part
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			msg.parts = append(msg.parts, part)
+		}
+		change = SessionStoreChange{Kind: SessionStoreChangePartUpdated, SessionID: part.SessionID, MessageID: part.MessageID, PartID: part.ID}
+
+	case EventTypeMessagePartRemoved:
+		evt, err := event.AsMessagePartRemoved()
+		if err != nil {
+			s.mu.Unlock()
+			return false
+		}
+		if msg, ok := s.messages[evt.Data.SessionID][evt.Data.MessageID]; ok {
+			for i := range msg.parts {
+				if msg.parts[i].ID == evt.Data.PartID {
+					msg.parts = append(msg.parts[:i:i], msg.parts[i+1:]...)
+					break
+				}
+			}
+		}
+		change = SessionStoreChange{Kind: SessionStoreChangePartRemoved, SessionID: evt.Data.SessionID, MessageID: evt.Data.MessageID, PartID: evt.Data.PartID}
+
+	default:
+		s.mu.Unlock()
+		return false
+	}
+	s.mu.Unlock()
+
+	s.notify(change)
+	return true
+}
+
+// messageLocked returns the stored message, creating a placeholder carrying
+// only its IDs when a part arrives before the message itself. The caller
+// must hold s.mu for writing.
+func (s *SessionStore) messageLocked(sessionID, messageID string) *storedMessage {
+	byID, ok := s.messages[sessionID]
+	if !ok {
+		byID = map[string]*storedMessage{}
+		s.messages[sessionID] = byID
+	}
+	msg, ok := byID[messageID]
+	if !ok {
+		msg = &storedMessage{info: Message{ID: messageID, SessionID: sessionID}}
+		byID[messageID] = msg
+	}
+	return msg
+}
+
+// Sessions returns every stored session, most recently updated first.
+func (s *SessionStore) Sessions() []Session {
+	s.mu.RLock()
+	sessions := make([]Session, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].Time.Updated != sessions[j].Time.Updated {
+			return sessions[i].Time.Updated > sessions[j].Time.Updated
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions
+}
+
+// Session returns the stored session with the given ID.
+func (s *SessionStore) Session(id string) (Session, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	session, ok := s.sessions[id]
+	return session, ok
+}
+
+// Messages returns the session's messages with their parts, ordered by
+// message ID, which the server assigns in creation order.
+func (s *SessionStore) Messages(sessionID string) []SessionMessagesResponse {
+	s.mu.RLock()
+	byID := s.messages[sessionID]
+	messages := make([]SessionMessagesResponse, 0, len(byID))
+	for _, msg := range byID {
+		messages = append(messages, SessionMessagesResponse{Info: msg.info, Parts: append([]Part(nil), msg.parts...)})
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(messages, func(i, j int) bool { return messages[i].Info.ID < messages[j].Info.ID })
+	return messages
+}
+
+// Message returns one stored message with its parts.
+func (s *SessionStore) Message(sessionID, messageID string) (SessionMessagesResponse, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	msg, ok := s.messages[sessionID][messageID]
+	if !ok {
+		return SessionMessagesResponse{}, false
+	}
+	return SessionMessagesResponse{Info: msg.info, Parts: append([]Part(nil), msg.parts...)}, true
+}
+
+// Part returns one stored part.
+func (s *SessionStore) Part(sessionID, messageID, partID string) (Part, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	msg, ok := s.messages[sessionID][messageID]
+	if !ok {
+		return Part{}, false
+	}
+	for _, part := range msg.parts {
+		if part.ID == partID {
+			return part, true
+		}
+	}
+	return Part{}, false
+}
+
+// OnChange registers fn to be called after every change to the store and
+// returns a function that unregisters it. Callbacks run synchronously on the
+// goroutine that made the change, outside the store's lock, so they may
+// query the store but should return promptly.
+func (s *SessionStore) OnChange(fn func(SessionStoreChange)) (unsubscribe func()) {
+	s.listenersMu.Lock()
+	id := s.nextListenerID
+	s.nextListenerID++
+	s.listeners[id] = fn
+	s.listenersMu.Unlock()
+
+	return func() {
+		s.listenersMu.Lock()
+		delete(s.listeners, id)
+		s.listenersMu.Unlock()
+	}
+}
+
+func (s *SessionStore) notify(change SessionStoreChange) {
+	s.listenersMu.Lock()
+	listeners := make([]func(SessionStoreChange), 0, len(s.listeners))
+	for _, fn := range s.listeners {
+		listeners = append(listeners, fn)
+	}
+	s.listenersMu.Unlock()
+
+	for _, fn := range listeners {
+		fn(change)
+	}
+}
@@ -0,0 +1,227 @@
+package opencode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func mustEvent(t *testing.T, raw string) Event {
+	t.Helper()
+	var event Event
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		t.Fatalf("failed to unmarshal event: %v", err)
+	}
+	return event
+}
+
+func TestSessionStore_ApplySessionLifecycle(t *testing.T) {
+	store := NewSessionStore(nil, nil)
+
+	if !store.Apply(mustEvent(t, `{"type":"session.created","properties":{"info":{"id":"s1","title":"first","time":{"created":1,"updated":1}}}}`)) {
+		t.Fatal("expected session.created to change the store")
+	}
+	store.Apply(mustEvent(t, `{"type":"session.created","properties":{"info":{"id":"s2","title":"second","time":{"created":2,"updated":2}}}}`))
+	store.Apply(mustEvent(t, `{"type":"session.updated","properties":{"info":{"id":"s1","title":"renamed","time":{"created":1,"updated":3}}}}`))
+
+	sessions := store.Sessions()
+	if len(sessions) != 2 || sessions[0].ID != "s1" || sessions[0].Title != "renamed" {
+		t.Fatalf("expected s1 (renamed) first, got %+v", sessions)
+	}
+
+	store.Apply(mustEvent(t, `{"type":"message.updated","properties":{"info":{"id":"m1","role":"user","sessionID":"s2"}}}`))
+	store.Apply(mustEvent(t, `{"type":"session.deleted","properties":{"info":{"id":"s2"}}}`))
+	if _, ok := store.Session("s2"); ok {
+		t.Fatal("expected s2 to be deleted")
+	}
+	if msgs := store.Messages("s2"); len(msgs) != 0 {
+		t.Fatalf("expected messages of deleted session to be dropped, got %d", len(msgs))
+	}
+}
+
+func TestSessionStore_ApplyMessagesAndParts(t *testing.T) {
+	store := NewSessionStore(nil, nil)
+
+	// A part can arrive before its message.
+	store.Apply(mustEvent(t, `{"type":"message.part.updated","properties":{"part":{"id":"p1","messageID":"m2","sessionID":"s1","type":"text","text":"hel"}}}`))
+	store.Apply(mustEvent(t, `{"type":"message.updated","properties":{"info":{"id":"m2","role":"assistant","sessionID":"s1"}}}`))
+	store.Apply(mustEvent(t, `{"type":"message.updated","properties":{"info":{"id":"m1","role":"user","sessionID":"s1"}}}`))
+	store.Apply(mustEvent(t, `{"type":"message.part.updated","properties":{"part":{"id":"p1","messageID":"m2","sessionID":"s1","type":"text","text":"hello"}}}`))
+	store.Apply(mustEvent(t, `{"type":"message.part.updated","properties":{"part":{"id":"p2","messageID":"m2","sessionID":"s1","type":"step-finish"}}}`))
+
+	msgs := store.Messages("s1")
+	if len(msgs) != 2 || msgs[0].Info.ID != "m1" || msgs[1].Info.ID != "m2" {
+		t.Fatalf("expected messages [m1 m2], got %+v", msgs)
+	}
+	if msgs[1].Info.Role != MessageRoleAssistant {
+		t.Errorf("expected placeholder to be replaced by assistant message, got role %q", msgs[1].Info.Role)
+	}
+	if len(msgs[1].Parts) != 2 {
+		t.Fatalf("expected 2 parts, got %d", len(msgs[1].Parts))
+	}
+
+	part, ok := store.Part("s1", "m2", "p1")
+	if !ok {
+		t.Fatal("expected part p1")
+	}
+	text, err := part.AsText()
+	if err != nil || text.Text != "hello" {
+		t.Fatalf("expected updated text %q, got %+v, %v", "hello", text, err)
+	}
+
+	store.Apply(mustEvent(t, `{"type":"message.part.removed","properties":{"sessionID":"s1","messageID":"m2","partID":"p1"}}`))
+	if _, ok := store.Part("s1", "m2", "p1"); ok {
+		t.Fatal("expected p1 to be removed")
+	}
+	store.Apply(mustEvent(t, `{"type":"message.removed","properties":{"sessionID":"s1","messageID":"m2"}}`))
+	if _, ok := store.Message("s1", "m2"); ok {
+		t.Fatal("expected m2 to be removed")
+	}
+}
+
+func TestSessionStore_ApplyIgnoresUnrelatedEvents(t *testing.T) {
+	store := NewSessionStore(nil, nil)
+	called := false
+	store.OnChange(func(SessionStoreChange) { called = true })
+
+	if store.Apply(mustEvent(t, `{"type":"file.edited","properties":{"file":"a.go"}}`)) {
+		t.Fatal("expected file.edited to be ignored")
+	}
+	if called {
+		t.Fatal("expected no change notification")
+	}
+}
+
+func TestSessionStore_OnChangeAndUnsubscribe(t *testing.T) {
+	store := NewSessionStore(nil, nil)
+	var changes []SessionStoreChange
+	unsubscribe := store.OnChange(func(c SessionStoreChange) { changes = append(changes, c) })
+
+	store.Apply(mustEvent(t, `{"type":"message.part.updated","properties":{"part":{"id":"p1","messageID":"m1","sessionID":"s1","type":"text"}}}`))
+	unsubscribe()
+	store.Apply(mustEvent(t, `{"type":"message.part.updated","properties":{"part":{"id":"p2","messageID":"m1","sessionID":"s1","type":"text"}}}`))
+
+	want := SessionStoreChange{Kind: SessionStoreChangePartUpdated, SessionID: "s1", MessageID: "m1", PartID: "p1"}
+	if len(changes) != 1 || changes[0] != want {
+		t.Fatalf("expected %+v only, got %+v", want, changes)
+	}
+}
+
+func newSessionStoreServer(t *testing.T, eventHandler http.HandlerFunc) (*Client, *atomic.Int32) {
+	t.Helper()
+	var syncs atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/session", func(w http.ResponseWriter, r *http.Request) {
+		syncs.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"s1","title":"one","time":{"created":1,"updated":1}}]`))
+	})
+	mux.HandleFunc("/session/s1/message", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"info":{"id":"m1","role":"user","sessionID":"s1"},"parts":[{"id":"p1","messageID":"m1","sessionID":"s1","type":"text","text":"hi"}]}]`))
+	})
+	mux.HandleFunc("/event", eventHandler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client, &syncs
+}
+
+func TestSessionStore_Sync(t *testing.T) {
+	client, _ := newSessionStoreServer(t, http.NotFound)
+	store := NewSessionStore(client, nil)
+
+	var synced bool
+	store.OnChange(func(c SessionStoreChange) { synced = synced || c.Kind == SessionStoreChangeSynced })
+	if err := store.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if !synced {
+		t.Error("expected synced notification")
+	}
+	if _, ok := store.Session("s1"); !ok {
+		t.Fatal("expected session s1")
+	}
+	if _, ok := store.Part("s1", "m1", "p1"); !ok {
+		t.Fatal("expected part p1 from bootstrap")
+	}
+}
+
+func TestSessionStore_RunAppliesEventsAndResyncsAfterReconnect(t *testing.T) {
+	var connects atomic.Int32
+	client, syncs := newSessionStoreServer(t, func(w http.ResponseWriter, r *http.Request) {
+		n := connects.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		if n == 1 {
+			// First connection delivers one event and drops.
+			_, _ = w.Write([]byte(`data: {"type":"session.updated","properties":{"info":{"id":"s1","title":"live","time":{"created":1,"updated":2}}}}` + "\n\n"))
+			return
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	store := NewSessionStore(client, nil)
+	store.backoff = func(int) time.Duration { return time.Millisecond }
+
+	var mu sync.Mutex
+	var kinds []SessionStoreChangeKind
+	resynced := make(chan struct{})
+	store.OnChange(func(c SessionStoreChange) {
+		mu.Lock()
+		defer mu.Unlock()
+		kinds = append(kinds, c.Kind)
+		if c.Kind == SessionStoreChangeSynced && syncs.Load() == 2 {
+			close(resynced)
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- store.Run(ctx) }()
+
+	select {
+	case <-resynced:
+	case <-time.After(5 * time.Second):
+		t.Fatal("store did not resync after reconnect")
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []SessionStoreChangeKind{SessionStoreChangeSynced, SessionStoreChangeSessionUpdated, SessionStoreChangeSynced}
+	if len(kinds) != len(want) {
+		t.Fatalf("expected changes %v, got %v", want, kinds)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("expected changes %v, got %v", want, kinds)
+		}
+	}
+}
+
+func TestSessionStore_RunStopsOnNonRetryableError(t *testing.T) {
+	client, _ := newSessionStoreServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "denied", http.StatusForbidden)
+	})
+	store := NewSessionStore(client, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := store.Run(ctx); !IsForbiddenError(err) {
+		t.Fatalf("expected forbidden error, got %v", err)
+	}
+}
@@ -0,0 +1,294 @@
+package opencode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultSessionTreeConcurrency is the number of Children requests Tree runs
+// in parallel when SessionTreeParams.Concurrency is zero.
+const DefaultSessionTreeConcurrency = 4
+
+// ErrSkipChildren can be returned by a SessionTreeNode.Walk visitor to skip
+// the children of the node being visited. It is never returned by Walk.
+var ErrSkipChildren = errors.New("skip children")
+
+var errStopWalk = errors.New("stop walk")
+
+// SessionTreeParams configures Session.Tree.
+type SessionTreeParams struct {
+	Directory *string
+	// MaxDepth limits how many levels below the root are fetched. Zero
+	// fetches the whole tree.
+	MaxDepth int
+	// Concurrency bounds the number of Children requests in flight. Zero
+	// uses DefaultSessionTreeConcurrency.
+	Concurrency int
+}
+
+// SessionTreeNode is a session together with its descendants, as returned by
+// Session.Tree. Children are ordered by creation time.
+type SessionTreeNode struct {
+	Session Session
+	// Depth is 0 for the root of the tree, 1 for its children, and so on.
+	Depth    int
+	Children []*SessionTreeNode
+	// Truncated is true when MaxDepth stopped Tree before this node's
+	// children were fetched. The session may or may not have children.
+	Truncated bool
+}
+
+// Tree fetches the session id and, level by level, all of its descendants:
+// the subagent sessions it spawned, the sessions those spawned, and so on.
+// Children requests for a level run concurrently. The first failed request
+// cancels the rest and is returned.
+func (s *SessionService) Tree(ctx context.Context, id string, params *SessionTreeParams) (*SessionTreeNode, error) {
+	if params == nil {
+		params = &SessionTreeParams{}
+	}
+	if params.MaxDepth < 0 {
+		return nil, errors.New("session tree: max depth must not be negative")
+	}
+	if params.Concurrency < 0 {
+		return nil, errors.New("session tree: concurrency must not be negative")
+	}
+	concurrency := params.Concurrency
+	if concurrency == 0 {
+		concurrency = DefaultSessionTreeConcurrency
+	}
+
+	root, err := s.Get(ctx, id, &SessionGetParams{Directory: params.Directory})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	tree := &SessionTreeNode{Session: *root}
+	// seen guards against a malformed hierarchy looping back on itself.
+	seen := map[string]bool{root.ID: true}
+	level := []*SessionTreeNode{tree}
+	for depth := 0; len(level) > 0; depth++ {
+		if params.MaxDepth > 0 && depth >= params.MaxDepth {
+			for _, node := range level {
+				node.Truncated = true
+			}
+			break
+		}
+
+		children, err := s.levelChildren(ctx, level, params.Directory, concurrency)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []*SessionTreeNode
+		for i, parent := range level {
+			sortSessions(children[i])
+			for _, child := range children[i] {
+				if seen[child.ID] {
+					continue
+				}
+				seen[child.ID] = true
+				node := &SessionTreeNode{Session: child, Depth: depth + 1}
+				parent.Children = append(parent.Children, node)
+				next = append(next, node)
+			}
+		}
+		level = next
+	}
+	return tree, nil
+}
+
+// levelChildren fetches the children of every node in level, running at most
+// concurrency requests at a time. Results are indexed like level.
+func (s *SessionService) levelChildren(ctx context.Context, level []*SessionTreeNode, directory *string, concurrency int) ([][]Session, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]Session, len(level))
+	sem := make(chan struct{}, concurrency)
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for i, node := range level {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			children, err := s.Children(ctx, id, &SessionChildrenParams{Directory: directory})
+			if err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("session tree: children of %s: %w", id, err)
+					cancel()
+				})
+				return
+			}
+			results[i] = children
+		}(i, node.Session.ID)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("session tree: %w", err)
+	}
+	return results, nil
+}
+
+func sortSessions(sessions []Session) {
+	sort.SliceStable(sessions, func(i, j int) bool {
+		if sessions[i].Time.Created != sessions[j].Time.Created {
+			return sessions[i].Time.Created < sessions[j].Time.Created
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+}
+
+// Walk calls fn for n and each of its descendants in depth-first pre-order.
+// If fn returns ErrSkipChildren the node's children are not visited; any
+// other error stops the walk and is returned.
+func (n *SessionTreeNode) Walk(fn func(node *SessionTreeNode) error) error {
+	if n == nil {
+		return nil
+	}
+	if err := fn(n); err != nil {
+		if errors.Is(err, ErrSkipChildren) {
+			return nil
+		}
+		return err
+	}
+	for _, child := range n.Children {
+		if err := child.Walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Find returns the node for session id, or nil if it is not in the tree.
+func (n *SessionTreeNode) Find(id string) *SessionTreeNode {
+	var found *SessionTreeNode
+	_ = n.Walk(func(node *SessionTreeNode) error {
+		if node.Session.ID == id {
+			found = node
+			return errStopWalk
+		}
+		return nil
+	})
+	return found
+}
+
+// Len returns the number of sessions in the tree.
+func (n *SessionTreeNode) Len() int {
+	count := 0
+	_ = n.Walk(func(*SessionTreeNode) error {
+		count++
+		return nil
+	})
+	return count
+}
+
+// Render writes the tree as indented text for terminal output, one session
+// per line:
+//
+//	Fix the build (ses_1)
+//	├── Explore test failures (ses_2)
+//	│   └── Read logs (ses_4) …
+//	└── Patch CI config (ses_3)
+//
+// A trailing ellipsis marks nodes whose children were not fetched because
+// of MaxDepth.
+func (n *SessionTreeNode) Render(w io.Writer) error {
+	if n == nil {
+		return nil
+	}
+	var b strings.Builder
+	writeTreeLine(&b, n)
+	renderChildren(&b, n, "")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// String returns the tree as rendered by Render.
+func (n *SessionTreeNode) String() string {
+	var b strings.Builder
+	_ = n.Render(&b)
+	return b.String()
+}
+
+func renderChildren(b *strings.Builder, n *SessionTreeNode, prefix string) {
+	for i, child := range n.Children {
+		branch, indent := "├── ", "│   "
+		if i == len(n.Children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		b.WriteString(prefix)
+		b.WriteString(branch)
+		writeTreeLine(b, child)
+		renderChildren(b, child, prefix+indent)
+	}
+}
+
+func writeTreeLine(b *strings.Builder, n *SessionTreeNode) {
+	title := strings.Join(strings.Fields(n.Session.Title), " ")
+	if title == "" {
+		title = "(untitled)"
+	}
+	fmt.Fprintf(b, "%s (%s)", title, n.Session.ID)
+	if n.Truncated {
+		b.WriteString(" …")
+	}
+	b.WriteByte('\n')
+}
+
+// Ancestors returns session id followed by its parent, grandparent and so on
+// up to the root session, fetching each with Get.
+func (s *SessionService) Ancestors(ctx context.Context, id string, params *SessionGetParams) ([]Session, error) {
+	var chain []Session
+	seen := make(map[string]bool)
+	for {
+		session, err := s.Get(ctx, id, params)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, *session)
+		seen[session.ID] = true
+
+		if session.ParentID == nil || *session.ParentID == "" {
+			return chain, nil
+		}
+		id = *session.ParentID
+		if seen[id] {
+			return nil, fmt.Errorf("session %s: parent cycle through %s", chain[0].ID, id)
+		}
+	}
+}
+
+// Root returns the top-level session that session id descends from, or the
+// session itself if it has no parent.
+func (s *SessionService) Root(ctx context.Context, id string, params *SessionGetParams) (*Session, error) {
+	chain, err := s.Ancestors(ctx, id, params)
+	if err != nil {
+		return nil, err
+	}
+	return &chain[len(chain)-1], nil
+}
@@ -0,0 +1,261 @@
+package opencode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// treeServer serves Get and Children for a fixed hierarchy of sessions.
+type treeServer struct {
+	sessions map[string]Session
+	failOn   string
+	delay    time.Duration
+
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+	mu          sync.Mutex
+	childCalls  []string
+}
+
+func newTreeServer(sessions ...Session) *treeServer {
+	s := &treeServer{sessions: make(map[string]Session)}
+	for _, session := range sessions {
+		s.sessions[session.ID] = session
+	}
+	return s
+}
+
+func treeSession(id, parent, title string, created float64) Session {
+	s := Session{ID: id, Title: title, Time: SessionTime{Created: created}}
+	if parent != "" {
+		s.ParentID = Ptr(parent)
+	}
+	return s
+}
+
+func (s *treeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case len(parts) == 2 && parts[0] == "session":
+		session, ok := s.sessions[parts[1]]
+		if !ok {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(session)
+	case len(parts) == 3 && parts[2] == "children":
+		n := s.inFlight.Add(1)
+		defer s.inFlight.Add(-1)
+		for {
+			peak := s.maxInFlight.Load()
+			if n <= peak || s.maxInFlight.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		s.mu.Lock()
+		s.childCalls = append(s.childCalls, parts[1])
+		s.mu.Unlock()
+		time.Sleep(s.delay)
+
+		if parts[1] == s.failOn {
+			http.Error(w, `{"message":"boom"}`, http.StatusBadRequest)
+			return
+		}
+		children := []Session{}
+		for _, session := range s.sessions {
+			if session.ParentID != nil && *session.ParentID == parts[1] {
+				children = append(children, session)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(children)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func treeFixture() *treeServer {
+	return newTreeServer(
+		treeSession("ses_root", "", "Fix the build", 1),
+		treeSession("ses_b", "ses_root", "Patch CI config", 3),
+		treeSession("ses_a", "ses_root", "Explore  test\nfailures", 2),
+		treeSession("ses_a1", "ses_a", "", 4),
+		treeSession("ses_a1x", "ses_a1", "Deep", 5),
+	)
+}
+
+func treeClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client
+}
+
+func TestSessionTree(t *testing.T) {
+	client := treeClient(t, treeFixture())
+
+	tree, err := client.Session.Tree(context.Background(), "ses_root", nil)
+	if err != nil {
+		t.Fatalf("Tree: %v", err)
+	}
+	if tree.Len() != 5 {
+		t.Fatalf("expected 5 sessions, got %d", tree.Len())
+	}
+
+	want := "Fix the build (ses_root)\n" +
+		"├── Explore test failures (ses_a)\n" +
+		"│   └── (untitled) (ses_a1)\n" +
+		"│       └── Deep (ses_a1x)\n" +
+		"└── Patch CI config (ses_b)\n"
+	if got := tree.String(); got != want {
+		t.Errorf("unexpected rendering:\n%s\nwant:\n%s", got, want)
+	}
+
+	node := tree.Find("ses_a1x")
+	if node == nil || node.Depth != 3 {
+		t.Fatalf("expected ses_a1x at depth 3, got %+v", node)
+	}
+	if tree.Find("ses_missing") != nil {
+		t.Error("expected nil for unknown session")
+	}
+}
+
+func TestSessionTree_MaxDepth(t *testing.T) {
+	srv := treeFixture()
+	client := treeClient(t, srv)
+
+	tree, err := client.Session.Tree(context.Background(), "ses_root", &SessionTreeParams{MaxDepth: 1})
+	if err != nil {
+		t.Fatalf("Tree: %v", err)
+	}
+	if tree.Len() != 3 {
+		t.Fatalf("expected 3 sessions, got %d", tree.Len())
+	}
+	if tree.Truncated || !tree.Children[0].Truncated {
+		t.Error("expected only depth-1 nodes to be truncated")
+	}
+	if len(srv.childCalls) != 1 {
+		t.Errorf("expected one Children request, got %v", srv.childCalls)
+	}
+	if !strings.Contains(tree.String(), "Patch CI config (ses_b) …\n") {
+		t.Errorf("expected truncation marker, got:\n%s", tree.String())
+	}
+
+	if _, err := client.Session.Tree(context.Background(), "ses_root", &SessionTreeParams{MaxDepth: -1}); err == nil {
+		t.Error("expected error for negative depth")
+	}
+}
+
+func TestSessionTree_Concurrency(t *testing.T) {
+	sessions := []Session{treeSession("ses_root", "", "root", 0)}
+	for i := 0; i < 10; i++ {
+		sessions = append(sessions, treeSession("ses_"+string(rune('a'+i)), "ses_root", "", float64(i)))
+	}
+	srv := newTreeServer(sessions...)
+	srv.delay = 20 * time.Millisecond
+	client := treeClient(t, srv)
+
+	tree, err := client.Session.Tree(context.Background(), "ses_root", &SessionTreeParams{Concurrency: 3})
+	if err != nil {
+		t.Fatalf("Tree: %v", err)
+	}
+	if tree.Len() != 11 {
+		t.Fatalf("expected 11 sessions, got %d", tree.Len())
+	}
+	if peak := srv.maxInFlight.Load(); peak < 2 || peak > 3 {
+		t.Errorf("expected between 2 and 3 concurrent requests, got %d", peak)
+	}
+}
+
+func TestSessionTree_ChildrenError(t *testing.T) {
+	srv := treeFixture()
+	srv.failOn = "ses_a"
+	client := treeClient(t, srv)
+
+	_, err := client.Session.Tree(context.Background(), "ses_root", nil)
+	if !errors.Is(err, ErrInvalidRequest) || !strings.Contains(err.Error(), "children of ses_a") {
+		t.Fatalf("expected children error for ses_a, got %v", err)
+	}
+
+	_, err = client.Session.Tree(context.Background(), "ses_missing", nil)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSessionTreeNode_Walk(t *testing.T) {
+	client := treeClient(t, treeFixture())
+	tree, err := client.Session.Tree(context.Background(), "ses_root", nil)
+	if err != nil {
+		t.Fatalf("Tree: %v", err)
+	}
+
+	var visited []string
+	err = tree.Walk(func(node *SessionTreeNode) error {
+		visited = append(visited, node.Session.ID)
+		if node.Session.ID == "ses_a1" {
+			return ErrSkipChildren
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+	if got := strings.Join(visited, ","); got != "ses_root,ses_a,ses_a1,ses_b" {
+		t.Errorf("unexpected visit order %s", got)
+	}
+
+	stop := errors.New("stop")
+	visited = nil
+	err = tree.Walk(func(node *SessionTreeNode) error {
+		visited = append(visited, node.Session.ID)
+		if node.Session.ID == "ses_a" {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || len(visited) != 2 {
+		t.Errorf("expected walk to stop after ses_a, got %v after %v", err, visited)
+	}
+}
+
+func TestSessionRootAndAncestors(t *testing.T) {
+	client := treeClient(t, treeFixture())
+
+	chain, err := client.Session.Ancestors(context.Background(), "ses_a1x", nil)
+	if err != nil {
+		t.Fatalf("Ancestors: %v", err)
+	}
+	var ids []string
+	for _, s := range chain {
+		ids = append(ids, s.ID)
+	}
+	if got := strings.Join(ids, ","); got != "ses_a1x,ses_a1,ses_a,ses_root" {
+		t.Errorf("unexpected ancestors %s", got)
+	}
+
+	root, err := client.Session.Root(context.Background(), "ses_root", nil)
+	if err != nil || root.ID != "ses_root" {
+		t.Errorf("expected root to be itself, got %+v (%v)", root, err)
+	}
+
+	cyclic := treeClient(t, newTreeServer(
+		treeSession("ses_x", "ses_y", "", 0),
+		treeSession("ses_y", "ses_x", "", 0),
+	))
+	if _, err := cyclic.Session.Root(context.Background(), "ses_x", nil); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("expected cycle error, got %v", err)
+	}
+}
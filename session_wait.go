@@ -0,0 +1,180 @@
+package opencode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SessionWaitParams configures WaitIdle and WaitFor.
+type SessionWaitParams struct {
+	// Directory scopes the event stream to one workspace. Nil uses the
+	// server's default directory.
+	Directory *string
+	// StreamOptions are passed to EventService.ListStreaming.
+	StreamOptions []EventStreamOption
+}
+
+// SessionWaitResult describes how a wait on a session ended.
+type SessionWaitResult struct {
+	SessionID string
+	// Event is the event that ended the wait. It is the zero Event when
+	// WaitIdle found the session already idle.
+	Event Event
+	// LastMessage is the most recent message seen for the session, from a
+	// message.updated event or, for WaitIdle, from the session's history.
+	LastMessage *Message
+	// Error is the session.error payload when the session failed.
+	Error *EventSessionErrorData
+}
+
+// Failed reports whether the wait ended because the session reported an
+// error.
+func (r SessionWaitResult) Failed() bool {
+	return r.Error != nil
+}
+
+// WaitIdle blocks until the session has finished all work, including
+// follow-up tool steps, which is signalled by session.idle. It is meant to be
+// called after Command, Shell or an asynchronous prompt:
+//
+//	if _, err := client.Session.Command(ctx, id, params); err != nil {
+//		...
+//	}
+//	result, err := client.Session.WaitIdle(ctx, id, nil)
+//
+// Because the session may already be idle by the time the event stream is
+// open, WaitIdle first checks the session's latest message. It returns
+// immediately if that is a completed assistant message whose last step
+// ended the turn rather than calling tools, and returns a
+// *SessionFailedError if the message recorded an error. Otherwise it waits
+// for session.idle. If the session reports session.error, WaitIdle returns
+// the result together with a *SessionFailedError.
+func (s *SessionService) WaitIdle(ctx context.Context, id string, params *SessionWaitParams) (*SessionWaitResult, error) {
+	return s.wait(ctx, id, params, true, func(event Event) bool {
+		return event.Type == EventTypeSessionIdle
+	})
+}
+
+// WaitFor blocks until predicate returns true for an event concerning the
+// session, as reported by Event.SessionID. Events for other sessions are
+// not passed to predicate. Like WaitIdle, it stops with a
+// *SessionFailedError if the session reports session.error first.
+func (s *SessionService) WaitFor(ctx context.Context, id string, predicate func(Event) bool, params *SessionWaitParams) (*SessionWaitResult, error) {
+	if predicate == nil {
+		return nil, missingRequiredParameterError("predicate")
+	}
+	return s.wait(ctx, id, params, false, predicate)
+}
+
+func (s *SessionService) wait(ctx context.Context, id string, params *SessionWaitParams, checkIdle bool, done func(Event) bool) (*SessionWaitResult, error) {
+	if ctx == nil {
+		return nil, ErrContextRequired
+	}
+	if strings.TrimSpace(id) == "" {
+		return nil, missingRequiredParameterError("id")
+	}
+	if params == nil {
+		params = &SessionWaitParams{}
+	}
+
+	// Open the stream before anything else so no event is missed.
+	stream := s.client.Event.ListStreaming(ctx, &EventListParams{Directory: params.Directory}, params.StreamOptions...)
+	defer func() { _ = stream.Close() }()
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &SessionWaitResult{SessionID: id}
+	if checkIdle {
+		msgs, err := s.Messages(ctx, id, &SessionMessagesParams{Directory: params.Directory})
+		if err != nil {
+			return nil, err
+		}
+		if len(msgs) == 0 {
+			// Nothing has been sent, so there is nothing to wait for.
+			return result, nil
+		}
+		last := msgs[len(msgs)-1]
+		result.LastMessage = &last.Info
+		if assistant, err := last.Info.AsAssistant(); err == nil && assistant.Error.Name != "" {
+			failed := assistantFailed(id, assistant.Error)
+			sessionErr := failed.Err
+			result.Error = &EventSessionErrorData{Error: &sessionErr, SessionID: &id}
+			return result, failed
+		}
+		if turnFinished(last) {
+			return result, nil
+		}
+	}
+
+	for stream.Next() {
+		event := stream.Current()
+		if sessionID, ok := event.SessionID(); !ok || sessionID != id {
+			continue
+		}
+		if event.Type == EventTypeMessageUpdated {
+			if evt, err := event.AsMessageUpdated(); err == nil {
+				info := evt.Data.Info
+				result.LastMessage = &info
+			}
+		}
+		if event.Type == EventTypeSessionError {
+			result.Event = event
+			if evt, err := event.AsSessionError(); err == nil {
+				data := evt.Data
+				result.Error = &data
+			}
+			return result, sessionFailure(event, id)
+		}
+		if done(event) {
+			result.Event = event
+			return result, nil
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("wait for session %s: %w", id, errEventStreamEnded)
+}
+
+// messageSettled reports whether msg is an assistant message the server has
+// finished, successfully or not. A nil msg means the session has no
+// messages and so nothing to wait for.
+func messageSettled(msg *Message) bool {
+	if msg == nil {
+		return true
+	}
+	assistant, err := msg.AsAssistant()
+	if err != nil {
+		return false
+	}
+	return assistant.Time.Completed > 0 || assistant.Error.Name != ""
+}
+
+// turnFinished reports whether msg is a completed assistant message whose
+// last step ended the agent loop. A completed message alone is not enough:
+// each step of a turn completes its message, and a step that ends in tool
+// calls is followed by another. The server exposes no session status to ask
+// instead, so anything else is left to session.idle.
+func turnFinished(msg SessionMessagesResponse) bool {
+	assistant, err := msg.Info.AsAssistant()
+	if err != nil || assistant.Time.Completed == 0 {
+		return false
+	}
+	reason := ""
+	for _, part := range msg.Parts {
+		switch part.Type {
+		case PartTypeStepFinish:
+			if step, err := part.AsStepFinish(); err == nil {
+				reason = step.Reason
+			}
+		case PartTypeTool:
+			if tool, err := part.AsTool(); err != nil ||
+				tool.State.Status == ToolPartStateStatusPending || tool.State.Status == ToolPartStateStatusRunning {
+				return false
+			}
+		}
+	}
+	return reason != "" && reason != "tool-calls"
+}
@@ -0,0 +1,174 @@
+package opencode
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newSessionWaitServer(t *testing.T, history string, events ...string) *Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/session/s1/message", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(history))
+	})
+	mux.HandleFunc("/event", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, evt := range events {
+			_, _ = w.Write([]byte("data: " + evt + "\n\n"))
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client
+}
+
+const busyHistory = `[{"info":{"id":"m1","role":"user","sessionID":"s1"},"parts":[]}]`
+
+func waitContext(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestSessionWaitIdle_AlreadyIdle(t *testing.T) {
+	client := newSessionWaitServer(t, `[
+		{"info":{"id":"m1","role":"user","sessionID":"s1"},"parts":[]},
+		{"info":{"id":"m2","role":"assistant","sessionID":"s1","time":{"created":1,"completed":2}},"parts":[
+			{"id":"p1","type":"step-finish","reason":"stop"}
+		]}
+	]`)
+
+	result, err := client.Session.WaitIdle(waitContext(t), "s1", nil)
+	if err != nil {
+		t.Fatalf("WaitIdle failed: %v", err)
+	}
+	if result.Event.Type != "" || result.LastMessage == nil || result.LastMessage.ID != "m2" {
+		t.Fatalf("expected idle from history with last message m2, got %+v", result)
+	}
+}
+
+func TestSessionWaitIdle_CompletedStepIsNotIdle(t *testing.T) {
+	// The last step ended in tool calls, so another step is about to start
+	// even though the message is complete.
+	client := newSessionWaitServer(t, `[
+		{"info":{"id":"m2","role":"assistant","sessionID":"s1","time":{"created":1,"completed":2}},"parts":[
+			{"id":"p1","type":"tool","tool":"bash","state":{"status":"completed","input":{},"output":"","title":"","metadata":{},"time":{"start":1,"end":2}}},
+			{"id":"p2","type":"step-finish","reason":"tool-calls"}
+		]}
+	]`,
+		`{"type":"session.idle","properties":{"sessionID":"s1"}}`,
+	)
+
+	result, err := client.Session.WaitIdle(waitContext(t), "s1", nil)
+	if err != nil {
+		t.Fatalf("WaitIdle failed: %v", err)
+	}
+	if result.Event.Type != EventTypeSessionIdle {
+		t.Fatalf("expected to wait for session.idle, got %+v", result)
+	}
+}
+
+func TestSessionWaitIdle_FailedLastMessage(t *testing.T) {
+	client := newSessionWaitServer(t, `[
+		{"info":{"id":"m2","role":"assistant","sessionID":"s1","time":{"created":1},
+			"error":{"name":"ProviderAuthError","data":{"providerID":"p","message":"bad key"}}},"parts":[]}
+	]`)
+
+	result, err := client.Session.WaitIdle(waitContext(t), "s1", nil)
+	var failed *SessionFailedError
+	if !errors.As(err, &failed) || failed.Err.Name != SessionErrorNameProviderAuthError {
+		t.Fatalf("expected *SessionFailedError, got %v", err)
+	}
+	if result == nil || !result.Failed() || result.Error.Error.Name != SessionErrorNameProviderAuthError {
+		t.Fatalf("expected failed result, got %+v", result)
+	}
+}
+
+func TestSessionWaitIdle_WaitsForIdleEvent(t *testing.T) {
+	client := newSessionWaitServer(t, busyHistory,
+		`{"type":"session.idle","properties":{"sessionID":"other"}}`,
+		`{"type":"message.updated","properties":{"info":{"id":"m2","role":"assistant","sessionID":"s1","time":{"created":1,"completed":2}}}}`,
+		`{"type":"session.idle","properties":{"sessionID":"s1"}}`,
+	)
+
+	result, err := client.Session.WaitIdle(waitContext(t), "s1", nil)
+	if err != nil {
+		t.Fatalf("WaitIdle failed: %v", err)
+	}
+	if result.Event.Type != EventTypeSessionIdle || result.Failed() {
+		t.Fatalf("expected session.idle, got %+v", result)
+	}
+	if result.LastMessage == nil || result.LastMessage.ID != "m2" {
+		t.Fatalf("expected last message m2, got %+v", result.LastMessage)
+	}
+}
+
+func TestSessionWaitIdle_ReportsSessionError(t *testing.T) {
+	client := newSessionWaitServer(t, busyHistory,
+		`{"type":"session.error","properties":{"sessionID":"s1","error":{"name":"ProviderAuthError","data":{"providerID":"p","message":"bad key"}}}}`,
+		`{"type":"session.idle","properties":{"sessionID":"s1"}}`,
+	)
+
+	result, err := client.Session.WaitIdle(waitContext(t), "s1", nil)
+	if !errors.Is(err, ErrSessionFailed) {
+		t.Fatalf("expected ErrSessionFailed, got %v", err)
+	}
+	if result == nil || !result.Failed() || result.Error.Error == nil {
+		t.Fatalf("expected failed result with error payload, got %+v", result)
+	}
+	if _, err := result.Error.Error.AsProviderAuth(); err != nil {
+		t.Fatalf("expected provider auth error, got %v", err)
+	}
+}
+
+func TestSessionWaitFor_Predicate(t *testing.T) {
+	client := newSessionWaitServer(t, busyHistory,
+		`{"type":"permission.updated","properties":{"id":"perm0","messageID":"m9","sessionID":"other","type":"bash","title":"x","metadata":{},"time":{"created":1}}}`,
+		`{"type":"todo.updated","properties":{"sessionID":"s1","todos":[]}}`,
+		`{"type":"permission.updated","properties":{"id":"perm1","messageID":"m2","sessionID":"s1","type":"bash","title":"run","metadata":{},"time":{"created":1}}}`,
+	)
+
+	result, err := client.Session.WaitFor(waitContext(t), "s1", func(e Event) bool {
+		return e.Type == EventTypePermissionUpdated
+	}, nil)
+	if err != nil {
+		t.Fatalf("WaitFor failed: %v", err)
+	}
+	evt, err := result.Event.AsPermissionUpdated()
+	if err != nil || evt.Data.ID != "perm1" {
+		t.Fatalf("expected perm1, got %+v, %v", evt, err)
+	}
+}
+
+func TestSessionWait_Validation(t *testing.T) {
+	client := newSessionWaitServer(t, busyHistory)
+	if _, err := client.Session.WaitFor(context.Background(), "s1", nil, nil); !errors.Is(err, ErrMissingRequiredParameter) {
+		t.Errorf("expected missing predicate error, got %v", err)
+	}
+	if _, err := client.Session.WaitIdle(context.Background(), " ", nil); !errors.Is(err, ErrMissingRequiredParameter) {
+		t.Errorf("expected missing id error, got %v", err)
+	}
+}
+
+func TestSessionWaitIdle_ContextCanceled(t *testing.T) {
+	client := newSessionWaitServer(t, busyHistory)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.Session.WaitIdle(ctx, "s1", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,805 @@
+package opencode
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dominicnunez/opencode-sdk-go/shared"
+)
+
+// Each union type has a Visit method that decodes the value and calls the
+// visitor method for its variant, and an Any method that returns the decoded
+// variant as a pointer to its concrete type. A visitor interface gains a
+// method whenever its union gains a variant, so implementations fail to
+// compile until they handle it. The *VisitorFuncs structs implement each
+// interface from optional callbacks for code that only cares about some
+// variants.
+//
+// Visit and Any return an error matching ErrUnknownVariant for a
+// discriminator the SDK does not know, and the As* error if the variant
+// fails to decode. Event.Visit is the exception for unknown types: it
+// passes them to VisitCustom. An error returned by a visitor method is
+// passed through unchanged.
+
+// visitVariant decodes a variant with as and passes it to visit.
+func visitVariant[T any](as func() (*T, error), visit func(*T) error) error {
+	v, err := as()
+	if err != nil {
+		return err
+	}
+	return visit(v)
+}
+
+// visitFunc calls fn, or fallback when fn is nil.
+func visitFunc[T any](fn func(*T) error, fallback func(any) error, v *T) error {
+	if fn != nil {
+		return fn(v)
+	}
+	if fallback != nil {
+		return fallback(v)
+	}
+	return nil
+}
+
+func unknownVariant(kind, discriminator string) error {
+	return fmt.Errorf("%s %q: %w", kind, discriminator, ErrUnknownVariant)
+}
+
+// PartVisitor handles each variant of Part.
+type PartVisitor interface {
+	VisitText(*TextPart) error
+	VisitReasoning(*ReasoningPart) error
+	VisitFile(*FilePart) error
+	VisitTool(*ToolPart) error
+	VisitStepStart(*StepStartPart) error
+	VisitStepFinish(*StepFinishPart) error
+	VisitSnapshot(*SnapshotPart) error
+	VisitPatch(*PartPatchPart) error
+	VisitAgent(*AgentPart) error
+	VisitRetry(*PartRetryPart) error
+}
+
+// PartVisitorFuncs implements PartVisitor with a callback per variant, so a
+// caller sets only the cases it handles. A nil callback falls back to
+// Default, or does nothing if Default is also nil. The other *VisitorFuncs
+// types follow the same rule.
+type PartVisitorFuncs struct {
+	Text       func(*TextPart) error
+	Reasoning  func(*ReasoningPart) error
+	File       func(*FilePart) error
+	Tool       func(*ToolPart) error
+	StepStart  func(*StepStartPart) error
+	StepFinish func(*StepFinishPart) error
+	Snapshot   func(*SnapshotPart) error
+	Patch      func(*PartPatchPart) error
+	Agent      func(*AgentPart) error
+	Retry      func(*PartRetryPart) error
+	// Default receives the decoded variant.
+	Default func(v any) error
+}
+
+var _ PartVisitor = PartVisitorFuncs{}
+
+func (f PartVisitorFuncs) VisitText(v *TextPart) error {
+	return visitFunc(f.Text, f.Default, v)
+}
+
+func (f PartVisitorFuncs) VisitReasoning(v *ReasoningPart) error {
+	return visitFunc(f.Reasoning, f.Default, v)
+}
+
+func (f PartVisitorFuncs) VisitFile(v *FilePart) error {
+	return visitFunc(f.File, f.Default, v)
+}
+
+func (f PartVisitorFuncs) VisitTool(v *ToolPart) error {
+	return visitFunc(f.Tool, f.Default, v)
+}
+
+func (f PartVisitorFuncs) VisitStepStart(v *StepStartPart) error {
+	return visitFunc(f.StepStart, f.Default, v)
+}
+
+func (f PartVisitorFuncs) VisitStepFinish(v *StepFinishPart) error {
+	return visitFunc(f.StepFinish, f.Default, v)
+}
+
+func (f PartVisitorFuncs) VisitSnapshot(v *SnapshotPart) error {
+	return visitFunc(f.Snapshot, f.Default, v)
+}
+
+func (f PartVisitorFuncs) VisitPatch(v *PartPatchPart) error {
+	return visitFunc(f.Patch, f.Default, v)
+}
+
+func (f PartVisitorFuncs) VisitAgent(v *AgentPart) error {
+	return visitFunc(f.Agent, f.Default, v)
+}
+
+func (f PartVisitorFuncs) VisitRetry(v *PartRetryPart) error {
+	return visitFunc(f.Retry, f.Default, v)
+}
+
+// Visit decodes the part and calls the visitor method for its Type.
+func (r Part) Visit(visitor PartVisitor) error {
+	switch r.Type {
+	case PartTypeText:
+		return visitVariant(r.AsText, visitor.VisitText)
+	case PartTypeReasoning:
+		return visitVariant(r.AsReasoning, visitor.VisitReasoning)
+	case PartTypeFile:
+		return visitVariant(r.AsFile, visitor.VisitFile)
+	case PartTypeTool:
+		return visitVariant(r.AsTool, visitor.VisitTool)
+	case PartTypeStepStart:
+		return visitVariant(r.AsStepStart, visitor.VisitStepStart)
+	case PartTypeStepFinish:
+		return visitVariant(r.AsStepFinish, visitor.VisitStepFinish)
+	case PartTypeSnapshot:
+		return visitVariant(r.AsSnapshot, visitor.VisitSnapshot)
+	case PartTypePatch:
+		return visitVariant(r.AsPatch, visitor.VisitPatch)
+	case PartTypeAgent:
+		return visitVariant(r.AsAgent, visitor.VisitAgent)
+	case PartTypeRetry:
+		return visitVariant(r.AsRetry, visitor.VisitRetry)
+	}
+	return unknownVariant("part", string(r.Type))
+}
+
+// Any decodes the part into its concrete type, such as *TextPart.
+func (r Part) Any() (any, error) {
+	switch r.Type {
+	case PartTypeText:
+		return anyVariant(r.AsText())
+	case PartTypeReasoning:
+		return anyVariant(r.AsReasoning())
+	case PartTypeFile:
+		return anyVariant(r.AsFile())
+	case PartTypeTool:
+		return anyVariant(r.AsTool())
+	case PartTypeStepStart:
+		return anyVariant(r.AsStepStart())
+	case PartTypeStepFinish:
+		return anyVariant(r.AsStepFinish())
+	case PartTypeSnapshot:
+		return anyVariant(r.AsSnapshot())
+	case PartTypePatch:
+		return anyVariant(r.AsPatch())
+	case PartTypeAgent:
+		return anyVariant(r.AsAgent())
+	case PartTypeRetry:
+		return anyVariant(r.AsRetry())
+	}
+	return nil, unknownVariant("part", string(r.Type))
+}
+
+// MessageVisitor handles each variant of Message.
+type MessageVisitor interface {
+	VisitUser(*UserMessage) error
+	VisitAssistant(*AssistantMessage) error
+}
+
+// MessageVisitorFuncs implements MessageVisitor with a callback per variant,
+// falling back like PartVisitorFuncs.
+type MessageVisitorFuncs struct {
+	User      func(*UserMessage) error
+	Assistant func(*AssistantMessage) error
+	// Default receives the decoded variant.
+	Default func(v any) error
+}
+
+var _ MessageVisitor = MessageVisitorFuncs{}
+
+func (f MessageVisitorFuncs) VisitUser(v *UserMessage) error {
+	return visitFunc(f.User, f.Default, v)
+}
+
+func (f MessageVisitorFuncs) VisitAssistant(v *AssistantMessage) error {
+	return visitFunc(f.Assistant, f.Default, v)
+}
+
+// Visit decodes the message and calls the visitor method for its Role.
+func (r Message) Visit(visitor MessageVisitor) error {
+	switch r.Role {
+	case MessageRoleUser:
+		return visitVariant(r.AsUser, visitor.VisitUser)
+	case MessageRoleAssistant:
+		return visitVariant(r.AsAssistant, visitor.VisitAssistant)
+	}
+	return unknownVariant("message", string(r.Role))
+}
+
+// Any decodes the message into its concrete type, such as *UserMessage.
+func (r Message) Any() (any, error) {
+	switch r.Role {
+	case MessageRoleUser:
+		return anyVariant(r.AsUser())
+	case MessageRoleAssistant:
+		return anyVariant(r.AsAssistant())
+	}
+	return nil, unknownVariant("message", string(r.Role))
+}
+
+// ToolPartStateVisitor handles each variant of ToolPartState.
+type ToolPartStateVisitor interface {
+	VisitPending(*ToolStatePending) error
+	VisitRunning(*ToolStateRunning) error
+	VisitCompleted(*ToolStateCompleted) error
+	VisitError(*ToolStateError) error
+}
+
+// ToolPartStateVisitorFuncs implements ToolPartStateVisitor with a callback per
+// variant, falling back like PartVisitorFuncs.
+type ToolPartStateVisitorFuncs struct {
+	Pending   func(*ToolStatePending) error
+	Running   func(*ToolStateRunning) error
+	Completed func(*ToolStateCompleted) error
+	Error     func(*ToolStateError) error
+	// Default receives the decoded variant.
+	Default func(v any) error
+}
+
+var _ ToolPartStateVisitor = ToolPartStateVisitorFuncs{}
+
+func (f ToolPartStateVisitorFuncs) VisitPending(v *ToolStatePending) error {
+	return visitFunc(f.Pending, f.Default, v)
+}
+
+func (f ToolPartStateVisitorFuncs) VisitRunning(v *ToolStateRunning) error {
+	return visitFunc(f.Running, f.Default, v)
+}
+
+func (f ToolPartStateVisitorFuncs) VisitCompleted(v *ToolStateCompleted) error {
+	return visitFunc(f.Completed, f.Default, v)
+}
+
+func (f ToolPartStateVisitorFuncs) VisitError(v *ToolStateError) error {
+	return visitFunc(f.Error, f.Default, v)
+}
+
+// Visit decodes the tool state and calls the visitor method for its Status.
+func (r ToolPartState) Visit(visitor ToolPartStateVisitor) error {
+	switch r.Status {
+	case ToolPartStateStatusPending:
+		return visitVariant(r.AsPending, visitor.VisitPending)
+	case ToolPartStateStatusRunning:
+		return visitVariant(r.AsRunning, visitor.VisitRunning)
+	case ToolPartStateStatusCompleted:
+		return visitVariant(r.AsCompleted, visitor.VisitCompleted)
+	case ToolPartStateStatusError:
+		return visitVariant(r.AsError, visitor.VisitError)
+	}
+	return unknownVariant("tool state", string(r.Status))
+}
+
+// Any decodes the tool state into its concrete type, such as *ToolStatePending.
+func (r ToolPartState) Any() (any, error) {
+	switch r.Status {
+	case ToolPartStateStatusPending:
+		return anyVariant(r.AsPending())
+	case ToolPartStateStatusRunning:
+		return anyVariant(r.AsRunning())
+	case ToolPartStateStatusCompleted:
+		return anyVariant(r.AsCompleted())
+	case ToolPartStateStatusError:
+		return anyVariant(r.AsError())
+	}
+	return nil, unknownVariant("tool state", string(r.Status))
+}
+
+// FilePartSourceVisitor handles each variant of FilePartSource.
+type FilePartSourceVisitor interface {
+	VisitFile(*FileSource) error
+	VisitSymbol(*SymbolSource) error
+}
+
+// FilePartSourceVisitorFuncs implements FilePartSourceVisitor with a callback
+// per variant, falling back like PartVisitorFuncs.
+type FilePartSourceVisitorFuncs struct {
+	File   func(*FileSource) error
+	Symbol func(*SymbolSource) error
+	// Default receives the decoded variant.
+	Default func(v any) error
+}
+
+var _ FilePartSourceVisitor = FilePartSourceVisitorFuncs{}
+
+func (f FilePartSourceVisitorFuncs) VisitFile(v *FileSource) error {
+	return visitFunc(f.File, f.Default, v)
+}
+
+func (f FilePartSourceVisitorFuncs) VisitSymbol(v *SymbolSource) error {
+	return visitFunc(f.Symbol, f.Default, v)
+}
+
+// Visit decodes the file source and calls the visitor method for its Type.
+func (r FilePartSource) Visit(visitor FilePartSourceVisitor) error {
+	switch r.Type {
+	case FilePartSourceTypeFile:
+		return visitVariant(r.AsFile, visitor.VisitFile)
+	case FilePartSourceTypeSymbol:
+		return visitVariant(r.AsSymbol, visitor.VisitSymbol)
+	}
+	return unknownVariant("file source", string(r.Type))
+}
+
+// Any decodes the file source into its concrete type, such as *FileSource.
+func (r FilePartSource) Any() (any, error) {
+	switch r.Type {
+	case FilePartSourceTypeFile:
+		return anyVariant(r.AsFile())
+	case FilePartSourceTypeSymbol:
+		return anyVariant(r.AsSymbol())
+	}
+	return nil, unknownVariant("file source", string(r.Type))
+}
+
+// AssistantMessageErrorVisitor handles each variant of AssistantMessageError.
+type AssistantMessageErrorVisitor interface {
+	VisitProviderAuth(*shared.ProviderAuthError) error
+	VisitUnknown(*shared.UnknownError) error
+	VisitOutputLength(*AssistantMessageErrorMessageOutputLengthError) error
+	VisitAborted(*shared.MessageAbortedError) error
+	VisitAPI(*AssistantMessageErrorAPIError) error
+}
+
+// AssistantMessageErrorVisitorFuncs implements AssistantMessageErrorVisitor
+// with a callback per variant, falling back like PartVisitorFuncs.
+type AssistantMessageErrorVisitorFuncs struct {
+	ProviderAuth func(*shared.ProviderAuthError) error
+	Unknown      func(*shared.UnknownError) error
+	OutputLength func(*AssistantMessageErrorMessageOutputLengthError) error
+	Aborted      func(*shared.MessageAbortedError) error
+	API          func(*AssistantMessageErrorAPIError) error
+	// Default receives the decoded variant.
+	Default func(v any) error
+}
+
+var _ AssistantMessageErrorVisitor = AssistantMessageErrorVisitorFuncs{}
+
+func (f AssistantMessageErrorVisitorFuncs) VisitProviderAuth(v *shared.ProviderAuthError) error {
+	return visitFunc(f.ProviderAuth, f.Default, v)
+}
+
+func (f AssistantMessageErrorVisitorFuncs) VisitUnknown(v *shared.UnknownError) error {
+	return visitFunc(f.Unknown, f.Default, v)
+}
+
+func (f AssistantMessageErrorVisitorFuncs) VisitOutputLength(v *AssistantMessageErrorMessageOutputLengthError) error {
+	return visitFunc(f.OutputLength, f.Default, v)
+}
+
+func (f AssistantMessageErrorVisitorFuncs) VisitAborted(v *shared.MessageAbortedError) error {
+	return visitFunc(f.Aborted, f.Default, v)
+}
+
+func (f AssistantMessageErrorVisitorFuncs) VisitAPI(v *AssistantMessageErrorAPIError) error {
+	return visitFunc(f.API, f.Default, v)
+}
+
+// Visit decodes the assistant error and calls the visitor method for its Name.
+func (r AssistantMessageError) Visit(visitor AssistantMessageErrorVisitor) error {
+	switch r.Name {
+	case AssistantMessageErrorNameProviderAuthError:
+		return visitVariant(r.AsProviderAuth, visitor.VisitProviderAuth)
+	case AssistantMessageErrorNameUnknownError:
+		return visitVariant(r.AsUnknown, visitor.VisitUnknown)
+	case AssistantMessageErrorNameMessageOutputLengthError:
+		return visitVariant(r.AsOutputLength, visitor.VisitOutputLength)
+	case AssistantMessageErrorNameMessageAbortedError:
+		return visitVariant(r.AsAborted, visitor.VisitAborted)
+	case AssistantMessageErrorNameAPIError:
+		return visitVariant(r.AsAPI, visitor.VisitAPI)
+	}
+	return unknownVariant("assistant error", string(r.Name))
+}
+
+// Any decodes the assistant error into its concrete type, such as *shared.ProviderAuthError.
+func (r AssistantMessageError) Any() (any, error) {
+	switch r.Name {
+	case AssistantMessageErrorNameProviderAuthError:
+		return anyVariant(r.AsProviderAuth())
+	case AssistantMessageErrorNameUnknownError:
+		return anyVariant(r.AsUnknown())
+	case AssistantMessageErrorNameMessageOutputLengthError:
+		return anyVariant(r.AsOutputLength())
+	case AssistantMessageErrorNameMessageAbortedError:
+		return anyVariant(r.AsAborted())
+	case AssistantMessageErrorNameAPIError:
+		return anyVariant(r.AsAPI())
+	}
+	return nil, unknownVariant("assistant error", string(r.Name))
+}
+
+// SessionErrorVisitor handles each variant of SessionError.
+type SessionErrorVisitor interface {
+	VisitProviderAuth(*shared.ProviderAuthError) error
+	VisitUnknown(*shared.UnknownError) error
+	VisitOutputLength(*MessageOutputLengthError) error
+	VisitAborted(*shared.MessageAbortedError) error
+	VisitAPI(*SessionAPIError) error
+}
+
+// SessionErrorVisitorFuncs implements SessionErrorVisitor with a callback per
+// variant, falling back like PartVisitorFuncs.
+type SessionErrorVisitorFuncs struct {
+	ProviderAuth func(*shared.ProviderAuthError) error
+	Unknown      func(*shared.UnknownError) error
+	OutputLength func(*MessageOutputLengthError) error
+	Aborted      func(*shared.MessageAbortedError) error
+	API          func(*SessionAPIError) error
+	// Default receives the decoded variant.
+	Default func(v any) error
+}
+
+var _ SessionErrorVisitor = SessionErrorVisitorFuncs{}
+
+func (f SessionErrorVisitorFuncs) VisitProviderAuth(v *shared.ProviderAuthError) error {
+	return visitFunc(f.ProviderAuth, f.Default, v)
+}
+
+func (f SessionErrorVisitorFuncs) VisitUnknown(v *shared.UnknownError) error {
+	return visitFunc(f.Unknown, f.Default, v)
+}
+
+func (f SessionErrorVisitorFuncs) VisitOutputLength(v *MessageOutputLengthError) error {
+	return visitFunc(f.OutputLength, f.Default, v)
+}
+
+func (f SessionErrorVisitorFuncs) VisitAborted(v *shared.MessageAbortedError) error {
+	return visitFunc(f.Aborted, f.Default, v)
+}
+
+func (f SessionErrorVisitorFuncs) VisitAPI(v *SessionAPIError) error {
+	return visitFunc(f.API, f.Default, v)
+}
+
+// Visit decodes the session error and calls the visitor method for its Name.
+func (r SessionError) Visit(visitor SessionErrorVisitor) error {
+	switch r.Name {
+	case SessionErrorNameProviderAuthError:
+		return visitVariant(r.AsProviderAuth, visitor.VisitProviderAuth)
+	case SessionErrorNameUnknownError:
+		return visitVariant(r.AsUnknown, visitor.VisitUnknown)
+	case SessionErrorNameMessageOutputLengthError:
+		return visitVariant(r.AsOutputLength, visitor.VisitOutputLength)
+	case SessionErrorNameMessageAbortedError:
+		return visitVariant(r.AsAborted, visitor.VisitAborted)
+	case SessionErrorNameAPIError:
+		return visitVariant(r.AsAPI, visitor.VisitAPI)
+	}
+	return unknownVariant("session error", string(r.Name))
+}
+
+// Any decodes the session error into its concrete type, such as *shared.ProviderAuthError.
+func (r SessionError) Any() (any, error) {
+	switch r.Name {
+	case SessionErrorNameProviderAuthError:
+		return anyVariant(r.AsProviderAuth())
+	case SessionErrorNameUnknownError:
+		return anyVariant(r.AsUnknown())
+	case SessionErrorNameMessageOutputLengthError:
+		return anyVariant(r.AsOutputLength())
+	case SessionErrorNameMessageAbortedError:
+		return anyVariant(r.AsAborted())
+	case SessionErrorNameAPIError:
+		return anyVariant(r.AsAPI())
+	}
+	return nil, unknownVariant("session error", string(r.Name))
+}
+
+// ConfigMcpVisitor handles each variant of ConfigMcp.
+type ConfigMcpVisitor interface {
+	VisitLocal(*McpLocalConfig) error
+	VisitRemote(*McpRemoteConfig) error
+}
+
+// ConfigMcpVisitorFuncs implements ConfigMcpVisitor with a callback per
+// variant, falling back like PartVisitorFuncs.
+type ConfigMcpVisitorFuncs struct {
+	Local  func(*McpLocalConfig) error
+	Remote func(*McpRemoteConfig) error
+	// Default receives the decoded variant.
+	Default func(v any) error
+}
+
+var _ ConfigMcpVisitor = ConfigMcpVisitorFuncs{}
+
+func (f ConfigMcpVisitorFuncs) VisitLocal(v *McpLocalConfig) error {
+	return visitFunc(f.Local, f.Default, v)
+}
+
+func (f ConfigMcpVisitorFuncs) VisitRemote(v *McpRemoteConfig) error {
+	return visitFunc(f.Remote, f.Default, v)
+}
+
+// Visit decodes the MCP config and calls the visitor method for its Type.
+func (r ConfigMcp) Visit(visitor ConfigMcpVisitor) error {
+	switch r.Type {
+	case ConfigMcpTypeLocal:
+		return visitVariant(r.AsLocal, visitor.VisitLocal)
+	case ConfigMcpTypeRemote:
+		return visitVariant(r.AsRemote, visitor.VisitRemote)
+	}
+	return unknownVariant("MCP config", string(r.Type))
+}
+
+// Any decodes the MCP config into its concrete type, such as *McpLocalConfig.
+func (r ConfigMcp) Any() (any, error) {
+	switch r.Type {
+	case ConfigMcpTypeLocal:
+		return anyVariant(r.AsLocal())
+	case ConfigMcpTypeRemote:
+		return anyVariant(r.AsRemote())
+	}
+	return nil, unknownVariant("MCP config", string(r.Type))
+}
+
+// ConfigLspVisitor handles each variant of ConfigLsp.
+type ConfigLspVisitor interface {
+	VisitDisabled(*ConfigLspDisabled) error
+	VisitObject(*ConfigLspObject) error
+}
+
+// ConfigLspVisitorFuncs implements ConfigLspVisitor with a callback per
+// variant, falling back like PartVisitorFuncs.
+type ConfigLspVisitorFuncs struct {
+	Disabled func(*ConfigLspDisabled) error
+	Object   func(*ConfigLspObject) error
+	// Default receives the decoded variant.
+	Default func(v any) error
+}
+
+var _ ConfigLspVisitor = ConfigLspVisitorFuncs{}
+
+func (f ConfigLspVisitorFuncs) VisitDisabled(v *ConfigLspDisabled) error {
+	return visitFunc(f.Disabled, f.Default, v)
+}
+
+func (f ConfigLspVisitorFuncs) VisitObject(v *ConfigLspObject) error {
+	return visitFunc(f.Object, f.Default, v)
+}
+
+// Visit decodes the LSP config and calls the visitor method for its shape:
+// an object with a command, or disabled=true without one.
+func (r ConfigLsp) Visit(visitor ConfigLspVisitor) error {
+	obj, disabled, err := r.decode()
+	if err != nil {
+		return err
+	}
+	if obj != nil {
+		return visitor.VisitObject(obj)
+	}
+	return visitor.VisitDisabled(disabled)
+}
+
+// Any decodes the LSP config into *ConfigLspObject or *ConfigLspDisabled.
+func (r ConfigLsp) Any() (any, error) {
+	obj, disabled, err := r.decode()
+	if err != nil {
+		return nil, err
+	}
+	if obj != nil {
+		return obj, nil
+	}
+	return disabled, nil
+}
+
+// decode returns exactly one of the variants, or an error.
+func (r ConfigLsp) decode() (*ConfigLspObject, *ConfigLspDisabled, error) {
+	obj, err := r.AsObject()
+	if err == nil {
+		return obj, nil, nil
+	}
+	if !errors.Is(err, ErrWrongVariant) {
+		return nil, nil, err
+	}
+	disabled, err := r.AsDisabled()
+	if err == nil {
+		return nil, disabled, nil
+	}
+	if !errors.Is(err, ErrWrongVariant) {
+		return nil, nil, err
+	}
+	return nil, nil, fmt.Errorf("LSP config without command or disabled=true: %w", ErrUnknownVariant)
+}
+
+// EventVisitor handles each built-in event type, and through VisitCustom
+// any other type.
+type EventVisitor interface {
+	VisitInstallationUpdated(*EventInstallationUpdated) error
+	VisitLspClientDiagnostics(*EventLspClientDiagnostics) error
+	VisitMessageUpdated(*EventMessageUpdated) error
+	VisitMessageRemoved(*EventMessageRemoved) error
+	VisitMessagePartUpdated(*EventMessagePartUpdated) error
+	VisitMessagePartRemoved(*EventMessagePartRemoved) error
+	VisitSessionCompacted(*EventSessionCompacted) error
+	VisitPermissionUpdated(*EventPermissionUpdated) error
+	VisitPermissionReplied(*EventPermissionReplied) error
+	VisitFileEdited(*EventFileEdited) error
+	VisitFileWatcherUpdated(*EventFileWatcherUpdated) error
+	VisitTodoUpdated(*EventTodoUpdated) error
+	VisitSessionIdle(*EventSessionIdle) error
+	VisitSessionCreated(*EventSessionCreated) error
+	VisitSessionUpdated(*EventSessionUpdated) error
+	VisitSessionDeleted(*EventSessionDeleted) error
+	VisitSessionError(*EventSessionError) error
+	VisitServerConnected(*EventServerConnected) error
+	VisitIdeInstalled(*EventIdeInstalled) error
+	// VisitCustom receives an event type the SDK does not model. v is the
+	// value decoded by the decoder registered with RegisterEventType, or the
+	// event's JSON payload as a json.RawMessage if none is registered.
+	VisitCustom(eventType EventType, v any) error
+}
+
+// EventVisitorFuncs implements EventVisitor with a callback per event type,
+// falling back like PartVisitorFuncs.
+type EventVisitorFuncs struct {
+	InstallationUpdated  func(*EventInstallationUpdated) error
+	LspClientDiagnostics func(*EventLspClientDiagnostics) error
+	MessageUpdated       func(*EventMessageUpdated) error
+	MessageRemoved       func(*EventMessageRemoved) error
+	MessagePartUpdated   func(*EventMessagePartUpdated) error
+	MessagePartRemoved   func(*EventMessagePartRemoved) error
+	SessionCompacted     func(*EventSessionCompacted) error
+	PermissionUpdated    func(*EventPermissionUpdated) error
+	PermissionReplied    func(*EventPermissionReplied) error
+	FileEdited           func(*EventFileEdited) error
+	FileWatcherUpdated   func(*EventFileWatcherUpdated) error
+	TodoUpdated          func(*EventTodoUpdated) error
+	SessionIdle          func(*EventSessionIdle) error
+	SessionCreated       func(*EventSessionCreated) error
+	SessionUpdated       func(*EventSessionUpdated) error
+	SessionDeleted       func(*EventSessionDeleted) error
+	SessionError         func(*EventSessionError) error
+	ServerConnected      func(*EventServerConnected) error
+	IdeInstalled         func(*EventIdeInstalled) error
+	// Custom receives the event types VisitCustom does.
+	Custom func(eventType EventType, v any) error
+	// Default receives the decoded event.
+	Default func(v any) error
+}
+
+var _ EventVisitor = EventVisitorFuncs{}
+
+func (f EventVisitorFuncs) VisitInstallationUpdated(v *EventInstallationUpdated) error {
+	return visitFunc(f.InstallationUpdated, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitLspClientDiagnostics(v *EventLspClientDiagnostics) error {
+	return visitFunc(f.LspClientDiagnostics, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitMessageUpdated(v *EventMessageUpdated) error {
+	return visitFunc(f.MessageUpdated, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitMessageRemoved(v *EventMessageRemoved) error {
+	return visitFunc(f.MessageRemoved, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitMessagePartUpdated(v *EventMessagePartUpdated) error {
+	return visitFunc(f.MessagePartUpdated, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitMessagePartRemoved(v *EventMessagePartRemoved) error {
+	return visitFunc(f.MessagePartRemoved, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitSessionCompacted(v *EventSessionCompacted) error {
+	return visitFunc(f.SessionCompacted, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitPermissionUpdated(v *EventPermissionUpdated) error {
+	return visitFunc(f.PermissionUpdated, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitPermissionReplied(v *EventPermissionReplied) error {
+	return visitFunc(f.PermissionReplied, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitFileEdited(v *EventFileEdited) error {
+	return visitFunc(f.FileEdited, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitFileWatcherUpdated(v *EventFileWatcherUpdated) error {
+	return visitFunc(f.FileWatcherUpdated, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitTodoUpdated(v *EventTodoUpdated) error {
+	return visitFunc(f.TodoUpdated, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitSessionIdle(v *EventSessionIdle) error {
+	return visitFunc(f.SessionIdle, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitSessionCreated(v *EventSessionCreated) error {
+	return visitFunc(f.SessionCreated, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitSessionUpdated(v *EventSessionUpdated) error {
+	return visitFunc(f.SessionUpdated, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitSessionDeleted(v *EventSessionDeleted) error {
+	return visitFunc(f.SessionDeleted, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitSessionError(v *EventSessionError) error {
+	return visitFunc(f.SessionError, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitServerConnected(v *EventServerConnected) error {
+	return visitFunc(f.ServerConnected, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitIdeInstalled(v *EventIdeInstalled) error {
+	return visitFunc(f.IdeInstalled, f.Default, v)
+}
+
+func (f EventVisitorFuncs) VisitCustom(eventType EventType, v any) error {
+	if f.Custom != nil {
+		return f.Custom(eventType, v)
+	}
+	if f.Default != nil {
+		return f.Default(v)
+	}
+	return nil
+}
+
+// Visit decodes the event and calls the visitor method for its Type. Other
+// types go to VisitCustom, decoded as Any does when a decoder is registered
+// with RegisterEventType, so unlike other unions Event.Visit does not fail
+// on an unknown discriminator.
+func (e Event) Visit(visitor EventVisitor) error {
+	switch e.Type {
+	case EventTypeInstallationUpdated:
+		return visitVariant(e.AsInstallationUpdated, visitor.VisitInstallationUpdated)
+	case EventTypeLspClientDiagnostics:
+		return visitVariant(e.AsLspClientDiagnostics, visitor.VisitLspClientDiagnostics)
+	case EventTypeMessageUpdated:
+		return visitVariant(e.AsMessageUpdated, visitor.VisitMessageUpdated)
+	case EventTypeMessageRemoved:
+		return visitVariant(e.AsMessageRemoved, visitor.VisitMessageRemoved)
+	case EventTypeMessagePartUpdated:
+		return visitVariant(e.AsMessagePartUpdated, visitor.VisitMessagePartUpdated)
+	case EventTypeMessagePartRemoved:
+		return visitVariant(e.AsMessagePartRemoved, visitor.VisitMessagePartRemoved)
+	case EventTypeSessionCompacted:
+		return visitVariant(e.AsSessionCompacted, visitor.VisitSessionCompacted)
+	case EventTypePermissionUpdated:
+		return visitVariant(e.AsPermissionUpdated, visitor.VisitPermissionUpdated)
+	case EventTypePermissionReplied:
+		return visitVariant(e.AsPermissionReplied, visitor.VisitPermissionReplied)
+	case EventTypeFileEdited:
+		return visitVariant(e.AsFileEdited, visitor.VisitFileEdited)
+	case EventTypeFileWatcherUpdated:
+		return visitVariant(e.AsFileWatcherUpdated, visitor.VisitFileWatcherUpdated)
+	case EventTypeTodoUpdated:
+		return visitVariant(e.AsTodoUpdated, visitor.VisitTodoUpdated)
+	case EventTypeSessionIdle:
+		return visitVariant(e.AsSessionIdle, visitor.VisitSessionIdle)
+	case EventTypeSessionCreated:
+		return visitVariant(e.AsSessionCreated, visitor.VisitSessionCreated)
+	case EventTypeSessionUpdated:
+		return visitVariant(e.AsSessionUpdated, visitor.VisitSessionUpdated)
+	case EventTypeSessionDeleted:
+		return visitVariant(e.AsSessionDeleted, visitor.VisitSessionDeleted)
+	case EventTypeSessionError:
+		return visitVariant(e.AsSessionError, visitor.VisitSessionError)
+	case EventTypeServerConnected:
+		return visitVariant(e.AsServerConnected, visitor.VisitServerConnected)
+	case EventTypeIdeInstalled:
+		return visitVariant(e.AsIdeInstalled, visitor.VisitIdeInstalled)
+	}
+	v, err := e.Any()
+	if errors.Is(err, ErrUnknownEventType) {
+		return visitor.VisitCustom(e.Type, e.Raw())
+	}
+	if err != nil {
+		return err
+	}
+	return visitor.VisitCustom(e.Type, v)
+}
@@ -0,0 +1,276 @@
+package opencode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dominicnunez/opencode-sdk-go/shared"
+)
+
+// recordingPartVisitor implements every PartVisitor method, as exhaustive
+// callers are expected to.
+type recordingPartVisitor struct {
+	visited []string
+}
+
+func (v *recordingPartVisitor) record(name string) error {
+	v.visited = append(v.visited, name)
+	return nil
+}
+
+func (v *recordingPartVisitor) VisitText(p *TextPart) error           { return v.record("text:" + p.Text) }
+func (v *recordingPartVisitor) VisitReasoning(*ReasoningPart) error   { return v.record("reasoning") }
+func (v *recordingPartVisitor) VisitFile(*FilePart) error             { return v.record("file") }
+func (v *recordingPartVisitor) VisitTool(p *ToolPart) error           { return v.record("tool:" + p.Tool) }
+func (v *recordingPartVisitor) VisitStepStart(*StepStartPart) error   { return v.record("step-start") }
+func (v *recordingPartVisitor) VisitStepFinish(*StepFinishPart) error { return v.record("step-finish") }
+func (v *recordingPartVisitor) VisitSnapshot(*SnapshotPart) error     { return v.record("snapshot") }
+func (v *recordingPartVisitor) VisitPatch(*PartPatchPart) error       { return v.record("patch") }
+func (v *recordingPartVisitor) VisitAgent(*AgentPart) error           { return v.record("agent") }
+func (v *recordingPartVisitor) VisitRetry(*PartRetryPart) error       { return v.record("retry") }
+
+func mustUnmarshal[T any](t *testing.T, raw string) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		t.Fatalf("unmarshal %s: %v", raw, err)
+	}
+	return v
+}
+
+func TestPart_VisitAndAny(t *testing.T) {
+	parts := mustUnmarshal[[]Part](t, `[
+		{"id":"p1","type":"text","text":"hello"},
+		{"id":"p2","type":"tool","tool":"bash","state":{"status":"pending"}},
+		{"id":"p3","type":"step-finish"}
+	]`)
+
+	v := &recordingPartVisitor{}
+	for _, p := range parts {
+		if err := p.Visit(v); err != nil {
+			t.Fatalf("Visit: %v", err)
+		}
+	}
+	if got := fmt.Sprint(v.visited); got != "[text:hello tool:bash step-finish]" {
+		t.Errorf("visited %s", got)
+	}
+
+	anyPart, err := parts[1].Any()
+	if err != nil {
+		t.Fatalf("Any: %v", err)
+	}
+	switch p := anyPart.(type) {
+	case *ToolPart:
+		if p.Tool != "bash" {
+			t.Errorf("Tool = %q", p.Tool)
+		}
+	default:
+		t.Errorf("Any returned %T, want *ToolPart", anyPart)
+	}
+}
+
+func TestPart_VisitEveryKnownType(t *testing.T) {
+	for _, typ := range []PartType{
+		PartTypeText, PartTypeReasoning, PartTypeFile, PartTypeTool, PartTypeStepStart,
+		PartTypeStepFinish, PartTypeSnapshot, PartTypePatch, PartTypeAgent, PartTypeRetry,
+	} {
+		p := mustUnmarshal[Part](t, fmt.Sprintf(`{"id":"p","type":%q}`, typ))
+		if err := p.Visit(&recordingPartVisitor{}); err != nil {
+			t.Errorf("Visit %s: %v", typ, err)
+		}
+		if v, err := p.Any(); err != nil || v == nil {
+			t.Errorf("Any %s = %v, %v", typ, v, err)
+		}
+	}
+}
+
+func TestPartVisitorFuncs(t *testing.T) {
+	parts := mustUnmarshal[[]Part](t, `[
+		{"id":"p1","type":"text","text":"hello"},
+		{"id":"p2","type":"reasoning","text":"hmm"},
+		{"id":"p3","type":"snapshot","snapshot":"abc"}
+	]`)
+
+	var texts int
+	var others []string
+	visitor := PartVisitorFuncs{
+		Text: func(*TextPart) error { texts++; return nil },
+		Default: func(v any) error {
+			others = append(others, fmt.Sprintf("%T", v))
+			return nil
+		},
+	}
+	for _, p := range parts {
+		if err := p.Visit(visitor); err != nil {
+			t.Fatalf("Visit: %v", err)
+		}
+	}
+	if texts != 1 || fmt.Sprint(others) != "[*opencode.ReasoningPart *opencode.SnapshotPart]" {
+		t.Errorf("texts=%d others=%v", texts, others)
+	}
+
+	// Without Default, unhandled variants are ignored.
+	if err := parts[1].Visit(PartVisitorFuncs{}); err != nil {
+		t.Errorf("Visit with no callbacks: %v", err)
+	}
+
+	// Visitor errors pass through unchanged.
+	errStop := errors.New("stop")
+	err := parts[0].Visit(PartVisitorFuncs{Text: func(*TextPart) error { return errStop }})
+	if err != errStop {
+		t.Errorf("Visit err = %v, want errStop", err)
+	}
+}
+
+func TestVisit_UnknownVariant(t *testing.T) {
+	part := mustUnmarshal[Part](t, `{"id":"p","type":"hologram"}`)
+	if err := part.Visit(PartVisitorFuncs{}); !errors.Is(err, ErrUnknownVariant) {
+		t.Errorf("Part.Visit err = %v, want ErrUnknownVariant", err)
+	}
+	if _, err := part.Any(); !errors.Is(err, ErrUnknownVariant) {
+		t.Errorf("Part.Any err = %v, want ErrUnknownVariant", err)
+	}
+
+	msg := mustUnmarshal[Message](t, `{"id":"m","role":"system"}`)
+	if _, err := msg.Any(); !errors.Is(err, ErrUnknownVariant) {
+		t.Errorf("Message.Any err = %v, want ErrUnknownVariant", err)
+	}
+
+	lsp := mustUnmarshal[ConfigLsp](t, `{"disabled":false}`)
+	if err := lsp.Visit(ConfigLspVisitorFuncs{}); !errors.Is(err, ErrUnknownVariant) {
+		t.Errorf("ConfigLsp.Visit err = %v, want ErrUnknownVariant", err)
+	}
+
+	evt := mustUnmarshal[Event](t, `{"type":"plugin.custom","properties":{}}`)
+	if _, err := evt.Any(); !errors.Is(err, ErrUnknownEventType) || !errors.Is(err, ErrUnknownVariant) {
+		t.Errorf("Event.Any err = %v, want ErrUnknownEventType and ErrUnknownVariant", err)
+	}
+}
+
+func TestVisit_OtherUnions(t *testing.T) {
+	msg := mustUnmarshal[Message](t, `{"id":"m","role":"assistant","sessionID":"s","modelID":"claude"}`)
+	var model string
+	if err := msg.Visit(MessageVisitorFuncs{Assistant: func(m *AssistantMessage) error {
+		model = m.ModelID
+		return nil
+	}}); err != nil || model != "claude" {
+		t.Errorf("Message.Visit model=%q err=%v", model, err)
+	}
+
+	state := mustUnmarshal[ToolPartState](t, `{"status":"error","error":"boom","input":{}}`)
+	if v, err := state.Any(); err != nil {
+		t.Errorf("ToolPartState.Any: %v", err)
+	} else if s, ok := v.(*ToolStateError); !ok || s.Error != "boom" {
+		t.Errorf("ToolPartState.Any = %#v", v)
+	}
+
+	src := mustUnmarshal[FilePartSource](t, `{"type":"symbol","name":"main","path":"main.go"}`)
+	if v, err := src.Any(); err != nil {
+		t.Errorf("FilePartSource.Any: %v", err)
+	} else if _, ok := v.(*SymbolSource); !ok {
+		t.Errorf("FilePartSource.Any = %T", v)
+	}
+
+	asstErr := mustUnmarshal[AssistantMessageError](t, `{"name":"MessageAbortedError","data":{"message":"stopped"}}`)
+	if v, err := asstErr.Any(); err != nil {
+		t.Errorf("AssistantMessageError.Any: %v", err)
+	} else if _, ok := v.(*shared.MessageAbortedError); !ok {
+		t.Errorf("AssistantMessageError.Any = %T", v)
+	}
+
+	sessErr := mustUnmarshal[SessionError](t, `{"name":"APIError","data":{"message":"bad","isRetryable":false}}`)
+	var apiMessage string
+	if err := sessErr.Visit(SessionErrorVisitorFuncs{API: func(e *SessionAPIError) error {
+		apiMessage = e.Data.Message
+		return nil
+	}}); err != nil || apiMessage != "bad" {
+		t.Errorf("SessionError.Visit message=%q err=%v", apiMessage, err)
+	}
+
+	mcp := mustUnmarshal[ConfigMcp](t, `{"type":"remote","url":"https://example.com"}`)
+	if v, err := mcp.Any(); err != nil {
+		t.Errorf("ConfigMcp.Any: %v", err)
+	} else if _, ok := v.(*McpRemoteConfig); !ok {
+		t.Errorf("ConfigMcp.Any = %T", v)
+	}
+
+	for raw, want := range map[string]string{
+		`{"command":["gopls"]}`: "*opencode.ConfigLspObject",
+		`{"disabled":true}`:     "*opencode.ConfigLspDisabled",
+	} {
+		lsp := mustUnmarshal[ConfigLsp](t, raw)
+		var got string
+		if err := lsp.Visit(ConfigLspVisitorFuncs{Default: func(v any) error {
+			got = fmt.Sprintf("%T", v)
+			return nil
+		}}); err != nil || got != want {
+			t.Errorf("ConfigLsp.Visit(%s) = %s, %v; want %s", raw, got, err, want)
+		}
+	}
+}
+
+func TestEvent_Visit(t *testing.T) {
+	evt := mustUnmarshal[Event](t, `{"type":"session.idle","properties":{"sessionID":"ses_1"}}`)
+	var idle string
+	err := evt.Visit(EventVisitorFuncs{SessionIdle: func(e *EventSessionIdle) error {
+		idle = e.Data.SessionID
+		return nil
+	}})
+	if err != nil || idle != "ses_1" {
+		t.Errorf("Event.Visit idle=%q err=%v", idle, err)
+	}
+}
+
+func TestEvent_VisitCustom(t *testing.T) {
+	type progress struct {
+		Percent int `json:"percent"`
+	}
+	if err := RegisterEventType("visit.progress", func(raw json.RawMessage) (any, error) {
+		var evt struct {
+			Properties progress `json:"properties"`
+		}
+		err := json.Unmarshal(raw, &evt)
+		return &evt.Properties, err
+	}); err != nil {
+		t.Fatalf("RegisterEventType: %v", err)
+	}
+
+	registered := mustUnmarshal[Event](t, `{"type":"visit.progress","properties":{"percent":40}}`)
+	unregistered := mustUnmarshal[Event](t, `{"type":"visit.unregistered","properties":{}}`)
+
+	var got []string
+	visitor := EventVisitorFuncs{Custom: func(eventType EventType, v any) error {
+		switch v := v.(type) {
+		case *progress:
+			got = append(got, fmt.Sprintf("%s:%d", eventType, v.Percent))
+		case json.RawMessage:
+			got = append(got, fmt.Sprintf("%s:%s", eventType, v))
+		default:
+			t.Errorf("VisitCustom got %T", v)
+		}
+		return nil
+	}}
+	for _, evt := range []Event{registered, unregistered} {
+		if err := evt.Visit(visitor); err != nil {
+			t.Fatalf("Visit: %v", err)
+		}
+	}
+	want := `[visit.progress:40 visit.unregistered:{"type":"visit.unregistered","properties":{}}]`
+	if fmt.Sprint(got) != want {
+		t.Errorf("visited %v, want %s", got, want)
+	}
+
+	// Without Custom, Default receives the decoded value.
+	var fallback any
+	if err := registered.Visit(EventVisitorFuncs{Default: func(v any) error {
+		fallback = v
+		return nil
+	}}); err != nil {
+		t.Fatalf("Visit: %v", err)
+	}
+	if p, ok := fallback.(*progress); !ok || p.Percent != 40 {
+		t.Errorf("Default got %#v", fallback)
+	}
+}